@@ -3,8 +3,10 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -21,6 +23,7 @@ var (
 	ErrInvalidSignature = errors.New("invalid token signature")
 	ErrInvalidClaims    = errors.New("invalid token claims")
 	ErrInvalidSubject   = errors.New("invalid token subject")
+	ErrInvalidCSRFToken = errors.New("missing or invalid csrf token")
 )
 
 // TokenType defines different types of tokens
@@ -31,6 +34,8 @@ const (
 	AccessToken TokenType = "access"
 	// RefreshToken is a longer-lived token for obtaining new access tokens
 	RefreshToken TokenType = "refresh"
+	// PersonalAccessToken is a user-managed opaque token restricted by scopes
+	PersonalAccessToken TokenType = "pat"
 )
 
 // Config holds the configuration for the JWT auth manager
@@ -39,7 +44,7 @@ type Config struct {
 	RefreshSecret         string         // Secret key for refresh tokens
 	AccessTokenDuration   time.Duration  // Duration for access tokens
 	RefreshTokenDuration  time.Duration  // Duration for refresh tokens
-	TokenLookup           string         // Method to extract token: "header:Authorization" or "cookie:jwt"
+	TokenLookup           string         // Where to extract the token from, e.g. "header:Authorization" or "cookie:jwt", comma separated sources are tried in order
 	TokenHeadName         string         // Token prefix in header, e.g., "Bearer"
 	AuthScheme            string         // Auth scheme in header, e.g., "Bearer"
 	IdentityKey           string         // Key to store the identity in claims, e.g., "identity"
@@ -52,42 +57,48 @@ type Config struct {
 	CookieSameSite        http.SameSite  // SameSite attribute for cookies
 	RefreshCookieName     string         // Name of refresh token cookie
 	AccessCookieName      string         // Name of access token cookie
-	BlacklistedTokenCache memcache.Cache // Cache for blacklisted tokens (optional)
+	CSRFCookieName        string         // Name of the cookie holding the CSRF token, readable by scripts
+	CSRFHeaderName        string         // Header echoing the CSRF token on state-changing requests
+	BlacklistedTokenCache memcache.Cache // Cache for blacklisted tokens, an unbounded in-memory one is created on first use when nil
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() Config {
 	return Config{
-		AccessTokenDuration:   24 * time.Hour,
-		RefreshTokenDuration:  7 * 24 * time.Hour,
-		TokenLookup:           "header:Authorization",
-		TokenHeadName:         "Bearer",
-		AuthScheme:            "Bearer",
-		IdentityKey:           "identity",
-		DisableRefresh:        false,
-		SendCookies:           false,
-		SecureCookies:         true,
-		HTTPOnlyCookies:       true,
-		CookiePath:            "/",
-		CookieSameSite:        http.SameSiteStrictMode,
-		RefreshCookieName:     "jwt_refresh_token",
-		AccessCookieName:      "jwt_access_token",
-		BlacklistedTokenCache: memcache.NewInMemoryCache(24 * time.Hour),
+		AccessTokenDuration:  24 * time.Hour,
+		RefreshTokenDuration: 7 * 24 * time.Hour,
+		TokenLookup:          "header:Authorization",
+		TokenHeadName:        "Bearer",
+		AuthScheme:           "Bearer",
+		IdentityKey:          "identity",
+		DisableRefresh:       false,
+		SendCookies:          false,
+		SecureCookies:        true,
+		HTTPOnlyCookies:      true,
+		CookiePath:           "/",
+		CookieSameSite:       http.SameSiteStrictMode,
+		RefreshCookieName:    "jwt_refresh_token",
+		AccessCookieName:     "jwt_access_token",
+		CSRFCookieName:       "csrf_token",
+		CSRFHeaderName:       "X-CSRF-Token",
 	}
 }
 
 // CustomClaims extends jwt.RegisteredClaims with custom fields
 type CustomClaims struct {
 	jwt.RegisteredClaims
-	TokenType TokenType              `json:"type"`          // Type of token (access/refresh)
-	EntityID  string                 `json:"entityId "`     // EntityID identifier
-	Custom    map[string]interface{} `json:"custom"`        // Custom user-defined claims
-	TokenID   string                 `json:"jti,omitempty"` // Token ID for blacklisting
+	TokenType TokenType              `json:"type"`             // Type of token (access/refresh)
+	EntityID  string                 `json:"entityId "`        // EntityID identifier
+	Custom    map[string]interface{} `json:"custom"`           // Custom user-defined claims
+	TokenID   string                 `json:"jti,omitempty"`    // Token ID for blacklisting
+	Scopes    []string               `json:"scopes,omitempty"` // Scopes granted to a personal access token
 }
 
 // AuthManager is the JWT authentication manager
 type AuthManager struct {
 	Config Config
+
+	blacklistOnce sync.Once
 }
 
 // NewManager creates a new JWT authentication manager with the given configuration
@@ -210,16 +221,22 @@ func (m *AuthManager) ParseToken(tokenString string, tokenType TokenType) (*Cust
 	}
 
 	// Check if token is blacklisted
-	if m.Config.BlacklistedTokenCache != nil {
-		if _, blacklisted := m.Config.BlacklistedTokenCache.Get(claims.TokenID); blacklisted {
-			return nil, ErrInvalidToken
-		}
+	if _, blacklisted := m.blacklist().Get(claims.TokenID); blacklisted {
+		return nil, ErrInvalidToken
 	}
 
 	return claims, nil
 }
 
 func (m *AuthManager) RefreshTokens(refreshToken string) (string, string, error) {
+	return m.RefreshTokensWith(refreshToken, func(claims *CustomClaims) (map[string]interface{}, error) {
+		return claims.Custom, nil
+	})
+}
+
+// RefreshTokensWith refreshes a token pair, letting the caller rebuild the custom claims from the
+// validated refresh token claims, e.g. to pick up a role change made since the previous login.
+func (m *AuthManager) RefreshTokensWith(refreshToken string, customClaims func(claims *CustomClaims) (map[string]interface{}, error)) (string, string, error) {
 	if m.Config.DisableRefresh {
 		return "", "", errors.New("refresh functionality is disabled")
 	}
@@ -230,24 +247,64 @@ func (m *AuthManager) RefreshTokens(refreshToken string) (string, string, error)
 		return "", "", err
 	}
 
+	custom, err := customClaims(claims)
+	if err != nil {
+		return "", "", err
+	}
+
 	// Generate new tokens
-	accessToken, newRefreshToken, err := m.GenerateTokenPair(claims.EntityID, claims.Custom)
+	accessToken, newRefreshToken, err := m.GenerateTokenPair(claims.EntityID, custom)
 	if err != nil {
 		return "", "", err
 	}
 
-	// Blacklist the old refresh token
-	if m.Config.BlacklistedTokenCache != nil && claims.TokenID != "" {
-		expiry := time.Until(claims.ExpiresAt.Time)
-		m.Config.BlacklistedTokenCache.Set(claims.TokenID, true, expiry)
+	// Blacklist the old refresh token, the new pair is withheld if it can't be
+	if claims.TokenID != "" {
+		if err := m.BlacklistToken(claims.TokenID, time.Until(claims.ExpiresAt.Time)); err != nil {
+			return "", "", err
+		}
 	}
 
 	return accessToken, newRefreshToken, nil
 }
 
-// ExtractTokenFromRequest extracts the token from an HTTP request
+// ExtractTokenFromRequest extracts the token from an HTTP request. With several lookups the
+// first source present is used, e.g. "header:Authorization,cookie:jwt_access_token" lets
+// API clients send a header while browsers rely on the cookie.
 func (m *AuthManager) ExtractTokenFromRequest(r *http.Request) (string, error) {
-	parts := strings.Split(m.Config.TokenLookup, ":")
+	for _, lookup := range strings.Split(m.Config.TokenLookup, ",") {
+		token, err := m.extractToken(r, strings.TrimSpace(lookup))
+		if err == ErrMissingToken {
+			continue
+		}
+		return token, err
+	}
+	return "", ErrMissingToken
+}
+
+// TokenFromCookie reports whether the request is authenticated by the access token cookie,
+// i.e. no earlier lookup source, such as the Authorization header, carries a token
+func (m *AuthManager) TokenFromCookie(r *http.Request) bool {
+	for _, lookup := range strings.Split(m.Config.TokenLookup, ",") {
+		lookup = strings.TrimSpace(lookup)
+		if _, err := m.extractToken(r, lookup); err != ErrMissingToken {
+			return strings.HasPrefix(lookup, "cookie:")
+		}
+	}
+	return false
+}
+
+// ExtractRefreshTokenFromRequest returns the refresh token sent in its cookie
+func (m *AuthManager) ExtractRefreshTokenFromRequest(r *http.Request) (string, error) {
+	cookie, err := r.Cookie(m.Config.RefreshCookieName)
+	if err != nil || cookie.Value == "" {
+		return "", ErrMissingToken
+	}
+	return cookie.Value, nil
+}
+
+func (m *AuthManager) extractToken(r *http.Request, lookup string) (string, error) {
+	parts := strings.Split(lookup, ":")
 	if len(parts) != 2 {
 		return "", errors.New("invalid token lookup config")
 	}
@@ -272,7 +329,7 @@ func (m *AuthManager) ExtractTokenFromRequest(r *http.Request) (string, error) {
 
 	case "cookie":
 		cookie, err := r.Cookie(parts[1])
-		if err != nil {
+		if err != nil || cookie.Value == "" {
 			return "", ErrMissingToken
 		}
 		return cookie.Value, nil
@@ -312,6 +369,8 @@ func (m *AuthManager) SetTokenCookies(w http.ResponseWriter, accessToken, refres
 			SameSite: m.Config.CookieSameSite,
 		})
 	}
+
+	m.setCSRFCookie(w)
 }
 
 // ClearTokenCookies removes token cookies
@@ -343,15 +402,33 @@ func (m *AuthManager) ClearTokenCookies(w http.ResponseWriter) {
 		HttpOnly: m.Config.HTTPOnlyCookies,
 		SameSite: m.Config.CookieSameSite,
 	})
+
+	m.clearCSRFCookie(w)
 }
 
 // BlacklistToken adds a token to the blacklist
 func (m *AuthManager) BlacklistToken(tokenID string, expiration time.Duration) error {
-	if m.Config.BlacklistedTokenCache == nil {
-		return errors.New("blacklist cache not configured")
-	}
+	return m.blacklist().Set(tokenID, true, expiration)
+}
+
+// blacklist returns the configured blacklist cache. The default one is only created when first
+// used, so configurations that are copied or replaced don't leave its cleanup goroutine behind.
+// Entries are never evicted, that would make a revoked token valid again.
+func (m *AuthManager) blacklist() memcache.Cache {
+	m.blacklistOnce.Do(func() {
+		if m.Config.BlacklistedTokenCache == nil {
+			m.Config.BlacklistedTokenCache = memcache.NewInMemoryCache(time.Minute)
+		}
+	})
+	return m.Config.BlacklistedTokenCache
+}
 
-	return m.Config.BlacklistedTokenCache.Set(tokenID, true, expiration)
+// Close releases the blacklist cache when it holds resources, such as a cleanup goroutine
+func (m *AuthManager) Close() error {
+	if closer, ok := m.blacklist().(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
 }
 
 // IsAuthorized checks if the claims have the required roles
@@ -362,7 +439,7 @@ func (m *AuthManager) IsAuthorized(claims *CustomClaims, rolesKey string, requir
 
 	// Convert user roles to a map for O(1) lookups
 	userRoles := make(map[string]bool)
-	for _, role := range claims.Custom[rolesKey].([]string) {
+	for _, role := range claims.Roles(rolesKey) {
 		userRoles[role] = true
 	}
 
@@ -8,6 +8,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
 )
 
 func init() {
@@ -200,6 +202,46 @@ func TestBlacklistToken(t *testing.T) {
 	assert.Equal(t, ErrInvalidToken, err)
 }
 
+func TestCloseKeepsBlacklist(t *testing.T) {
+	manager := createTestManager()
+
+	assert.NoError(t, manager.BlacklistToken("token-id", time.Minute))
+	assert.NoError(t, manager.Close())
+	assert.NoError(t, manager.Close())
+
+	// Only the cleanup stops, revoked tokens stay revoked
+	_, blacklisted := manager.Config.BlacklistedTokenCache.Get("token-id")
+	assert.True(t, blacklisted)
+}
+
+func TestDefaultBlacklistIsCreatedOnFirstUse(t *testing.T) {
+	config := DefaultConfig()
+	assert.Nil(t, config.BlacklistedTokenCache, "copies of the default config must not start a cleanup goroutine")
+
+	manager := NewAuthManager(config)
+	defer manager.Close()
+
+	assert.NoError(t, manager.BlacklistToken("token-id", time.Minute))
+	_, blacklisted := manager.Config.BlacklistedTokenCache.Get("token-id")
+	assert.True(t, blacklisted)
+}
+
+func TestRefreshTokensFailsWhenBlacklistIsFull(t *testing.T) {
+	manager := createTestManager()
+	manager.Config.BlacklistedTokenCache = memcache.New(memcache.Config{Shards: 1, MaxEntries: 1, RejectWhenFull: true})
+	defer manager.Close()
+
+	assert.NoError(t, manager.BlacklistToken("revoked", time.Hour))
+	_, refreshToken, err := manager.GenerateTokenPair("123", nil)
+	assert.NoError(t, err)
+
+	// The old refresh token can't be revoked, so no new pair is handed out
+	_, _, err = manager.RefreshTokens(refreshToken)
+	assert.ErrorIs(t, err, memcache.ErrCacheFull)
+	_, blacklisted := manager.Config.BlacklistedTokenCache.Get("revoked")
+	assert.True(t, blacklisted)
+}
+
 func TestExtractTokenFromRequest(t *testing.T) {
 	manager := createTestManager()
 
@@ -242,6 +284,31 @@ func TestExtractTokenFromRequest(t *testing.T) {
 				manager.Config.TokenLookup = "cookie:jwt_access_token"
 			},
 		},
+		{
+			name: "Cookie Token After Missing Header",
+			setupFunc: func() *http.Request {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.AddCookie(&http.Cookie{Name: "jwt_access_token", Value: "test-token"})
+				return req
+			},
+			wantErr: false,
+			tokenConfig: func() {
+				manager.Config.TokenLookup = "header:Authorization, cookie:jwt_access_token"
+			},
+		},
+		{
+			name: "Header Token Before Cookie",
+			setupFunc: func() *http.Request {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("Authorization", "Bearer test-token")
+				req.AddCookie(&http.Cookie{Name: "jwt_access_token", Value: "cookie-token"})
+				return req
+			},
+			wantErr: false,
+			tokenConfig: func() {
+				manager.Config.TokenLookup = "header:Authorization, cookie:jwt_access_token"
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -311,6 +378,83 @@ func TestTokenCookies(t *testing.T) {
 	}
 }
 
+func TestTokenFromCookie(t *testing.T) {
+	manager := createTestManager()
+	manager.Config.TokenLookup = "header:Authorization,cookie:jwt_access_token"
+
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.False(t, manager.TokenFromCookie(req))
+
+	req.AddCookie(&http.Cookie{Name: "jwt_access_token", Value: "cookie-token"})
+	assert.True(t, manager.TokenFromCookie(req))
+
+	// The header wins, so the cookie is not what authenticates the request
+	req.Header.Set("Authorization", "Bearer header-token")
+	assert.False(t, manager.TokenFromCookie(req))
+}
+
+func TestCSRFCookie(t *testing.T) {
+	manager := createTestManager()
+	manager.Config.SendCookies = true
+
+	w := httptest.NewRecorder()
+	manager.SetTokenCookies(w, "access-token", "refresh-token")
+
+	var csrf *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == manager.Config.CSRFCookieName {
+			csrf = cookie
+		}
+	}
+	if assert.NotNil(t, csrf, "CSRF cookie not found") {
+		assert.False(t, csrf.HttpOnly, "scripts must be able to read the CSRF token")
+		assert.NotEmpty(t, csrf.Value)
+	}
+
+	tests := []struct {
+		name    string
+		cookie  string
+		header  string
+		wantErr bool
+	}{
+		{name: "Matching Token", cookie: csrf.Value, header: csrf.Value},
+		{name: "Missing Header", cookie: csrf.Value, wantErr: true},
+		{name: "Missing Cookie", header: csrf.Value, wantErr: true},
+		{name: "Different Token", cookie: csrf.Value, header: "forged", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: manager.Config.CSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				req.Header.Set(manager.Config.CSRFHeaderName, tt.header)
+			}
+
+			err := manager.VerifyCSRF(req)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCSRFToken)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	// Clearing the token cookies clears the CSRF cookie too
+	w = httptest.NewRecorder()
+	manager.ClearTokenCookies(w)
+	cleared := false
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == manager.Config.CSRFCookieName {
+			assert.True(t, cookie.MaxAge < 0)
+			cleared = true
+		}
+	}
+	assert.True(t, cleared, "CSRF cookie not cleared")
+}
+
 func TestIsAuthorized(t *testing.T) {
 	manager := createTestManager()
 
@@ -342,3 +486,128 @@ func TestIsAuthorized(t *testing.T) {
 		})
 	}
 }
+
+func TestHasScope(t *testing.T) {
+	patClaims := &CustomClaims{
+		EntityID:  "123",
+		TokenType: PersonalAccessToken,
+		Scopes:    []string{"reminders:read", "groups:*"},
+	}
+
+	sessionClaims := &CustomClaims{
+		EntityID:  "123",
+		TokenType: AccessToken,
+	}
+
+	// Test cases
+	tests := []struct {
+		name     string
+		claims   *CustomClaims
+		required string
+		expected bool
+	}{
+		{"Exact Scope", patClaims, "reminders:read", true},
+		{"Missing Scope", patClaims, "reminders:write", false},
+		{"Resource Wildcard", patClaims, "groups:write", true},
+		{"Wildcard Does Not Cross Resources", patClaims, "users:read", false},
+		{"Session Token Is Unrestricted", sessionClaims, "reminders:write", true},
+		{"Global Wildcard", &CustomClaims{TokenType: PersonalAccessToken, Scopes: []string{"*"}}, "users:write", true},
+		{"No Scopes", &CustomClaims{TokenType: PersonalAccessToken}, "reminders:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.claims.HasScope(tt.required))
+		})
+	}
+}
+
+func TestClaimsRoles(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []string
+	}{
+		{"Single Role", "admin", []string{"admin"}},
+		{"Comma Separated", "admin, user", []string{"admin", "user"}},
+		{"String Slice", []string{"user", "editor"}, []string{"user", "editor"}},
+		{"Decoded JSON Array", []interface{}{"user", 42, "editor"}, []string{"user", "editor"}},
+		{"Missing", nil, nil},
+		{"Unexpected Type", 42, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &CustomClaims{Custom: map[string]interface{}{"role": tt.value}}
+			assert.Equal(t, tt.expected, claims.Roles("role"))
+		})
+	}
+}
+
+func TestIsAuthorizedAfterTokenRoundTrip(t *testing.T) {
+	manager := createTestManager()
+
+	// Roles go through JSON encoding, so the parsed claims no longer hold the original types
+	token, err := manager.GenerateToken("123", AccessToken, map[string]interface{}{
+		"role":  "admin",
+		"roles": []string{"user", "editor"},
+	})
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(token, AccessToken)
+	assert.NoError(t, err)
+
+	assert.True(t, manager.IsAuthorized(claims, "role", []string{"admin"}))
+	assert.True(t, manager.IsAuthorized(claims, "roles", []string{"editor"}))
+	assert.False(t, manager.IsAuthorized(claims, "roles", []string{"admin"}))
+}
+
+func TestRefreshTokensWith(t *testing.T) {
+	manager := createTestManager()
+
+	_, refreshToken, err := manager.GenerateTokenPair("123", map[string]interface{}{"role": "admin"})
+	assert.NoError(t, err)
+
+	newAccess, _, err := manager.RefreshTokensWith(refreshToken, func(claims *CustomClaims) (map[string]interface{}, error) {
+		assert.Equal(t, "123", claims.EntityID)
+		return map[string]interface{}{"role": "user"}, nil
+	})
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(newAccess, AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user"}, claims.Roles("role"))
+}
+
+func TestRoleRegistry(t *testing.T) {
+	registry := NewRoleRegistry().
+		Register("admin", "*").
+		Register("user", "reminders:*", "users:read")
+
+	// Test cases
+	tests := []struct {
+		name       string
+		roles      []string
+		permission string
+		expected   bool
+	}{
+		{"Admin Wildcard", []string{"admin"}, "users:manage", true},
+		{"Resource Wildcard", []string{"user"}, "reminders:write", true},
+		{"Exact Permission", []string{"user"}, "users:read", true},
+		{"Missing Permission", []string{"user"}, "users:manage", false},
+		{"Any Role Grants", []string{"guest", "user"}, "users:read", true},
+		{"Unknown Role", []string{"guest"}, "users:read", false},
+		{"No Roles", nil, "users:read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, registry.Can(tt.roles, tt.permission))
+		})
+	}
+
+	assert.True(t, registry.HasRole("user"))
+	assert.False(t, registry.HasRole("guest"))
+	assert.Equal(t, []string{"admin", "user"}, registry.Roles())
+}
@@ -0,0 +1,412 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE:
+// - Provider discovery through /.well-known/openid-configuration
+// - Authorization URL generation with state, nonce and an S256 code challenge
+// - Code exchange at the token endpoint
+// - ID token verification against the provider's JWKS
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Common errors
+var (
+	ErrDiscovery         = errors.New("oidc discovery failed")
+	ErrExchange          = errors.New("oidc code exchange failed")
+	ErrMissingIDToken    = errors.New("oidc token response has no id_token")
+	ErrInvalidIDToken    = errors.New("invalid oidc id token")
+	ErrNonceMismatch     = errors.New("oidc nonce mismatch")
+	ErrUnknownSigningKey = errors.New("unknown oidc signing key")
+)
+
+// codeChallengeMethod is the only PKCE method we send, "plain" offers no protection
+const codeChallengeMethod = "S256"
+
+// DefaultKeyRefreshInterval is the minimum time between JWKS fetches for unknown key ids
+const DefaultKeyRefreshInterval = time.Minute
+
+// DefaultScopes are requested when the provider config does not list any
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// Config holds the client registration for a single provider
+type Config struct {
+	Name         string       // Name of the provider used in routes, e.g. "google"
+	IssuerURL    string       // Issuer identifier, discovery is read from IssuerURL + "/.well-known/openid-configuration"
+	ClientID     string       // OAuth2 client id
+	ClientSecret string       // OAuth2 client secret
+	RedirectURL  string       // Callback URL registered with the provider
+	Scopes       []string     // Requested scopes, defaults to DefaultScopes
+	HTTPClient   *http.Client // HTTP client used to reach the provider, defaults to http.DefaultClient
+	// KeyRefreshInterval is the minimum time between JWKS fetches, so tokens with unknown key ids
+	// can't make us hammer the provider. Defaults to DefaultKeyRefreshInterval.
+	KeyRefreshInterval time.Duration
+}
+
+// Token is the result of a successful code exchange
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	IDToken      string `json:"id_token"`
+}
+
+// IDTokenClaims are the claims of a verified ID token
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified FlexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+}
+
+// FlexibleBool accepts both JSON booleans and the "true"/"false" strings some providers send
+type FlexibleBool bool
+
+func (b *FlexibleBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch value := v.(type) {
+	case bool:
+		*b = FlexibleBool(value)
+	case string:
+		*b = FlexibleBool(strings.EqualFold(value, "true"))
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// IsEmailVerified reports whether the provider vouches for the email address
+func (c *IDTokenClaims) IsEmailVerified() bool {
+	return bool(c.EmailVerified)
+}
+
+// discoveryDocument is the subset of the provider metadata we rely on
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider is an OpenID Connect provider client
+type Provider struct {
+	config Config
+
+	mu          sync.RWMutex
+	discovery   *discoveryDocument
+	keys        map[string]interface{} // Public keys by key id
+	lastRefresh time.Time              // When the JWKS was last fetched, or a fetch started
+}
+
+// NewProvider creates a provider client. Discovery happens lazily on first use,
+// so an unreachable provider does not prevent the application from starting.
+func NewProvider(config Config) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = DefaultScopes
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if config.KeyRefreshInterval <= 0 {
+		config.KeyRefreshInterval = DefaultKeyRefreshInterval
+	}
+
+	return &Provider{
+		config: config,
+		keys:   make(map[string]interface{}),
+	}
+}
+
+// Name returns the configured provider name
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL returns the URL to redirect the user to for authentication
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrDiscovery, err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallengeS256(codeVerifier))
+	query.Set("code_challenge_method", codeChallengeMethod)
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange trades an authorization code for tokens, proving possession of the PKCE verifier
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &errResp)
+		return nil, fmt.Errorf("%w: status %d: %s %s", ErrExchange, resp.StatusCode, errResp.Error, errResp.ErrorDescription)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+
+	if token.IDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseWithClaims(rawIDToken, &IDTokenClaims{},
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.signingKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	claims, ok := token.Claims.(*IDTokenClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	return claims, nil
+}
+
+// discover fetches and caches the provider metadata. The fetch happens without holding the lock,
+// a slow provider must not block key lookups of requests that don't need discovery.
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.RLock()
+	doc := p.discovery
+	p.mu.RUnlock()
+	if doc != nil {
+		return doc, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	doc = &discoveryDocument{}
+	if err := p.getJSON(ctx, wellKnown, doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+
+	// The issuer in the metadata must match the configured one, otherwise tokens could be minted by someone else
+	if strings.TrimSuffix(doc.Issuer, "/") != strings.TrimSuffix(p.config.IssuerURL, "/") {
+		return nil, fmt.Errorf("%w: issuer mismatch, expected %s got %s", ErrDiscovery, p.config.IssuerURL, doc.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Concurrent requests may have discovered the provider too, they all use the first result
+	if p.discovery == nil {
+		p.discovery = doc
+	}
+	return p.discovery, nil
+}
+
+// signingKey returns the public key for a key id, refreshing the JWKS once for unknown ids to follow
+// key rotation. Refreshes are at most once per KeyRefreshInterval.
+func (p *Provider) signingKey(ctx context.Context, kid string) (interface{}, error) {
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if !p.claimRefresh() {
+		return nil, ErrUnknownSigningKey
+	}
+
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	return nil, ErrUnknownSigningKey
+}
+
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok
+	}
+
+	// Tokens without a key id are only acceptable when the provider publishes a single key
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// claimRefresh reports whether the JWKS may be fetched now, and if so records the fetch so
+// concurrent callers don't start another one
+func (p *Provider) claimRefresh() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if !p.lastRefresh.IsZero() && now.Sub(p.lastRefresh) < p.config.KeyRefreshInterval {
+		return false
+	}
+	p.lastRefresh = now
+	return true
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+		return fmt.Errorf("%w: fetching jwks: %v", ErrUnknownSigningKey, err)
+	}
+
+	keys, err := set.publicKeys()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, rawURL string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, rawURL)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// GenerateRandomString returns a URL-safe random string suitable for state, nonce and PKCE verifiers
+func GenerateRandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallengeS256 derives the PKCE code challenge for a verifier
+func CodeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,278 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth/oidc"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost/callback"
+
+// Helper to create a client for the fake provider
+func setupProvider(t *testing.T) (*oidctest.Provider, *oidc.Provider) {
+	return setupProviderWithKeyRefresh(t, 0)
+}
+
+// setupProviderWithKeyRefresh creates a client refreshing the JWKS at most once per interval,
+// zero uses the default
+func setupProviderWithKeyRefresh(t *testing.T, interval time.Duration) (*oidctest.Provider, *oidc.Provider) {
+	fake := oidctest.NewProvider("test-client", "test-secret")
+	t.Cleanup(fake.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:               "fake",
+		IssuerURL:          fake.Issuer(),
+		ClientID:           fake.ClientID,
+		ClientSecret:       fake.ClientSecret,
+		RedirectURL:        testRedirectURL,
+		KeyRefreshInterval: interval,
+	})
+
+	return fake, provider
+}
+
+// authorize runs the browser part of the flow and returns the code
+func authorize(t *testing.T, fake *oidctest.Provider, provider *oidc.Provider, state, nonce, verifier string) string {
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+
+	redirect, err := fake.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, state, redirect.Query().Get("state"))
+
+	return redirect.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	fake, provider := setupProvider(t)
+	ctx := context.Background()
+
+	verifier, err := oidc.GenerateRandomString()
+	require.NoError(t, err)
+
+	code := authorize(t, fake, provider, "state-1", "nonce-1", verifier)
+
+	token, err := provider.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "fake-subject", claims.Subject)
+	assert.Equal(t, "fake.user@example.com", claims.Email)
+	assert.True(t, claims.IsEmailVerified())
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	fake, provider := setupProvider(t)
+
+	code := authorize(t, fake, provider, "state", "nonce", "the-real-verifier")
+
+	_, err := provider.Exchange(context.Background(), code, "another-verifier")
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	fake, provider := setupProvider(t)
+	ctx := context.Background()
+
+	code := authorize(t, fake, provider, "state", "nonce", "verifier")
+
+	_, err := provider.Exchange(ctx, code, "verifier")
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(ctx, code, "verifier")
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
+
+func TestVerifyIDTokenRejectsNonceMismatch(t *testing.T) {
+	fake, provider := setupProvider(t)
+	ctx := context.Background()
+
+	code := authorize(t, fake, provider, "state", "nonce", "verifier")
+	token, err := provider.Exchange(ctx, code, "verifier")
+	require.NoError(t, err)
+
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "other-nonce")
+	assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+}
+
+func TestVerifyIDTokenFollowsKeyRotation(t *testing.T) {
+	fake, provider := setupProviderWithKeyRefresh(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	// First verification caches the current key
+	code := authorize(t, fake, provider, "state", "nonce", "verifier")
+	token, err := provider.Exchange(ctx, code, "verifier")
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "nonce")
+	require.NoError(t, err)
+
+	fake.RotateKey()
+	time.Sleep(20 * time.Millisecond)
+
+	code = authorize(t, fake, provider, "state", "nonce", "verifier")
+	token, err = provider.Exchange(ctx, code, "verifier")
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestVerifyIDTokenThrottlesKeyRefresh(t *testing.T) {
+	fake, provider := setupProvider(t)
+	ctx := context.Background()
+
+	code := authorize(t, fake, provider, "state", "nonce", "verifier")
+	token, err := provider.Exchange(ctx, code, "verifier")
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "nonce")
+	require.NoError(t, err)
+	require.Equal(t, 1, fake.JWKSRequests())
+
+	// Tokens with an unknown key id don't each trigger a fetch within the refresh interval
+	fake.RotateKey()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		raw, err := fake.SignIDToken(&oidc.IDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    fake.Issuer(),
+				Subject:   "subject",
+				Audience:  jwt.ClaimStrings{fake.ClientID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+			Nonce: "nonce",
+		})
+		require.NoError(t, err)
+		_, err = provider.VerifyIDToken(ctx, raw, "nonce")
+		assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+	}
+	assert.Equal(t, 1, fake.JWKSRequests())
+
+	// Known keys keep working
+	_, err = provider.VerifyIDToken(ctx, token.IDToken, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestVerifyIDTokenRejectsInvalidClaims(t *testing.T) {
+	fake, provider := setupProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	validClaims := func() *oidc.IDTokenClaims {
+		return &oidc.IDTokenClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    fake.Issuer(),
+				Subject:   "subject",
+				Audience:  jwt.ClaimStrings{fake.ClientID},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+			Nonce: "nonce",
+		}
+	}
+
+	// Test cases
+	tests := []struct {
+		name   string
+		modify func(c *oidc.IDTokenClaims)
+	}{
+		{"Wrong Issuer", func(c *oidc.IDTokenClaims) { c.Issuer = "https://evil.example.com" }},
+		{"Wrong Audience", func(c *oidc.IDTokenClaims) { c.Audience = jwt.ClaimStrings{"other-client"} }},
+		{"Expired", func(c *oidc.IDTokenClaims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) }},
+		{"Missing Subject", func(c *oidc.IDTokenClaims) { c.Subject = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(claims)
+
+			raw, err := fake.SignIDToken(claims)
+			require.NoError(t, err)
+
+			_, err = provider.VerifyIDToken(ctx, raw, "nonce")
+			assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+		})
+	}
+
+	// Sanity check that the unmodified claims verify
+	raw, err := fake.SignIDToken(validClaims())
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(ctx, raw, "nonce")
+	assert.NoError(t, err)
+}
+
+func TestVerifyIDTokenRejectsForeignSignature(t *testing.T) {
+	fake, provider := setupProvider(t)
+	other := oidctest.NewProvider(fake.ClientID, fake.ClientSecret)
+	defer other.Close()
+	now := time.Now()
+
+	// Signed by another provider's key but claiming to be from ours
+	raw, err := other.SignIDToken(&oidc.IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    fake.Issuer(),
+			Subject:   "subject",
+			Audience:  jwt.ClaimStrings{fake.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+		Nonce: "nonce",
+	})
+	require.NoError(t, err)
+
+	_, err = provider.VerifyIDToken(context.Background(), raw, "nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	fake := oidctest.NewProvider("test-client", "test-secret")
+	defer fake.Close()
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:   fake.Issuer() + "/tenant",
+		ClientID:    fake.ClientID,
+		RedirectURL: testRedirectURL,
+	})
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+}
+
+func TestDiscoveryDoesNotWaitForSlowerRequests(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:   slow.URL,
+		ClientID:    "test-client",
+		RedirectURL: testRedirectURL,
+	})
+
+	// A first request is stuck discovering the provider
+	go provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	time.Sleep(50 * time.Millisecond)
+
+	// Others give up with their own context instead of waiting for it
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+	assert.Less(t, time.Since(start), time.Second)
+}
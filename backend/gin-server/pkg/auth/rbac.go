@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RoleRegistry maps roles to the permissions they grant.
+// Permissions use the same "resource:action" format as scopes, so "*" and "resource:*" grants are supported.
+type RoleRegistry struct {
+	mu    sync.RWMutex
+	roles map[string][]string
+}
+
+// NewRoleRegistry creates an empty role registry
+func NewRoleRegistry() *RoleRegistry {
+	return &RoleRegistry{
+		roles: make(map[string][]string),
+	}
+}
+
+// Register adds a role, or extends an existing one, with the given permissions
+func (r *RoleRegistry) Register(role string, permissions ...string) *RoleRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.roles[role] = append(r.roles[role], permissions...)
+	return r
+}
+
+// HasRole reports whether the role is registered
+func (r *RoleRegistry) HasRole(role string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.roles[role]
+	return ok
+}
+
+// Roles returns the registered roles in sorted order
+func (r *RoleRegistry) Roles() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]string, 0, len(r.roles))
+	for role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	return roles
+}
+
+// Permissions returns the permissions granted to a role
+func (r *RoleRegistry) Permissions(role string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.roles[role]...)
+}
+
+// Can reports whether any of the roles grants the required permission. Unknown roles grant nothing.
+func (r *RoleRegistry) Can(roles []string, permission string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, role := range roles {
+		for _, granted := range r.roles[role] {
+			if ScopeMatches(granted, permission) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Roles decodes the roles stored under key in the custom claims.
+// Tokens may carry a single role as a string, a comma separated list, or a list of roles,
+// which after a JSON round trip is a []interface{} rather than a []string.
+func (c *CustomClaims) Roles(key string) []string {
+	if c == nil || c.Custom == nil {
+		return nil
+	}
+
+	var roles []string
+	switch v := c.Custom[key].(type) {
+	case string:
+		roles = strings.Split(v, ",")
+	case []string:
+		roles = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+	default:
+		return nil
+	}
+
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			result = append(result, role)
+		}
+	}
+
+	return result
+}
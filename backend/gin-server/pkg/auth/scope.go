@@ -0,0 +1,42 @@
+package auth
+
+import "strings"
+
+const (
+	// ScopeWildcard grants every scope
+	ScopeWildcard = "*"
+	// scopeSeparator separates the resource from the action in a scope, e.g. "reminders:read"
+	scopeSeparator = ":"
+)
+
+// ScopeMatches reports whether a granted scope satisfies the required scope.
+// "*" matches any scope and "resource:*" matches any action on that resource.
+func ScopeMatches(granted, required string) bool {
+	if granted == ScopeWildcard || granted == required {
+		return true
+	}
+
+	resource, action, ok := strings.Cut(granted, scopeSeparator)
+	if !ok || action != ScopeWildcard {
+		return false
+	}
+
+	requiredResource, _, ok := strings.Cut(required, scopeSeparator)
+	return ok && requiredResource == resource
+}
+
+// HasScope reports whether the claims grant the required scope.
+// Only personal access tokens are restricted by scopes, session tokens are granted every scope.
+func (c *CustomClaims) HasScope(required string) bool {
+	if c.TokenType != PersonalAccessToken {
+		return true
+	}
+
+	for _, granted := range c.Scopes {
+		if ScopeMatches(granted, required) {
+			return true
+		}
+	}
+
+	return false
+}
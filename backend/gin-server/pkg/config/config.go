@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 
@@ -13,9 +16,10 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	AppEnv string
-	Port   int
-	DBType string // "sqlite", "mongodb"
+	AppEnv     string
+	Port       int
+	DBType     string // "sqlite", "mongodb"
+	AppBaseURL string // Public URL of the app, used to build links sent by email
 
 	SQLiteFile string
 
@@ -30,6 +34,89 @@ type Config struct {
 	EnableDBSeeding bool
 
 	JWTSecret string
+
+	OIDCProviders []OIDCProviderConfig
+
+	// Login brute-force protection
+	LoginAttemptStore  string        // "database" or "memory"
+	LoginMaxFailures   int           // Failed attempts per account before it is locked
+	LoginIPMaxFailures int           // Failed attempts per client IP before it is locked
+	LoginLockoutBase   time.Duration // First lockout duration, doubled on every further failure
+	LoginLockoutMax    time.Duration // Upper bound of a lockout
+	LoginFailureWindow time.Duration // Failures older than this are forgotten
+
+	// Password policy
+	PasswordMinLength      int
+	PasswordMinCharClasses int  // Out of lower case, upper case, digits and symbols
+	PasswordRejectBreached bool // Reject passwords from the bundled breached password list
+
+	// Personal data exports
+	ExportDir string        // Directory holding export archives
+	ExportTTL time.Duration // How long an archive and its download link stay valid
+	// Key signing download links, kept apart from JWTSecret so links can't be forged from a token
+	// secret and rotating one doesn't invalidate the other
+	ExportSigningSecret string
+
+	// Browser sessions, tokens are also set as HttpOnly cookies and cookie authenticated writes need a CSRF token
+	AuthCookieMode   bool
+	AuthCookieSecure bool   // Only send the cookies over HTTPS, disable for local development over HTTP, applies in every mode
+	AuthCookieDomain string // Defaults to the host of the request
+
+	// Security audit log
+	AuditRetention time.Duration // How long audit events are kept, 0 keeps them forever
+
+	// Shared cache, "memory" keeps the token blacklist and pending logins per instance,
+	// "redis" shares them between instances
+	CacheStore     string
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+	RedisKeyPrefix string
+	RedisPoolSize  int
+
+	// Read users, reminders and reminder groups through the cache store
+	RepositoryCache bool
+
+	// API rate limits in requests per minute, per user when authenticated and per client IP otherwise
+	RateLimitEnabled     bool
+	RateLimitStore       string // "memory", "database" or "cache", the latter two share limits between instances
+	RateLimitFailOpen    bool   // Allow requests when the store is unavailable, otherwise they are refused
+	RateLimitDefault     int    // Most API routes
+	RateLimitAuth        int    // Login, registration and other public auth routes
+	RateLimitLowPriority int    // Expensive, non-critical routes such as username suggestions and exports
+
+	// Proxies, as IPs or CIDRs, allowed to set the client IP through X-Forwarded-For. The client IP
+	// keys the rate limits of anonymous requests, none are trusted by default.
+	TrustedProxies []string
+
+	// Logging, the level can also be changed at runtime by admins
+	LogLevel      string // "debug", "info", "warn" or "error"
+	LogRedaction  string // Masking of passwords, tokens, JWTs and emails: "full", "partial", "hashed" or empty to disable
+	LogBufferSize int    // Recent entries kept in memory for /api/admin/logs, 0 to disable
+
+	// Network log destinations, each is enabled when its address or URL is set
+	LogSyslogNetwork string // "udp" or "tcp"
+	LogSyslogAddress string // host:port of an RFC 5424 syslog server
+	LogHTTPURL       string // Collector receiving batches of entries as JSON arrays
+	LogOTLPURL       string // OTLP/HTTP logs endpoint of an OpenTelemetry collector, e.g. http://localhost:4318/v1/logs
+
+	// Prometheus metrics served on /metrics of a separate listener, kept off the public port
+	MetricsEnabled bool
+	MetricsAddr    string // host:port of the metrics listener, bound to localhost by default
+	MetricsToken   string // Bearer token scrapers must send, optional on top of the internal address
+
+	// Generated usernames
+	UsernamePattern  string // Dash separated segments, e.g. "adjective-noun-NNNN", see usernamegen
+	UsernameWordsDir string // Optional directory with adverbs.txt, adjectives.txt, nouns.txt and blocklist.txt
+}
+
+// OIDCProviderConfig holds the client registration of an OpenID Connect provider
+type OIDCProviderConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
 }
 
 // Load loads configuration from environment variables
@@ -45,6 +132,8 @@ func Load(fileName string) (*Config, error) {
 		Port:   getEnvAsInt("PORT", 8080),
 		DBType: getEnv("DB_TYPE", constants.SQLite),
 
+		AppBaseURL: strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/"),
+
 		SQLiteFile: getEnv("SQLITE_FILE", "./gin-server.db"),
 
 		FirebaseProjectID:            getEnv("FIREBASE_PROJECT_ID", ""),
@@ -58,12 +147,73 @@ func Load(fileName string) (*Config, error) {
 		EnableDBSeeding: getEnvAsInt("ENABLE_DB_SEEDING", 0) == 1,
 
 		JWTSecret: getEnv("JWT_SECRET", constants.DefaultJWTSecret),
+
+		OIDCProviders: getOIDCProviders(),
+
+		LoginAttemptStore:  getEnv("LOGIN_ATTEMPT_STORE", constants.LoginAttemptStoreDatabase),
+		LoginMaxFailures:   getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures: getEnvAsInt("LOGIN_IP_MAX_FAILURES", 20),
+		LoginLockoutBase:   getEnvAsDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:    getEnvAsDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginFailureWindow: getEnvAsDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+
+		PasswordMinLength:      getEnvAsInt("PASSWORD_MIN_LENGTH", 10),
+		PasswordMinCharClasses: getEnvAsInt("PASSWORD_MIN_CHAR_CLASSES", 3),
+		PasswordRejectBreached: getEnvAsInt("PASSWORD_REJECT_BREACHED", 1) == 1,
+
+		ExportDir: getEnv("EXPORT_DIR", filepath.Join(os.TempDir(), "reminder-exports")),
+		ExportTTL: getEnvAsDuration("EXPORT_TTL", 24*time.Hour),
+
+		ExportSigningSecret: getEnv("EXPORT_SIGNING_SECRET", ""),
+
+		AuthCookieMode:   getEnvAsInt("AUTH_COOKIE_MODE", 0) == 1,
+		AuthCookieSecure: getEnvAsInt("AUTH_COOKIE_SECURE", 1) == 1,
+		AuthCookieDomain: getEnv("AUTH_COOKIE_DOMAIN", ""),
+
+		AuditRetention: getEnvAsDuration("AUDIT_RETENTION", 365*24*time.Hour),
+
+		CacheStore:     getEnv("CACHE_STORE", constants.CacheStoreMemory),
+		RedisAddr:      getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:  getEnv("REDIS_PASSWORD", ""),
+		RedisDB:        getEnvAsInt("REDIS_DB", 0),
+		RedisKeyPrefix: getEnv("REDIS_KEY_PREFIX", "reminder:"),
+		RedisPoolSize:  getEnvAsInt("REDIS_POOL_SIZE", 10),
+
+		RepositoryCache: getEnvAsInt("REPOSITORY_CACHE", 1) == 1,
+
+		RateLimitEnabled:     getEnvAsInt("RATE_LIMIT_ENABLED", 1) == 1,
+		RateLimitStore:       getEnv("RATE_LIMIT_STORE", constants.RateLimitStoreMemory),
+		RateLimitFailOpen:    getEnvAsInt("RATE_LIMIT_FAIL_OPEN", 1) == 1,
+		RateLimitDefault:     getEnvAsInt("RATE_LIMIT_DEFAULT", constants.DefaultRateLimit),
+		RateLimitAuth:        getEnvAsInt("RATE_LIMIT_AUTH", constants.AuthRateLimit),
+		RateLimitLowPriority: getEnvAsInt("RATE_LIMIT_LOW_PRIORITY", constants.LowPriorityRateLimit),
+
+		TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+
+		LogLevel:         getEnv("LOG_LEVEL", "debug"),
+		LogRedaction:     getEnv("LOG_REDACTION", "partial"),
+		LogBufferSize:    getEnvAsInt("LOG_BUFFER_SIZE", 1000),
+		LogSyslogNetwork: getEnv("LOG_SYSLOG_NETWORK", "udp"),
+		LogSyslogAddress: getEnv("LOG_SYSLOG_ADDRESS", ""),
+		LogHTTPURL:       getEnv("LOG_HTTP_URL", ""),
+		LogOTLPURL:       getEnv("LOG_OTLP_URL", ""),
+
+		MetricsEnabled: getEnvAsInt("METRICS_ENABLED", 1) == 1,
+		MetricsAddr:    getEnv("METRICS_ADDR", "127.0.0.1:9090"),
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
+
+		UsernamePattern:  getEnv("USERNAME_PATTERN", "adjective-noun-NNNN"),
+		UsernameWordsDir: getEnv("USERNAME_WORDS_DIR", ""),
 	}
 
 	// Validate configuration
 	if config.JWTSecret == constants.DefaultJWTSecret && config.AppEnv == constants.EnvProduction {
 		return nil, fmt.Errorf("JWT_SECRET must be set in production environment")
 	}
+	// There is no safe default, a well known key would let anyone sign download links for any export
+	if config.ExportSigningSecret == "" {
+		return nil, fmt.Errorf("EXPORT_SIGNING_SECRET must be set")
+	}
 
 	return config, nil
 }
@@ -84,3 +234,55 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList splits a comma separated variable, skipping empty items
+func getEnvAsList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getOIDCProviders reads the providers listed in OIDC_PROVIDERS, e.g. "google,okta".
+// Each provider is configured through OIDC_<NAME>_ISSUER_URL, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET and OIDC_<NAME>_REDIRECT_URL.
+func getOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			IssuerURL:    getEnv(prefix+"ISSUER_URL", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", ""),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" || provider.RedirectURL == "" {
+			log.Printf("Skipping OIDC provider %s, issuer url, client id and redirect url are required", name)
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
@@ -35,4 +35,32 @@ const (
 	UsersCollection          = "users"
 	RemindersCollection      = "reminders"
 	ReminderGroupsCollection = "reminder_groups"
+	APITokensCollection      = "api_tokens"
+	UserIdentitiesCollection = "user_identities"
+	LoginAttemptsCollection  = "login_attempts"
+	EmailChangesCollection   = "email_changes"
+	MagicLinksCollection     = "magic_links"
+	ExportJobsCollection     = "export_jobs"
+	ExportClaimsCollection   = "export_claims"
+	AuditEventsCollection    = "audit_events"
+	AuditChainCollection     = "audit_chain"
+)
+
+// Login attempt stores
+const (
+	LoginAttemptStoreDatabase = "database"
+	LoginAttemptStoreMemory   = "memory"
+)
+
+// Rate limit stores, "memory" limits every instance on its own
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStoreDatabase = "database"
+	RateLimitStoreCache    = "cache"
+)
+
+// Cache stores, for state shared between instances such as the token blacklist
+const (
+	CacheStoreMemory = "memory"
+	CacheStoreRedis  = "redis"
 )
@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what an AsyncDestination does with an entry when its buffer is full
+type OverflowPolicy int
+
+const (
+	// DropOldest discards the oldest buffered entry to make room for the new one
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the new entry
+	DropNewest
+	// Block waits for the flusher to make room, slowing down the callers
+	Block
+)
+
+const (
+	defaultAsyncBufferSize   = 4096
+	defaultAsyncDrainTimeout = 5 * time.Second
+)
+
+var (
+	// ErrDestinationClosed is returned when writing to a closed AsyncDestination
+	ErrDestinationClosed = errors.New("logger: destination closed")
+	// ErrDrainTimeout is returned when buffered entries can't be written before the deadline
+	ErrDrainTimeout = errors.New("logger: timed out draining buffered entries")
+)
+
+// AsyncDestination buffers entries and writes them to another destination in the background,
+// so a slow destination doesn't stall the callers. Entries are written after Write returns,
+// the fields of an entry must not be modified once logged.
+type AsyncDestination struct {
+	dest         Destination
+	policy       OverflowPolicy
+	drainTimeout time.Duration
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	buf      []LogEntry
+	head     int
+	count    int
+	writing  bool
+	closed   bool
+	idle     []chan struct{}
+
+	dropped  atomic.Uint64
+	stopped  chan struct{}
+	closeErr error
+}
+
+// AsyncOption configures an AsyncDestination
+type AsyncOption func(*AsyncDestination)
+
+// WithBufferSize sets the number of entries buffered before the overflow policy applies
+func WithBufferSize(size int) AsyncOption {
+	return func(a *AsyncDestination) {
+		if size > 0 {
+			a.buf = make([]LogEntry, size)
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to entries written while the buffer is full
+func WithOverflowPolicy(policy OverflowPolicy) AsyncOption {
+	return func(a *AsyncDestination) {
+		a.policy = policy
+	}
+}
+
+// WithDrainTimeout bounds how long Close and Flush wait for buffered entries to be written
+func WithDrainTimeout(timeout time.Duration) AsyncOption {
+	return func(a *AsyncDestination) {
+		a.drainTimeout = timeout
+	}
+}
+
+// NewAsyncDestination wraps dest and starts the background flusher. Closing the AsyncDestination
+// closes dest.
+func NewAsyncDestination(dest Destination, opts ...AsyncOption) *AsyncDestination {
+	a := &AsyncDestination{
+		dest:         dest,
+		policy:       DropOldest,
+		drainTimeout: defaultAsyncDrainTimeout,
+		stopped:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.buf == nil {
+		a.buf = make([]LogEntry, defaultAsyncBufferSize)
+	}
+	a.notEmpty = sync.NewCond(&a.mu)
+	a.notFull = sync.NewCond(&a.mu)
+
+	go a.run()
+	return a
+}
+
+// Write implements Destination. It buffers the entry, applying the overflow policy when the
+// buffer is full. Fatal entries are flushed before returning, as the program exits right after.
+func (a *AsyncDestination) Write(entry LogEntry) error {
+	fatal := entry.Level == FatalLevel
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrDestinationClosed
+	}
+
+	if a.count == len(a.buf) {
+		switch {
+		case a.policy == Block || fatal:
+			for a.count == len(a.buf) && !a.closed {
+				a.notFull.Wait()
+			}
+			if a.closed {
+				a.mu.Unlock()
+				return ErrDestinationClosed
+			}
+		case a.policy == DropNewest:
+			a.mu.Unlock()
+			a.dropped.Add(1)
+			return nil
+		default:
+			a.buf[a.head] = LogEntry{}
+			a.head = (a.head + 1) % len(a.buf)
+			a.count--
+			a.dropped.Add(1)
+		}
+	}
+
+	a.buf[(a.head+a.count)%len(a.buf)] = entry
+	a.count++
+	a.notEmpty.Signal()
+	a.mu.Unlock()
+
+	if fatal {
+		return a.Flush()
+	}
+	return nil
+}
+
+// Flush waits until the buffered entries are written, up to the drain timeout
+func (a *AsyncDestination) Flush() error {
+	a.mu.Lock()
+	if a.count == 0 && !a.writing {
+		a.mu.Unlock()
+		return nil
+	}
+	done := make(chan struct{})
+	a.idle = append(a.idle, done)
+	a.mu.Unlock()
+
+	timer := time.NewTimer(a.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-a.stopped:
+		return nil
+	case <-timer.C:
+		return ErrDrainTimeout
+	}
+}
+
+// Dropped returns the number of entries discarded because the buffer was full or couldn't be
+// drained in time
+func (a *AsyncDestination) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Unwrap returns the wrapped destination
+func (a *AsyncDestination) Unwrap() Destination {
+	return a.dest
+}
+
+// Len returns the number of buffered entries
+func (a *AsyncDestination) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.count
+}
+
+// Close implements Destination. It stops accepting entries and waits up to the drain timeout
+// for the buffered ones to be written. Entries left after the deadline are dropped, and the
+// wrapped destination is closed once the write in progress completes.
+func (a *AsyncDestination) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		<-a.stopped
+		return a.closeErr
+	}
+	a.closed = true
+	a.notEmpty.Signal()
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	timer := time.NewTimer(a.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-a.stopped:
+		return a.closeErr
+	case <-timer.C:
+	}
+
+	a.mu.Lock()
+	lost := a.count
+	for i := range a.buf {
+		a.buf[i] = LogEntry{}
+	}
+	a.head, a.count = 0, 0
+	a.mu.Unlock()
+
+	a.dropped.Add(uint64(lost))
+	return fmt.Errorf("%w: %d entries dropped", ErrDrainTimeout, lost)
+}
+
+// run writes the buffered entries in batches until the destination is closed and drained
+func (a *AsyncDestination) run() {
+	defer close(a.stopped)
+
+	batch := make([]LogEntry, 0, len(a.buf))
+	for {
+		a.mu.Lock()
+		for a.count == 0 && !a.closed {
+			a.notEmpty.Wait()
+		}
+		if a.count == 0 {
+			a.notifyIdle()
+			a.mu.Unlock()
+			break
+		}
+
+		for ; a.count > 0; a.count-- {
+			batch = append(batch, a.buf[a.head])
+			a.buf[a.head] = LogEntry{}
+			a.head = (a.head + 1) % len(a.buf)
+		}
+		a.writing = true
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		for _, entry := range batch {
+			if err := a.dest.Write(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write buffered log: %v\n", err)
+			}
+		}
+		clear(batch)
+		batch = batch[:0]
+
+		a.mu.Lock()
+		a.writing = false
+		if a.count == 0 {
+			a.notifyIdle()
+		}
+		a.mu.Unlock()
+	}
+
+	a.closeErr = a.dest.Close()
+}
+
+// notifyIdle releases the Flush calls waiting for the buffer to drain, a.mu must be held
+func (a *AsyncDestination) notifyIdle() {
+	for _, done := range a.idle {
+		close(done)
+	}
+	a.idle = nil
+}
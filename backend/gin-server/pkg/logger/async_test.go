@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedDestination blocks every write until the gate is opened, simulating a slow destination
+type gatedDestination struct {
+	gate    chan struct{}
+	started chan struct{}
+
+	mu       sync.Mutex
+	messages []string
+	closed   bool
+}
+
+func newGatedDestination() *gatedDestination {
+	return &gatedDestination{gate: make(chan struct{}), started: make(chan struct{}, 1)}
+}
+
+func (d *gatedDestination) Write(entry LogEntry) error {
+	select {
+	case d.started <- struct{}{}:
+	default:
+	}
+	<-d.gate
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.messages = append(d.messages, entry.Message)
+	return nil
+}
+
+func (d *gatedDestination) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+func (d *gatedDestination) Messages() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.messages...)
+}
+
+func (d *gatedDestination) Closed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// stall writes a first entry and waits until the flusher is blocked on it, so the buffer
+// fills up behind it
+func stall(t *testing.T, a *AsyncDestination, d *gatedDestination) {
+	t.Helper()
+	require.NoError(t, a.Write(LogEntry{Message: "first"}))
+	select {
+	case <-d.started:
+	case <-time.After(time.Second):
+		t.Fatal("the flusher didn't start writing")
+	}
+}
+
+func TestAsyncDestination(t *testing.T) {
+	testDest := NewRingBufferDestination(0)
+	async := NewAsyncDestination(testDest)
+
+	logger := New(WithServiceName("test-service"))
+	logger.AddDestination("async", async)
+	logger.SetDefaultDestinations("async")
+
+	for i := 0; i < 100; i++ {
+		logger.Infof("message %d", i)
+	}
+	logger.Close()
+
+	require.Len(t, testDest.Entries(), 100)
+	for i, entry := range testDest.Entries() {
+		assert.Equal(t, "test-service", entry.ServiceName)
+		assert.Equal(t, fmt.Sprintf("message %d", i), entry.Message, "entries keep their order")
+	}
+	assert.Zero(t, async.Dropped())
+	assert.ErrorIs(t, async.Write(LogEntry{}), ErrDestinationClosed)
+}
+
+func TestAsyncDestinationOverflow(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   OverflowPolicy
+		expected []string
+	}{
+		{"drop oldest", DropOldest, []string{"first", "c", "d"}},
+		{"drop newest", DropNewest, []string{"first", "a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := newGatedDestination()
+			async := NewAsyncDestination(dest, WithBufferSize(2), WithOverflowPolicy(tt.policy))
+			stall(t, async, dest)
+
+			for _, msg := range []string{"a", "b", "c", "d"} {
+				require.NoError(t, async.Write(LogEntry{Message: msg}))
+			}
+			assert.Equal(t, 2, async.Len())
+			assert.Equal(t, uint64(2), async.Dropped())
+
+			close(dest.gate)
+			require.NoError(t, async.Close())
+			assert.Equal(t, tt.expected, dest.Messages())
+			assert.True(t, dest.Closed())
+		})
+	}
+}
+
+func TestAsyncDestinationBlock(t *testing.T) {
+	dest := newGatedDestination()
+	async := NewAsyncDestination(dest, WithBufferSize(1), WithOverflowPolicy(Block))
+	stall(t, async, dest)
+	require.NoError(t, async.Write(LogEntry{Message: "a"}))
+
+	written := make(chan error, 1)
+	go func() {
+		written <- async.Write(LogEntry{Message: "b"})
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("the write should block while the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(dest.gate)
+	select {
+	case err := <-written:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the write should complete once the buffer drains")
+	}
+
+	require.NoError(t, async.Close())
+	assert.Equal(t, []string{"first", "a", "b"}, dest.Messages())
+	assert.Zero(t, async.Dropped())
+}
+
+func TestAsyncDestinationFlush(t *testing.T) {
+	dest := newGatedDestination()
+	async := NewAsyncDestination(dest, WithDrainTimeout(20*time.Millisecond))
+	stall(t, async, dest)
+
+	assert.ErrorIs(t, async.Flush(), ErrDrainTimeout)
+
+	close(dest.gate)
+	require.NoError(t, async.Flush())
+	assert.Equal(t, []string{"first"}, dest.Messages())
+	require.NoError(t, async.Close())
+}
+
+func TestAsyncDestinationCloseTimeout(t *testing.T) {
+	dest := newGatedDestination()
+	async := NewAsyncDestination(dest, WithDrainTimeout(20*time.Millisecond))
+	stall(t, async, dest)
+	require.NoError(t, async.Write(LogEntry{Message: "a"}))
+	require.NoError(t, async.Write(LogEntry{Message: "b"}))
+
+	err := async.Close()
+	assert.ErrorIs(t, err, ErrDrainTimeout)
+	assert.Equal(t, uint64(2), async.Dropped(), "entries left after the deadline are dropped")
+	assert.False(t, dest.Closed(), "the write in progress isn't interrupted")
+
+	// The wrapped destination is closed once the write in progress completes
+	close(dest.gate)
+	assert.Eventually(t, dest.Closed, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"first"}, dest.Messages())
+}
@@ -0,0 +1,370 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultBatchSize      = 100
+	defaultFlushInterval  = time.Second
+	defaultMaxBuffered    = 10000
+	defaultBatchTimeout   = 10 * time.Second
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// BatchConfig configures the destinations sending batches of entries over HTTP
+type BatchConfig struct {
+	// URL receiving the batches
+	URL string
+	// Headers added to every request, such as credentials
+	Headers map[string]string
+	// BatchSize is the number of entries sending a batch before the flush interval
+	BatchSize int
+	// FlushInterval is the longest an entry waits to be sent
+	FlushInterval time.Duration
+	// MaxBuffered bounds the entries waiting to be sent, newer entries are dropped beyond it
+	MaxBuffered int
+	// Gzip compresses the requests
+	Gzip bool
+	// Timeout bounds each request
+	Timeout time.Duration
+	// MaxRetries is the number of retries of a failed batch, with exponential backoff
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry, doubled up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Client sends the requests, a client with Timeout by default
+	Client *http.Client
+}
+
+// withDefaults validates the config and fills the unset values
+func (c BatchConfig) withDefaults() (BatchConfig, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return c, fmt.Errorf("logger: invalid batch URL %q", c.URL)
+	}
+
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultFlushInterval
+	}
+	if c.MaxBuffered < c.BatchSize {
+		c.MaxBuffered = max(defaultMaxBuffered, c.BatchSize)
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultBatchTimeout
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = defaultInitialBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = max(defaultMaxBackoff, c.InitialBackoff)
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: c.Timeout}
+	}
+	return c, nil
+}
+
+// timedEntry is an entry with the time it was logged, as batches are sent later
+type timedEntry struct {
+	LogEntry
+	time time.Time
+}
+
+// batchEncoder encodes a batch into a request body of the content type
+type batchEncoder struct {
+	contentType string
+	encode      func(entries []timedEntry) ([]byte, error)
+}
+
+// errPermanent marks failures that retrying can't fix, such as a rejected batch
+var errPermanent = errors.New("permanent failure")
+
+// batchSender buffers entries and sends them in batches from a background goroutine
+type batchSender struct {
+	config  BatchConfig
+	encoder batchEncoder
+
+	mu      sync.Mutex
+	pending []timedEntry
+	closed  bool
+
+	flush   chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	dropped atomic.Uint64
+}
+
+func newBatchSender(config BatchConfig, encoder batchEncoder) (*batchSender, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &batchSender{
+		config:  config,
+		encoder: encoder,
+		flush:   make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// write buffers an entry. Fatal entries are sent with the pending ones before returning, as the
+// program exits right after.
+func (s *batchSender) write(entry LogEntry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrDestinationClosed
+	}
+	if len(s.pending) >= s.config.MaxBuffered {
+		s.mu.Unlock()
+		s.dropped.Add(1)
+		return nil
+	}
+	s.pending = append(s.pending, timedEntry{LogEntry: entry, time: time.Now()})
+	full := len(s.pending) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if entry.Level == FatalLevel {
+		s.sendPending(true)
+		return nil
+	}
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *batchSender) run() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendPending(false)
+		case <-s.flush:
+			s.sendPending(false)
+		case <-s.done:
+			s.sendPending(true)
+			return
+		}
+	}
+}
+
+// sendPending sends the pending entries in batches. Once closing, failed batches aren't retried.
+func (s *batchSender) sendPending(closing bool) {
+	for {
+		s.mu.Lock()
+		n := min(len(s.pending), s.config.BatchSize)
+		if n == 0 {
+			s.mu.Unlock()
+			return
+		}
+		batch := s.pending[:n:n]
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+
+		if err := s.send(batch, closing); err != nil {
+			s.dropped.Add(uint64(len(batch)))
+			fmt.Fprintf(os.Stderr, "Failed to send %d log entries: %v\n", len(batch), err)
+		}
+	}
+}
+
+// send posts a batch, retrying network errors, throttling and server errors with backoff
+func (s *batchSender) send(batch []timedEntry, closing bool) error {
+	body, err := s.encoder.encode(batch)
+	if err != nil {
+		return err
+	}
+
+	if s.config.Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	retries := s.config.MaxRetries
+	if closing {
+		retries = 0
+	}
+
+	backoff := s.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err = s.post(body)
+		if err == nil || errors.Is(err, errPermanent) || attempt >= retries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// Closing, one last attempt without waiting
+			retries = attempt + 1
+		}
+		backoff = min(backoff*2, s.config.MaxBackoff)
+	}
+}
+
+func (s *batchSender) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", s.encoder.contentType)
+	if s.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: unexpected status %s", errPermanent, resp.Status)
+	}
+}
+
+// close stops accepting entries and sends the pending ones
+func (s *batchSender) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.stopped
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	<-s.stopped
+	return nil
+}
+
+// HTTPBatchDestination sends entries to an HTTP collector as JSON arrays, in batches by size
+// and time
+type HTTPBatchDestination struct {
+	sender *batchSender
+}
+
+// httpBatchEntry is the JSON encoding of an entry sent by HTTPBatchDestination
+type httpBatchEntry struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Service   string                 `json:"service,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+// NewHTTPBatchDestination creates an HTTP batch destination and starts sending batches
+func NewHTTPBatchDestination(config BatchConfig) (*HTTPBatchDestination, error) {
+	sender, err := newBatchSender(config, batchEncoder{
+		contentType: "application/json",
+		encode: func(entries []timedEntry) ([]byte, error) {
+			batch := make([]httpBatchEntry, len(entries))
+			for i, entry := range entries {
+				batch[i] = httpBatchEntry{
+					Timestamp: entry.time.UTC().Format(time.RFC3339Nano),
+					Level:     entry.Level.String(),
+					Service:   entry.ServiceName,
+					Message:   entry.Message,
+					Fields:    jsonFields(entry.Fields),
+				}
+			}
+			return json.Marshal(batch)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPBatchDestination{sender: sender}, nil
+}
+
+// Write implements Destination
+func (h *HTTPBatchDestination) Write(entry LogEntry) error {
+	return h.sender.write(entry)
+}
+
+// Dropped returns the number of entries dropped because the buffer was full or a batch failed
+func (h *HTTPBatchDestination) Dropped() uint64 {
+	return h.sender.dropped.Load()
+}
+
+// Close implements Destination, sending the pending entries
+func (h *HTTPBatchDestination) Close() error {
+	return h.sender.close()
+}
+
+// jsonFields returns the fields with errors replaced by their message, as they encode to {} otherwise
+func jsonFields(fields map[string]interface{}) map[string]interface{} {
+	var converted map[string]interface{}
+	for k, v := range fields {
+		err, ok := v.(error)
+		if !ok {
+			continue
+		}
+		if converted == nil {
+			converted = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				converted[k] = v
+			}
+		}
+		converted[k] = err.Error()
+	}
+	if converted == nil {
+		return fields
+	}
+	return converted
+}
@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector records the bodies of the requests it receives, failing the first ones with failStatus
+type collector struct {
+	failures   atomic.Int32
+	failStatus int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if c.failures.Add(-1) >= 0 {
+		w.WriteHeader(c.failStatus)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, r)
+	c.bodies = append(c.bodies, data)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (c *collector) Bodies() [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.bodies...)
+}
+
+func (c *collector) Requests() []*http.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*http.Request(nil), c.requests...)
+}
+
+func decodeBatches(t *testing.T, bodies [][]byte) [][]httpBatchEntry {
+	t.Helper()
+	batches := make([][]httpBatchEntry, len(bodies))
+	for i, body := range bodies {
+		require.NoError(t, json.Unmarshal(body, &batches[i]))
+	}
+	return batches
+}
+
+func TestHTTPBatchDestination(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	dest, err := NewHTTPBatchDestination(BatchConfig{
+		URL:           server.URL,
+		Headers:       map[string]string{"Authorization": "Bearer token"},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Gzip:          true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "svc", Level: InfoLevel, Message: "one",
+		Fields: map[string]interface{}{"error": errors.New("boom"), "count": 1}}))
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "svc", Level: WarnLevel, Message: "two"}))
+
+	// A full batch is sent without waiting for the flush interval
+	require.Eventually(t, func() bool { return len(c.Bodies()) == 1 }, time.Second, 5*time.Millisecond)
+
+	// The rest is sent on close
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "svc", Level: ErrorLevel, Message: "three"}))
+	require.NoError(t, dest.Close())
+	assert.ErrorIs(t, dest.Write(LogEntry{}), ErrDestinationClosed)
+
+	batches := decodeBatches(t, c.Bodies())
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1)
+
+	first := batches[0][0]
+	assert.Equal(t, "svc", first.Service)
+	assert.Equal(t, LogLevelInfo, first.Level)
+	assert.Equal(t, "one", first.Message)
+	assert.Equal(t, map[string]interface{}{"error": "boom", "count": float64(1)}, first.Fields)
+	_, err = time.Parse(time.RFC3339Nano, first.Timestamp)
+	assert.NoError(t, err)
+	assert.Equal(t, "three", batches[1][0].Message)
+
+	req := c.Requests()[0]
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Zero(t, dest.Dropped())
+}
+
+func TestHTTPBatchDestinationFlushInterval(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	dest, err := NewHTTPBatchDestination(BatchConfig{URL: server.URL, FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer dest.Close()
+
+	require.NoError(t, dest.Write(LogEntry{Level: InfoLevel, Message: "one"}))
+	assert.Eventually(t, func() bool { return len(c.Bodies()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestHTTPBatchDestinationRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		failStatus int
+		failures   int32
+		delivered  bool
+	}{
+		{"server errors are retried", http.StatusServiceUnavailable, 2, true},
+		{"throttling is retried", http.StatusTooManyRequests, 1, true},
+		{"retries are bounded", http.StatusBadGateway, 10, false},
+		{"rejected batches aren't retried", http.StatusBadRequest, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &collector{failStatus: tt.failStatus}
+			c.failures.Store(tt.failures)
+			server := httptest.NewServer(c)
+			defer server.Close()
+
+			dest, err := NewHTTPBatchDestination(BatchConfig{
+				URL:            server.URL,
+				BatchSize:      1,
+				FlushInterval:  time.Hour,
+				MaxRetries:     2,
+				InitialBackoff: time.Millisecond,
+			})
+			require.NoError(t, err)
+			defer dest.Close()
+
+			require.NoError(t, dest.Write(LogEntry{Level: InfoLevel, Message: "one"}))
+
+			if tt.delivered {
+				assert.Eventually(t, func() bool { return len(c.Bodies()) == 1 }, time.Second, 5*time.Millisecond)
+				assert.Zero(t, dest.Dropped())
+			} else {
+				assert.Eventually(t, func() bool { return dest.Dropped() == 1 }, time.Second, 5*time.Millisecond)
+				assert.Empty(t, c.Bodies())
+			}
+		})
+	}
+}
+
+func TestHTTPBatchDestinationMaxBuffered(t *testing.T) {
+	dest, err := NewHTTPBatchDestination(BatchConfig{
+		URL:           "http://127.0.0.1:1",
+		BatchSize:     2,
+		MaxBuffered:   2,
+		FlushInterval: time.Hour,
+		MaxRetries:    -1,
+	})
+	require.NoError(t, err)
+
+	// Fill the buffer without signaling the sender, so the entries stay pending
+	dest.sender.mu.Lock()
+	dest.sender.pending = append(dest.sender.pending, timedEntry{}, timedEntry{})
+	dest.sender.mu.Unlock()
+
+	require.NoError(t, dest.Write(LogEntry{Message: "dropped"}))
+	assert.Equal(t, uint64(1), dest.Dropped())
+	_ = dest.Close()
+}
+
+func TestBatchConfig(t *testing.T) {
+	for _, url := range []string{"", "collector:8080", "ftp://collector"} {
+		_, err := NewHTTPBatchDestination(BatchConfig{URL: url})
+		assert.Error(t, err, url)
+	}
+}
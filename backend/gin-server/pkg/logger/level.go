@@ -0,0 +1,239 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// FieldComponent is the field naming the component of loggers created by WithComponent
+const FieldComponent = "component"
+
+// ParseLevel parses a level name such as "debug" or "WARN"
+func ParseLevel(s string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case LogLevelDebug:
+		return DebugLevel, nil
+	case LogLevelInfo:
+		return InfoLevel, nil
+	case LogLevelWarn, "WARNING":
+		return WarnLevel, nil
+	case LogLevelError:
+		return ErrorLevel, nil
+	case LogLevelFatal:
+		return FatalLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("logger: unknown level %q", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, levels are encoded by name
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// levels holds the levels of a logger, which can change at runtime. The overrides are copied on
+// write so logging reads them without locking.
+type levels struct {
+	global       atomic.Int32
+	components   atomic.Pointer[map[string]LogLevel]
+	destinations atomic.Pointer[map[string]LogLevel]
+
+	// mu serializes the changes and guards the pending reverts
+	mu      sync.Mutex
+	reverts map[string]*levelRevert
+}
+
+// levelRevert restores a level when its timer fires
+type levelRevert struct {
+	timer   *time.Timer
+	at      time.Time
+	restore func()
+}
+
+// Levels is a snapshot of the levels of a logger
+type Levels struct {
+	// Level is the minimum level of loggers without a component override
+	Level LogLevel `json:"level"`
+	// Components overrides the minimum level of the loggers created by WithComponent
+	Components map[string]LogLevel `json:"components"`
+	// Destinations skip the entries below their level
+	Destinations map[string]LogLevel `json:"destinations"`
+	// Reverts are the times temporary changes are undone, by "level", "component:<name>" or
+	// "destination:<name>"
+	Reverts map[string]time.Time `json:"reverts,omitempty"`
+}
+
+func newLevels(level LogLevel) *levels {
+	lv := &levels{reverts: map[string]*levelRevert{}}
+	lv.global.Store(int32(level))
+	lv.components.Store(&map[string]LogLevel{})
+	lv.destinations.Store(&map[string]LogLevel{})
+	return lv
+}
+
+func (lv *levels) level() LogLevel {
+	return LogLevel(lv.global.Load())
+}
+
+// override returns the level of name in an override map
+func override(m *atomic.Pointer[map[string]LogLevel], name string) (LogLevel, bool) {
+	level, ok := (*m.Load())[name]
+	return level, ok
+}
+
+// setOverride sets or, when ok is false, removes the level of name in an override map, lv.mu
+// must be held
+func setOverride(m *atomic.Pointer[map[string]LogLevel], name string, level LogLevel, ok bool) {
+	current := *m.Load()
+	updated := make(map[string]LogLevel, len(current)+1)
+	for k, v := range current {
+		updated[k] = v
+	}
+	if ok {
+		updated[name] = level
+	} else {
+		delete(updated, name)
+	}
+	m.Store(&updated)
+}
+
+// change applies a level change to target. With a positive revertAfter, the previous level is
+// restored once it elapses. A pending revert of the same target is kept, so successive temporary
+// changes go back to the level before the first one, and a permanent change cancels it.
+func (lv *levels) change(target string, get func() (LogLevel, bool), set func(LogLevel, bool), level LogLevel, ok bool, revertAfter time.Duration) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	pending := lv.reverts[target]
+	if pending != nil {
+		pending.timer.Stop()
+		delete(lv.reverts, target)
+	}
+
+	var restore func()
+	if pending != nil {
+		restore = pending.restore
+	} else {
+		previous, wasSet := get()
+		restore = func() { set(previous, wasSet) }
+	}
+
+	set(level, ok)
+	if revertAfter <= 0 {
+		return
+	}
+
+	revert := &levelRevert{at: time.Now().Add(revertAfter), restore: restore}
+	revert.timer = time.AfterFunc(revertAfter, func() {
+		lv.mu.Lock()
+		defer lv.mu.Unlock()
+		// A later change replaced this revert
+		if lv.reverts[target] != revert {
+			return
+		}
+		delete(lv.reverts, target)
+		revert.restore()
+	})
+	lv.reverts[target] = revert
+}
+
+// stop cancels the pending reverts
+func (lv *levels) stop() {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	for target, revert := range lv.reverts {
+		revert.timer.Stop()
+		delete(lv.reverts, target)
+	}
+}
+
+// Level returns the minimum level of loggers without a component override
+func (l *Logger) Level() LogLevel {
+	return l.levels.level()
+}
+
+// SetLevel changes the minimum level. With a positive revertAfter, the previous level is restored
+// once it elapses, such as to debug a production issue for a few minutes.
+func (l *Logger) SetLevel(level LogLevel, revertAfter time.Duration) {
+	lv := l.levels
+	lv.change("level",
+		func() (LogLevel, bool) { return lv.level(), true },
+		func(level LogLevel, _ bool) { lv.global.Store(int32(level)) },
+		level, true, revertAfter)
+}
+
+// SetComponentLevel overrides the minimum level of the loggers of a component, see SetLevel for
+// revertAfter
+func (l *Logger) SetComponentLevel(component string, level LogLevel, revertAfter time.Duration) {
+	l.changeOverride("component:"+component, &l.levels.components, component, level, true, revertAfter)
+}
+
+// ResetComponentLevel removes the override of a component, its loggers use the minimum level again
+func (l *Logger) ResetComponentLevel(component string) {
+	l.changeOverride("component:"+component, &l.levels.components, component, 0, false, 0)
+}
+
+// SetDestinationLevel makes a destination skip the entries below level, see SetLevel for revertAfter.
+// It only filters the entries passing the minimum level.
+func (l *Logger) SetDestinationLevel(name string, level LogLevel, revertAfter time.Duration) {
+	l.changeOverride("destination:"+name, &l.levels.destinations, name, level, true, revertAfter)
+}
+
+// ResetDestinationLevel makes a destination write every entry passing the minimum level again
+func (l *Logger) ResetDestinationLevel(name string) {
+	l.changeOverride("destination:"+name, &l.levels.destinations, name, 0, false, 0)
+}
+
+func (l *Logger) changeOverride(target string, m *atomic.Pointer[map[string]LogLevel], name string, level LogLevel, ok bool, revertAfter time.Duration) {
+	l.levels.change(target,
+		func() (LogLevel, bool) { return override(m, name) },
+		func(level LogLevel, ok bool) { setOverride(m, name, level, ok) },
+		level, ok, revertAfter)
+}
+
+// Levels returns the current levels and pending reverts
+func (l *Logger) Levels() Levels {
+	lv := l.levels
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	snapshot := Levels{
+		Level:        lv.level(),
+		Components:   make(map[string]LogLevel),
+		Destinations: make(map[string]LogLevel),
+	}
+	for k, v := range *lv.components.Load() {
+		snapshot.Components[k] = v
+	}
+	for k, v := range *lv.destinations.Load() {
+		snapshot.Destinations[k] = v
+	}
+	if len(lv.reverts) > 0 {
+		snapshot.Reverts = make(map[string]time.Time, len(lv.reverts))
+		for target, revert := range lv.reverts {
+			snapshot.Reverts[target] = revert.at
+		}
+	}
+	return snapshot
+}
+
+// WithComponent returns a child logger of a component, such as a package or service. Its messages
+// carry the component field and its minimum level can be overridden with SetComponentLevel.
+func (l *Logger) WithComponent(component string) *Logger {
+	child := l.WithFields(map[string]interface{}{FieldComponent: component})
+	child.component = component
+	return child
+}
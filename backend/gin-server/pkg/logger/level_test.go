@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	for s, expected := range map[string]LogLevel{"debug": DebugLevel, "INFO": InfoLevel, "warning": WarnLevel, " Error ": ErrorLevel} {
+		level, err := ParseLevel(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, level, s)
+	}
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+
+	var level LogLevel
+	require.NoError(t, json.Unmarshal([]byte(`"warn"`), &level))
+	assert.Equal(t, WarnLevel, level)
+	data, err := json.Marshal(ErrorLevel)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"ERROR"`, string(data))
+}
+
+func TestLoggerSetLevel(t *testing.T) {
+	testDest := NewRingBufferDestination(0)
+	logger := New(WithProduction(true))
+	logger.AddDestination("test", testDest)
+	logger.SetDefaultDestinations("test")
+
+	logger.Debugf("dropped")
+	logger.SetLevel(DebugLevel, 0)
+	logger.Debugf("logged in production")
+	logger.WithFields(map[string]interface{}{"k": "v"}).Debugf("children share the level")
+
+	require.Len(t, testDest.Entries(), 2)
+	assert.Equal(t, DebugLevel, logger.Level())
+	assert.Empty(t, logger.Levels().Reverts, "permanent changes don't revert")
+}
+
+func TestLoggerSetLevelRevert(t *testing.T) {
+	logger := New(WithMinLevel(WarnLevel))
+
+	logger.SetLevel(InfoLevel, time.Hour)
+	logger.SetLevel(DebugLevel, 20*time.Millisecond)
+	assert.Equal(t, DebugLevel, logger.Level())
+
+	levels := logger.Levels()
+	require.Contains(t, levels.Reverts, "level")
+	assert.WithinDuration(t, time.Now().Add(20*time.Millisecond), levels.Reverts["level"], time.Second)
+
+	// Successive temporary changes revert to the level before the first one
+	assert.Eventually(t, func() bool { return logger.Level() == WarnLevel }, time.Second, 5*time.Millisecond)
+	assert.Empty(t, logger.Levels().Reverts)
+
+	// A permanent change cancels the pending revert
+	logger.SetLevel(DebugLevel, 20*time.Millisecond)
+	logger.SetLevel(ErrorLevel, 0)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, ErrorLevel, logger.Level())
+}
+
+func TestLoggerComponentLevel(t *testing.T) {
+	testDest := NewRingBufferDestination(0)
+	logger := New(WithMinLevel(InfoLevel))
+	logger.AddDestination("test", testDest)
+	logger.SetDefaultDestinations("test")
+
+	export := logger.WithComponent("export")
+	logger.SetComponentLevel("export", DebugLevel, 0)
+	logger.SetComponentLevel("auth", ErrorLevel, 0)
+
+	export.Debugf("export debug")
+	export.WithContext(NewContext(context.Background(), logger.WithFields(map[string]interface{}{FieldRequestID: "r1"}))).Debugf("request debug")
+	logger.Debugf("dropped, no component")
+	logger.WithComponent("auth").Warnf("dropped, auth logs errors only")
+	logger.WithComponent("users").Infof("users uses the minimum level")
+
+	require.Len(t, testDest.Entries(), 3)
+	assert.Equal(t, "export debug", testDest.Entries()[0].Message)
+	assert.Equal(t, "export", testDest.Entries()[0].Fields[FieldComponent])
+	assert.Equal(t, "r1", testDest.Entries()[1].Fields[FieldRequestID], "request loggers keep the component")
+	assert.Equal(t, "users", testDest.Entries()[2].Fields[FieldComponent])
+
+	logger.ResetComponentLevel("export")
+	export.Debugf("dropped again")
+	assert.Len(t, testDest.Entries(), 3)
+	assert.Equal(t, map[string]LogLevel{"auth": ErrorLevel}, logger.Levels().Components)
+}
+
+func TestLoggerDestinationLevel(t *testing.T) {
+	console := NewRingBufferDestination(0)
+	file := NewRingBufferDestination(0)
+	logger := New(WithMinLevel(DebugLevel))
+	logger.AddDestination("console", console)
+	logger.AddDestination("file", file)
+	logger.SetDefaultDestinations("console", "file")
+
+	logger.SetDestinationLevel("file", WarnLevel, 20*time.Millisecond)
+	logger.Infof("console only")
+	logger.Warnf("both")
+
+	assert.Len(t, console.Entries(), 2)
+	require.Len(t, file.Entries(), 1)
+	assert.Equal(t, "both", file.Entries()[0].Message)
+
+	// The destination goes back to writing everything
+	assert.Eventually(t, func() bool { return len(logger.Levels().Destinations) == 0 }, time.Second, 5*time.Millisecond)
+	logger.Infof("both again")
+	assert.Len(t, file.Entries(), 2)
+}
@@ -2,10 +2,12 @@
 package logger
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"syscall"
 
@@ -43,6 +45,17 @@ const (
 const (
 	FileLogger    = "file"
 	ConsoleLogger = "console"
+	SyslogLogger  = "syslog"
+	HTTPLogger    = "http"
+	OTLPLogger    = "otlp"
+	MemoryLogger  = "memory"
+)
+
+// Fields attached to request scoped loggers
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldRoute     = "route"
 )
 
 // String returns string representation of log level
@@ -222,10 +235,18 @@ func (f *FileDestination) Close() error {
 	return f.lumberjack.Close()
 }
 
-// Logger is the main logger interface
+// Logger is the main logger interface. Loggers derived with WithFields share the
+// configuration and destinations of the logger they come from.
 type Logger struct {
+	*core
+	fields    map[string]interface{}
+	component string
+}
+
+// core is the state shared by a logger and the loggers derived from it
+type core struct {
 	serviceName  string
-	minLevel     LogLevel
+	levels       *levels
 	isProd       bool
 	destinations map[string]Destination
 	defaultDests []string
@@ -245,7 +266,7 @@ func WithServiceName(name string) Option {
 // WithMinLevel sets the minimum log level
 func WithMinLevel(level LogLevel) Option {
 	return func(l *Logger) {
-		l.minLevel = level
+		l.levels.global.Store(int32(level))
 	}
 }
 
@@ -279,13 +300,13 @@ func WithFileDestination(path string, maxSize, maxBackups, maxAge int, compress
 
 // New creates a new logger with the given options
 func New(options ...Option) *Logger {
-	l := &Logger{
+	l := &Logger{core: &core{
 		serviceName:  "",
-		minLevel:     InfoLevel,
+		levels:       newLevels(InfoLevel),
 		isProd:       false,
 		destinations: make(map[string]Destination),
 		defaultDests: []string{},
-	}
+	}}
 
 	// Apply options
 	for _, option := range options {
@@ -293,8 +314,8 @@ func New(options ...Option) *Logger {
 	}
 
 	// If in production mode, set minimum level to info
-	if l.isProd && l.minLevel < InfoLevel {
-		l.minLevel = InfoLevel
+	if l.isProd && l.Level() < InfoLevel {
+		l.levels.global.Store(int32(InfoLevel))
 	}
 
 	return l
@@ -302,14 +323,14 @@ func New(options ...Option) *Logger {
 
 // log sends the log message to specified destinations
 func (l *Logger) log(level LogLevel, msg string, fields map[string]interface{}, dests ...string) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-
 	// Skip if level is below minimum (especially for debug in prod)
-	if level < l.minLevel {
+	if !l.enabled(level) {
 		return
 	}
 
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
 	// If no destinations specified, use defaults
 	if len(dests) == 0 {
 		dests = l.defaultDests
@@ -319,11 +340,15 @@ func (l *Logger) log(level LogLevel, msg string, fields map[string]interface{},
 		ServiceName: l.serviceName,
 		Level:       level,
 		Message:     msg,
-		Fields:      fields,
+		Fields:      l.merge(fields),
 	}
 
 	// Write to all specified destinations
+	destLevels := *l.levels.destinations.Load()
 	for _, destName := range dests {
+		if minLevel, ok := destLevels[destName]; ok && level < minLevel {
+			continue
+		}
 		if dest, ok := l.destinations[destName]; ok {
 			// Just log destination write errors to stderr for now
 			if err := dest.Write(entry); err != nil {
@@ -333,6 +358,87 @@ func (l *Logger) log(level LogLevel, msg string, fields map[string]interface{},
 	}
 }
 
+// enabled reports whether messages of the level are logged, so formatting can be skipped otherwise.
+// The override of the component of the logger takes precedence over the minimum level.
+func (l *Logger) enabled(level LogLevel) bool {
+	if l.component != "" {
+		if minLevel, ok := override(&l.levels.components, l.component); ok {
+			return level >= minLevel
+		}
+	}
+	return level >= l.levels.level()
+}
+
+// merge returns the fields of the logger overridden by the fields of a message
+func (l *Logger) merge(fields map[string]interface{}) map[string]interface{} {
+	if len(l.fields) == 0 {
+		return fields
+	}
+	if len(fields) == 0 {
+		return l.fields
+	}
+
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+// WithFields returns a child logger adding the fields to every message, on top of the fields
+// of l. The child writes to the same destinations as l.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	return &Logger{core: l.core, fields: l.merge(fields), component: l.component}
+}
+
+// WithContext returns a child logger with the fields of the logger carried by ctx, so
+// components holding their own logger can link their messages to the current request
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	scoped, ok := ctx.Value(contextKey{}).(*Logger)
+	if !ok || len(scoped.fields) == 0 {
+		return l
+	}
+	return l.WithFields(scoped.fields)
+}
+
+type contextKey struct{}
+
+var (
+	defaultLogger   = New(WithConsoleDestination(), WithDefaultDestinations(ConsoleLogger))
+	defaultLoggerMu sync.RWMutex
+)
+
+// NewContext returns a copy of ctx carrying the logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger when there is none
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// Default returns the logger used by FromContext outside of a request, which writes to the console
+// until SetDefault is called
+func Default() *Logger {
+	defaultLoggerMu.RLock()
+	defer defaultLoggerMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault replaces the default logger
+func SetDefault(l *Logger) {
+	defaultLoggerMu.Lock()
+	defer defaultLoggerMu.Unlock()
+	defaultLogger = l
+}
+
 // AddDestination adds a destination to the logger
 func (l *Logger) AddDestination(name string, dest Destination) {
 	l.mu.Lock()
@@ -350,6 +456,27 @@ func (l *Logger) RemoveDestination(name string) {
 	}
 }
 
+// DestinationNames returns the names of the destinations, sorted
+func (l *Logger) DestinationNames() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	names := make([]string, 0, len(l.destinations))
+	for name := range l.destinations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Destination returns the destination added under name
+func (l *Logger) Destination(name string) (Destination, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	dest, ok := l.destinations[name]
+	return dest, ok
+}
+
 // SetDefaultDestinations sets the default destinations
 func (l *Logger) SetDefaultDestinations(dests ...string) {
 	l.mu.Lock()
@@ -377,40 +504,58 @@ func (l *Logger) Error(msg string, fields map[string]interface{}, dests ...strin
 	l.log(ErrorLevel, msg, fields, dests...)
 }
 
-// Fatal logs a fatal message and terminates the program
+// Fatal logs a fatal message, closes the destinations so buffered entries are delivered and
+// terminates the program
 func (l *Logger) Fatal(msg string, fields map[string]interface{}, dests ...string) {
 	l.log(FatalLevel, msg, fields, dests...)
+	l.Close()
 	os.Exit(1)
 }
 
 // Debug logs a debug message
 func (l *Logger) Debugf(format string, args ...interface{}) {
+	if !l.enabled(DebugLevel) {
+		return
+	}
 	l.log(DebugLevel, fmt.Sprintf(format, args...), nil)
 }
 
 // Info logs an info message
 func (l *Logger) Infof(format string, args ...interface{}) {
+	if !l.enabled(InfoLevel) {
+		return
+	}
 	l.log(InfoLevel, fmt.Sprintf(format, args...), nil)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warnf(format string, args ...interface{}) {
+	if !l.enabled(WarnLevel) {
+		return
+	}
 	l.log(WarnLevel, fmt.Sprintf(format, args...), nil)
 }
 
 // Error logs an error message
 func (l *Logger) Errorf(format string, args ...interface{}) {
+	if !l.enabled(ErrorLevel) {
+		return
+	}
 	l.log(ErrorLevel, fmt.Sprintf(format, args...), nil)
 }
 
-// Fatal logs a fatal message and terminates the program
+// Fatalf logs a fatal message, closes the destinations so buffered entries are delivered and
+// terminates the program
 func (l *Logger) Fatalf(format string, args ...interface{}) {
 	l.log(FatalLevel, fmt.Sprintf(format, args...), nil)
+	l.Close()
 	os.Exit(1)
 }
 
 // Close closes all destinations
 func (l *Logger) Close() {
+	l.levels.stop()
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -2,10 +2,12 @@ package logger
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -56,33 +58,13 @@ func TestLogLevelToZapLevel(t *testing.T) {
 	}
 }
 
-// CustomTestDestination is a test destination that captures log entries
-type CustomTestDestination struct {
-	Entries []LogEntry
-}
-
-func NewTestDestination() *CustomTestDestination {
-	return &CustomTestDestination{
-		Entries: make([]LogEntry, 0),
-	}
-}
-
-func (d *CustomTestDestination) Write(entry LogEntry) error {
-	d.Entries = append(d.Entries, entry)
-	return nil
-}
-
-func (d *CustomTestDestination) Close() error {
-	return nil
-}
-
 // TestLoggerCreation tests the creation of a logger with options
 func TestLoggerCreation(t *testing.T) {
 	// Test default logger
 	logger := New()
-	logger.AddDestination("custom", NewTestDestination())
+	logger.AddDestination("custom", NewRingBufferDestination(0))
 	assert.Equal(t, "", logger.serviceName)
-	assert.Equal(t, InfoLevel, logger.minLevel)
+	assert.Equal(t, InfoLevel, logger.Level())
 	assert.False(t, logger.isProd)
 	assert.Len(t, logger.destinations, 1)
 	assert.Contains(t, logger.destinations, "custom")
@@ -96,7 +78,7 @@ func TestLoggerCreation(t *testing.T) {
 		WithDefaultDestinations("test"),
 	)
 	assert.Equal(t, "test-service", logger.serviceName)
-	assert.Equal(t, InfoLevel, logger.minLevel) // Should be InfoLevel because isProd=true
+	assert.Equal(t, InfoLevel, logger.Level()) // Should be InfoLevel because isProd=true
 	assert.True(t, logger.isProd)
 	assert.Equal(t, []string{"test"}, logger.defaultDests)
 
@@ -106,7 +88,7 @@ func TestLoggerCreation(t *testing.T) {
 
 // TestLoggerLevelFiltering tests that logs below minimum level are filtered
 func TestLoggerLevelFiltering(t *testing.T) {
-	testDest := NewTestDestination()
+	testDest := NewRingBufferDestination(0)
 
 	// Create logger with InfoLevel minimum
 	logger := New(
@@ -122,9 +104,9 @@ func TestLoggerLevelFiltering(t *testing.T) {
 	logger.Warn("Warn message", nil)
 
 	// Verify only Info and Warn were logged
-	assert.Len(t, testDest.Entries, 2)
-	assert.Equal(t, "Info message", testDest.Entries[0].Message)
-	assert.Equal(t, "Warn message", testDest.Entries[1].Message)
+	assert.Len(t, testDest.Entries(), 2)
+	assert.Equal(t, "Info message", testDest.Entries()[0].Message)
+	assert.Equal(t, "Warn message", testDest.Entries()[1].Message)
 
 	// Clean up
 	logger.Close()
@@ -132,7 +114,7 @@ func TestLoggerLevelFiltering(t *testing.T) {
 
 // TestLoggerProductionMode tests that debug logs are filtered in production mode
 func TestLoggerProductionMode(t *testing.T) {
-	testDest := NewTestDestination()
+	testDest := NewRingBufferDestination(0)
 
 	// Create logger in production mode with DebugLevel
 	logger := New(
@@ -148,8 +130,8 @@ func TestLoggerProductionMode(t *testing.T) {
 	logger.Info("Info message", nil)
 
 	// Verify only Info was logged (Debug filtered in production)
-	assert.Len(t, testDest.Entries, 1)
-	assert.Equal(t, "Info message", testDest.Entries[0].Message)
+	assert.Len(t, testDest.Entries(), 1)
+	assert.Equal(t, "Info message", testDest.Entries()[0].Message)
 
 	// Clean up
 	logger.Close()
@@ -157,7 +139,7 @@ func TestLoggerProductionMode(t *testing.T) {
 
 // TestLoggerFields tests that fields are properly included in log entries
 func TestLoggerFields(t *testing.T) {
-	testDest := NewTestDestination()
+	testDest := NewRingBufferDestination(0)
 
 	// Create logger
 	logger := New(
@@ -175,19 +157,81 @@ func TestLoggerFields(t *testing.T) {
 	logger.Info("Test message", fields)
 
 	// Verify fields are included
-	assert.Len(t, testDest.Entries, 1)
-	assert.Equal(t, "Test message", testDest.Entries[0].Message)
-	assert.Equal(t, fields, testDest.Entries[0].Fields)
-	assert.Equal(t, "test-service", testDest.Entries[0].ServiceName)
+	assert.Len(t, testDest.Entries(), 1)
+	assert.Equal(t, "Test message", testDest.Entries()[0].Message)
+	assert.Equal(t, fields, testDest.Entries()[0].Fields)
+	assert.Equal(t, "test-service", testDest.Entries()[0].ServiceName)
 
 	// Clean up
 	logger.Close()
 }
 
+// TestLoggerWithFields tests that child loggers add their fields and share the destinations
+func TestLoggerWithFields(t *testing.T) {
+	testDest := NewRingBufferDestination(0)
+
+	logger := New(WithServiceName("test-service"))
+	logger.AddDestination("test", testDest)
+	logger.SetDefaultDestinations("test")
+
+	request := logger.WithFields(map[string]interface{}{FieldRequestID: "req-1", "route": "/a"})
+	user := request.WithFields(map[string]interface{}{FieldUserID: "user-1"})
+
+	user.Infof("Hello %s", "world")
+	request.Info("Overridden", map[string]interface{}{"route": "/b"})
+	logger.Info("Plain", nil)
+
+	require.Len(t, testDest.Entries(), 3)
+	assert.Equal(t, "Hello world", testDest.Entries()[0].Message)
+	assert.Equal(t, map[string]interface{}{FieldRequestID: "req-1", "route": "/a", FieldUserID: "user-1"}, testDest.Entries()[0].Fields)
+	assert.Equal(t, map[string]interface{}{FieldRequestID: "req-1", "route": "/b"}, testDest.Entries()[1].Fields)
+	assert.Empty(t, testDest.Entries()[2].Fields, "the parent is left unchanged")
+
+	// Destinations added later are shared
+	laterDest := NewRingBufferDestination(0)
+	logger.AddDestination("later", laterDest)
+	logger.SetDefaultDestinations("later")
+	user.Info("Shared", nil)
+	assert.Len(t, laterDest.Entries(), 1)
+
+	logger.Close()
+}
+
+// TestLoggerContext tests carrying a request scoped logger in a context
+func TestLoggerContext(t *testing.T) {
+	testDest := NewRingBufferDestination(0)
+
+	logger := New(WithServiceName("test-service"))
+	logger.AddDestination("test", testDest)
+	logger.SetDefaultDestinations("test")
+
+	assert.Same(t, Default(), FromContext(context.Background()))
+	assert.Same(t, logger, logger.WithContext(context.Background()))
+
+	scoped := logger.WithFields(map[string]interface{}{FieldRequestID: "req-1"})
+	ctx := NewContext(context.Background(), scoped)
+	assert.Same(t, scoped, FromContext(ctx))
+
+	// A component logger picks up the request fields
+	component := New(WithServiceName("component"))
+	component.AddDestination("test", testDest)
+	component.SetDefaultDestinations("test")
+	component.WithContext(ctx).Warnf("Failed")
+
+	require.Len(t, testDest.Entries(), 1)
+	assert.Equal(t, "component", testDest.Entries()[0].ServiceName)
+	assert.Equal(t, "req-1", testDest.Entries()[0].Fields[FieldRequestID])
+
+	previous := Default()
+	SetDefault(logger)
+	defer SetDefault(previous)
+	assert.Same(t, logger, FromContext(context.Background()))
+}
+
 // TestLoggerMultipleDestinations tests logging to multiple destinations
 func TestLoggerMultipleDestinations(t *testing.T) {
-	testDest1 := NewTestDestination()
-	testDest2 := NewTestDestination()
+	testDest1 := NewRingBufferDestination(0)
+	testDest2 := NewRingBufferDestination(0)
 
 	// Create logger with multiple destinations
 	logger := New(
@@ -201,18 +245,18 @@ func TestLoggerMultipleDestinations(t *testing.T) {
 	logger.Info("Test message", nil)
 
 	// Verify it went to both destinations
-	assert.Len(t, testDest1.Entries, 1)
-	assert.Len(t, testDest2.Entries, 1)
-	assert.Equal(t, "Test message", testDest1.Entries[0].Message)
-	assert.Equal(t, "Test message", testDest2.Entries[0].Message)
+	assert.Len(t, testDest1.Entries(), 1)
+	assert.Len(t, testDest2.Entries(), 1)
+	assert.Equal(t, "Test message", testDest1.Entries()[0].Message)
+	assert.Equal(t, "Test message", testDest2.Entries()[0].Message)
 
 	// Test explicit destination
 	logger.Error("Error message", nil, "test1")
 
 	// Verify it only went to test1
-	assert.Len(t, testDest1.Entries, 2)
-	assert.Len(t, testDest2.Entries, 1)
-	assert.Equal(t, "Error message", testDest1.Entries[1].Message)
+	assert.Len(t, testDest1.Entries(), 2)
+	assert.Len(t, testDest2.Entries(), 1)
+	assert.Equal(t, "Error message", testDest1.Entries()[1].Message)
 
 	// Clean up
 	logger.Close()
@@ -273,7 +317,7 @@ func TestLoggerFileDestination(t *testing.T) {
 
 // TestLoggerAddRemoveDestination tests adding and removing destinations
 func TestLoggerAddRemoveDestination(t *testing.T) {
-	testDest := NewTestDestination()
+	testDest := NewRingBufferDestination(0)
 
 	// Create logger
 	logger := New()
@@ -282,14 +326,14 @@ func TestLoggerAddRemoveDestination(t *testing.T) {
 
 	// Log a message
 	logger.Info("Test message", nil)
-	assert.Len(t, testDest.Entries, 1)
+	assert.Len(t, testDest.Entries(), 1)
 
 	// Remove the destination
 	logger.RemoveDestination("test")
 	logger.Info("Another message", nil)
 
 	// The message shouldn't have been logged to the removed destination
-	assert.Len(t, testDest.Entries, 1)
+	assert.Len(t, testDest.Entries(), 1)
 
 	// Clean up
 	logger.Close()
@@ -337,17 +381,43 @@ func TestConsoleDestination(t *testing.T) {
 	assert.Contains(t, output, `"test": true`)
 }
 
-// TestFatalExit tests that Fatal logs cause program exit
-// This test is skipped because it would terminate the test process
+// exitRecorder records in a file whether it was closed, surviving the exit of the process
+type exitRecorder struct {
+	path string
+}
+
+func (d *exitRecorder) Write(entry LogEntry) error {
+	return nil
+}
+
+func (d *exitRecorder) Close() error {
+	return os.WriteFile(d.path, []byte("closed"), 0o600)
+}
+
+// TestFatalExit tests that Fatal closes the destinations and exits. The fatal log runs in a
+// subprocess since it terminates the process.
 func TestFatalExit(t *testing.T) {
-	if os.Getenv("TEST_FATAL_EXIT") == "1" {
+	if path := os.Getenv("TEST_FATAL_EXIT"); path != "" {
 		logger := New()
+		logger.AddDestination("recorder", &exitRecorder{path: path})
 		logger.Fatal("This should exit", nil)
 		// Should not reach here
 		t.Fail()
-	} else {
-		t.Skip("Skipping fatal exit test as it would terminate the process")
+		return
 	}
+
+	path := filepath.Join(t.TempDir(), "closed")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalExit$")
+	cmd.Env = append(os.Environ(), "TEST_FATAL_EXIT="+path)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 1, exitErr.ExitCode())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "destinations were not closed before exiting")
+	assert.Equal(t, "closed", string(content))
 }
 
 // TestWithFileDestinationOption tests the WithFileDestination option
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// otlpScopeName identifies this package as the instrumentation scope of the exported records
+const otlpScopeName = "github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+// OTLPDestination exports entries to an OpenTelemetry collector with OTLP/HTTP, using the JSON
+// encoding. The URL of the config is the logs endpoint, such as http://localhost:4318/v1/logs.
+// The service name of the entries becomes the service.name resource attribute and fields become
+// record attributes.
+type OTLPDestination struct {
+	sender *batchSender
+}
+
+// NewOTLPDestination creates an OTLP destination and starts exporting batches
+func NewOTLPDestination(config BatchConfig) (*OTLPDestination, error) {
+	sender, err := newBatchSender(config, batchEncoder{
+		contentType: "application/json",
+		encode:      encodeOTLP,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPDestination{sender: sender}, nil
+}
+
+// Write implements Destination
+func (o *OTLPDestination) Write(entry LogEntry) error {
+	return o.sender.write(entry)
+}
+
+// Dropped returns the number of entries dropped because the buffer was full or a batch failed
+func (o *OTLPDestination) Dropped() uint64 {
+	return o.sender.dropped.Load()
+}
+
+// Close implements Destination, exporting the pending entries
+func (o *OTLPDestination) Close() error {
+	return o.sender.close()
+}
+
+// The JSON encoding of the OTLP logs export request, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto
+type (
+	otlpRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano         string         `json:"timeUnixNano"`
+		ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+		SeverityNumber       int            `json:"severityNumber"`
+		SeverityText         string         `json:"severityText"`
+		Body                 otlpAnyValue   `json:"body"`
+		Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	// otlpAnyValue sets one of its fields, int64 values are strings in the JSON encoding
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// encodeOTLP groups the entries by service into an export request
+func encodeOTLP(entries []timedEntry) ([]byte, error) {
+	observed := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	var request otlpRequest
+	index := map[string]int{}
+	for _, entry := range entries {
+		i, ok := index[entry.ServiceName]
+		if !ok {
+			i = len(request.ResourceLogs)
+			index[entry.ServiceName] = i
+
+			var attributes []otlpKeyValue
+			if entry.ServiceName != "" {
+				attributes = append(attributes, otlpKeyValue{Key: "service.name", Value: otlpString(entry.ServiceName)})
+			}
+			request.ResourceLogs = append(request.ResourceLogs, otlpResourceLogs{
+				Resource:  otlpResource{Attributes: attributes},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: otlpScopeName}}},
+			})
+		}
+
+		scope := &request.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, otlpLogRecord{
+			TimeUnixNano:         strconv.FormatInt(entry.time.UnixNano(), 10),
+			ObservedTimeUnixNano: observed,
+			SeverityNumber:       otlpSeverity(entry.Level),
+			SeverityText:         entry.Level.String(),
+			Body:                 otlpString(entry.Message),
+			Attributes:           otlpAttributes(entry.Fields),
+		})
+	}
+
+	return json.Marshal(request)
+}
+
+// otlpSeverity maps levels to the first severity number of their OTLP range
+func otlpSeverity(level LogLevel) int {
+	switch level {
+	case DebugLevel:
+		return 5
+	case InfoLevel:
+		return 9
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	case FatalLevel:
+		return 21
+	default:
+		return 0
+	}
+}
+
+// otlpAttributes converts fields to attributes, sorted by key
+func otlpAttributes(fields map[string]interface{}) []otlpKeyValue {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	attributes := make([]otlpKeyValue, 0, len(fields))
+	for k, v := range fields {
+		attributes = append(attributes, otlpKeyValue{Key: k, Value: otlpValue(v)})
+	}
+	sort.Slice(attributes, func(i, j int) bool { return attributes[i].Key < attributes[j].Key })
+	return attributes
+}
+
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+func otlpInt(i int64) otlpAnyValue {
+	s := strconv.FormatInt(i, 10)
+	return otlpAnyValue{IntValue: &s}
+}
+
+// otlpValue converts a field value, values without an OTLP type are sent as strings
+func otlpValue(v interface{}) otlpAnyValue {
+	switch v := v.(type) {
+	case string:
+		return otlpString(v)
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		return otlpInt(int64(v))
+	case int8:
+		return otlpInt(int64(v))
+	case int16:
+		return otlpInt(int64(v))
+	case int32:
+		return otlpInt(int64(v))
+	case int64:
+		return otlpInt(v)
+	case uint8:
+		return otlpInt(int64(v))
+	case uint16:
+		return otlpInt(int64(v))
+	case uint32:
+		return otlpInt(int64(v))
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return otlpInt(int64(v))
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return otlpInt(int64(v))
+		}
+	case float32:
+		f := float64(v)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		// NaN and infinities can't be encoded in JSON
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return otlpAnyValue{DoubleValue: &v}
+		}
+	case error:
+		return otlpString(v.Error())
+	case time.Time:
+		return otlpString(v.Format(time.RFC3339Nano))
+	}
+	return otlpString(fmt.Sprint(v))
+}
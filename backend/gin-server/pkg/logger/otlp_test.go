@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPDestination(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	dest, err := NewOTLPDestination(BatchConfig{URL: server.URL + "/v1/logs", FlushInterval: time.Hour, Gzip: true})
+	require.NoError(t, err)
+
+	before := time.Now()
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "api", Level: WarnLevel, Message: "slow", Fields: map[string]interface{}{
+		"status":  200,
+		"latency": 1.5,
+		"cached":  true,
+		"error":   errors.New("boom"),
+		"user_id": "u1",
+	}}))
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "worker", Level: ErrorLevel, Message: "failed"}))
+	require.NoError(t, dest.Write(LogEntry{ServiceName: "api", Level: InfoLevel, Message: "done"}))
+	require.NoError(t, dest.Close())
+
+	requests := c.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "/v1/logs", requests[0].URL.Path)
+
+	var request otlpRequest
+	require.NoError(t, json.Unmarshal(c.Bodies()[0], &request))
+
+	// Records are grouped by service
+	require.Len(t, request.ResourceLogs, 2)
+	api := request.ResourceLogs[0]
+	assert.Equal(t, []otlpKeyValue{{Key: "service.name", Value: otlpString("api")}}, api.Resource.Attributes)
+	require.Len(t, api.ScopeLogs, 1)
+	assert.Equal(t, otlpScopeName, api.ScopeLogs[0].Scope.Name)
+	require.Len(t, api.ScopeLogs[0].LogRecords, 2)
+	assert.Equal(t, "done", *api.ScopeLogs[0].LogRecords[1].Body.StringValue)
+
+	record := api.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, 13, record.SeverityNumber)
+	assert.Equal(t, LogLevelWarn, record.SeverityText)
+	assert.Equal(t, "slow", *record.Body.StringValue)
+	timestamp, err := strconv.ParseInt(record.TimeUnixNano, 10, 64)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, timestamp, before.UnixNano())
+
+	attributes := map[string]otlpAnyValue{}
+	for _, kv := range record.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "200", *attributes["status"].IntValue)
+	assert.Equal(t, 1.5, *attributes["latency"].DoubleValue)
+	assert.True(t, *attributes["cached"].BoolValue)
+	assert.Equal(t, "boom", *attributes["error"].StringValue)
+	assert.Equal(t, "u1", *attributes["user_id"].StringValue)
+
+	worker := request.ResourceLogs[1]
+	assert.Equal(t, "worker", *worker.Resource.Attributes[0].Value.StringValue)
+	assert.Equal(t, 17, worker.ScopeLogs[0].LogRecords[0].SeverityNumber)
+}
+
+func TestOTLPValue(t *testing.T) {
+	assert.Equal(t, "NaN", *otlpValue(math.NaN()).StringValue, "NaN can't be encoded in JSON")
+	assert.Equal(t, "18446744073709551615", *otlpValue(uint64(math.MaxUint64)).StringValue)
+	assert.Equal(t, "1s", *otlpValue(time.Second).StringValue)
+	assert.Equal(t, "[a b]", *otlpValue([]string{"a", "b"}).StringValue)
+}
@@ -0,0 +1,269 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaskStrategy decides how a sensitive value is masked
+type MaskStrategy int
+
+const (
+	// MaskFull replaces the value with [REDACTED]
+	MaskFull MaskStrategy = iota
+	// MaskPartial keeps a few characters, such as the first letter and domain of an email
+	MaskPartial
+	// MaskHashed replaces the value with a short hash, so occurrences can be correlated
+	MaskHashed
+)
+
+const redactedValue = "[REDACTED]"
+
+// DefaultRedactedKeys are the field keys masked by a Redactor unless configured otherwise
+var DefaultRedactedKeys = []string{"password", "token", "refreshToken", "authorization"}
+
+var (
+	jwtPattern   = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// ParseMaskStrategy parses "full", "partial" or "hashed"
+func ParseMaskStrategy(s string) (MaskStrategy, error) {
+	switch strings.ToLower(s) {
+	case "full":
+		return MaskFull, nil
+	case "partial":
+		return MaskPartial, nil
+	case "hashed":
+		return MaskHashed, nil
+	default:
+		return MaskFull, fmt.Errorf("logger: unknown mask strategy %q", s)
+	}
+}
+
+// Redactor masks sensitive data in entries: the values of configured field keys, and JWTs and
+// emails found in messages and string values. Nested maps and slices of fields are redacted too.
+type Redactor struct {
+	keys        map[string]struct{}
+	strategy    MaskStrategy
+	hashKey     []byte
+	detectJWT   bool
+	detectEmail bool
+}
+
+// RedactOption configures a Redactor
+type RedactOption func(*Redactor)
+
+// WithRedactedKeys replaces the masked field keys. Keys match regardless of case, dashes and
+// underscores, so "refreshToken" matches "refresh_token", and also match the last segment of
+// dotted keys such as "user.password".
+func WithRedactedKeys(keys ...string) RedactOption {
+	return func(r *Redactor) {
+		r.keys = make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			r.keys[normalizeKey(key)] = struct{}{}
+		}
+	}
+}
+
+// WithMaskStrategy sets how values are masked
+func WithMaskStrategy(strategy MaskStrategy) RedactOption {
+	return func(r *Redactor) {
+		r.strategy = strategy
+	}
+}
+
+// WithHashKey keys the hashes of MaskHashed, so they can't be reversed by hashing guesses
+func WithHashKey(key []byte) RedactOption {
+	return func(r *Redactor) {
+		r.hashKey = key
+	}
+}
+
+// WithJWTDetection enables masking values that look like JWTs
+func WithJWTDetection(enabled bool) RedactOption {
+	return func(r *Redactor) {
+		r.detectJWT = enabled
+	}
+}
+
+// WithEmailDetection enables masking values that look like emails
+func WithEmailDetection(enabled bool) RedactOption {
+	return func(r *Redactor) {
+		r.detectEmail = enabled
+	}
+}
+
+// NewRedactor creates a redactor masking DefaultRedactedKeys, JWTs and emails with MaskFull
+func NewRedactor(opts ...RedactOption) *Redactor {
+	r := &Redactor{strategy: MaskFull, detectJWT: true, detectEmail: true}
+	WithRedactedKeys(DefaultRedactedKeys...)(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// normalizeKey lowercases a key and removes dashes and underscores
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// sensitiveKey reports whether the values of key are masked
+func (r *Redactor) sensitiveKey(key string) bool {
+	if _, ok := r.keys[normalizeKey(key)]; ok {
+		return true
+	}
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		_, ok := r.keys[normalizeKey(key[i+1:])]
+		return ok
+	}
+	return false
+}
+
+// Redact returns a copy of the entry with sensitive data masked, the entry itself is unchanged
+// as it is shared by the destinations
+func (r *Redactor) Redact(entry LogEntry) LogEntry {
+	entry.Message = r.RedactString(entry.Message)
+	if len(entry.Fields) > 0 {
+		entry.Fields = r.redactMap(entry.Fields)
+	}
+	return entry
+}
+
+// RedactString masks the JWTs and emails found in s
+func (r *Redactor) RedactString(s string) string {
+	if r.detectJWT && strings.Contains(s, "eyJ") {
+		s = jwtPattern.ReplaceAllStringFunc(s, r.mask)
+	}
+	if r.detectEmail && strings.Contains(s, "@") {
+		s = emailPattern.ReplaceAllStringFunc(s, r.mask)
+	}
+	return s
+}
+
+func (r *Redactor) redactMap(fields map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if r.sensitiveKey(k) {
+			redacted[k] = r.mask(fmt.Sprint(v))
+		} else {
+			redacted[k] = r.redactValue(v)
+		}
+	}
+	return redacted
+}
+
+// redactValue redacts strings, errors and nested maps and slices, other values are kept
+func (r *Redactor) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.RedactString(v)
+	case error:
+		return r.RedactString(v.Error())
+	case map[string]interface{}:
+		return r.redactMap(v)
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.sensitiveKey(k) {
+				redacted[k] = r.mask(s)
+			} else {
+				redacted[k] = r.RedactString(s)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = r.redactValue(item)
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = r.RedactString(s)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// mask masks a value with the strategy of the redactor
+func (r *Redactor) mask(value string) string {
+	switch r.strategy {
+	case MaskPartial:
+		return maskPartial(value)
+	case MaskHashed:
+		return r.hash(value)
+	default:
+		return redactedValue
+	}
+}
+
+// maskPartial keeps the first letter and domain of emails, and the first and last two characters
+// of other values long enough not to give them away
+func maskPartial(value string) string {
+	if at := strings.LastIndexByte(value, '@'); at > 0 && emailPattern.MatchString(value) {
+		return value[:1] + "***" + value[at:]
+	}
+	if len(value) < 12 {
+		return "***"
+	}
+	return value[:2] + "***" + value[len(value)-2:]
+}
+
+// hash returns the first 16 hex digits of the SHA-256 of the value, keyed when a hash key is set
+func (r *Redactor) hash(value string) string {
+	var sum []byte
+	if len(r.hashKey) > 0 {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(value))
+		sum = digest[:]
+	}
+	return "hash:" + hex.EncodeToString(sum)[:16]
+}
+
+// RedactingDestination redacts entries before writing them to another destination, so redaction
+// can be enabled for some destinations only
+type RedactingDestination struct {
+	dest     Destination
+	redactor *Redactor
+}
+
+// NewRedactingDestination wraps dest, a nil redactor uses NewRedactor defaults. Closing the
+// RedactingDestination closes dest.
+func NewRedactingDestination(dest Destination, redactor *Redactor) *RedactingDestination {
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+	return &RedactingDestination{dest: dest, redactor: redactor}
+}
+
+// Write implements Destination
+func (d *RedactingDestination) Write(entry LogEntry) error {
+	return d.dest.Write(d.redactor.Redact(entry))
+}
+
+// Unwrap returns the wrapped destination
+func (d *RedactingDestination) Unwrap() Destination {
+	return d.dest
+}
+
+// Close implements Destination
+func (d *RedactingDestination) Close() error {
+	return d.dest.Close()
+}
@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRingBufferSize = 1000
+
+// RecordedEntry is an entry kept by a RingBufferDestination, with the time it was written and
+// its position among all the entries written to the destination
+type RecordedEntry struct {
+	LogEntry
+	Time     time.Time
+	Sequence uint64
+}
+
+// RingQuery filters the entries of a RingBufferDestination, zero fields match every entry
+type RingQuery struct {
+	// MinLevel keeps the entries of this level and above
+	MinLevel LogLevel
+	// Since and Until bound the time of the entries, inclusive
+	Since time.Time
+	Until time.Time
+	// RequestID keeps the entries of a request, see FieldRequestID
+	RequestID string
+	// Contains keeps the entries whose message or a field value contains it, ignoring case
+	Contains string
+	// AfterSequence keeps the entries written after the one with this sequence
+	AfterSequence uint64
+	// Limit keeps the last matching entries
+	Limit int
+}
+
+// Match reports whether the entry passes the filters of the query, ignoring Limit
+func (q RingQuery) Match(entry RecordedEntry) bool {
+	if entry.Level < q.MinLevel || entry.Sequence <= q.AfterSequence {
+		return false
+	}
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.Time.After(q.Until) {
+		return false
+	}
+	if q.RequestID != "" && entry.Fields[FieldRequestID] != q.RequestID {
+		return false
+	}
+	if q.Contains != "" && !entryContains(entry.LogEntry, strings.ToLower(q.Contains)) {
+		return false
+	}
+	return true
+}
+
+// entryContains reports whether the message or a field value contains the lowercase substring
+func entryContains(entry LogEntry, substring string) bool {
+	if strings.Contains(strings.ToLower(entry.Message), substring) {
+		return true
+	}
+	for _, v := range entry.Fields {
+		if strings.Contains(strings.ToLower(fmt.Sprint(v)), substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// RingBufferDestination keeps the last entries in memory, to query them without access to the
+// log files or to assert on them in tests. Subscribers receive the entries as they are written.
+type RingBufferDestination struct {
+	mu          sync.RWMutex
+	buf         []RecordedEntry
+	next        int
+	full        bool
+	sequence    uint64
+	subscribers map[chan RecordedEntry]struct{}
+	closed      bool
+}
+
+// NewRingBufferDestination creates a destination keeping the last size entries, 1000 when size
+// isn't positive
+func NewRingBufferDestination(size int) *RingBufferDestination {
+	if size <= 0 {
+		size = defaultRingBufferSize
+	}
+	return &RingBufferDestination{
+		buf:         make([]RecordedEntry, size),
+		subscribers: make(map[chan RecordedEntry]struct{}),
+	}
+}
+
+// Write implements Destination, replacing the oldest entry once the buffer is full. Subscribers
+// that aren't keeping up miss the entry.
+func (r *RingBufferDestination) Write(entry LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sequence++
+	recorded := RecordedEntry{LogEntry: entry, Time: time.Now(), Sequence: r.sequence}
+	r.buf[r.next] = recorded
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+
+	for ch := range r.subscribers {
+		select {
+		case ch <- recorded:
+		default:
+		}
+	}
+	return nil
+}
+
+// Entries returns the kept entries, oldest first
+func (r *RingBufferDestination) Entries() []RecordedEntry {
+	return r.Query(RingQuery{})
+}
+
+// Query returns the kept entries matching the query, oldest first
+func (r *RingBufferDestination) Query(q RingQuery) []RecordedEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []RecordedEntry
+	r.each(func(entry RecordedEntry) {
+		if q.Match(entry) {
+			entries = append(entries, entry)
+		}
+	})
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[len(entries)-q.Limit:]
+	}
+	return entries
+}
+
+// each calls fn with the kept entries, oldest first, r.mu must be held
+func (r *RingBufferDestination) each(fn func(RecordedEntry)) {
+	if r.full {
+		for _, entry := range r.buf[r.next:] {
+			fn(entry)
+		}
+	}
+	for _, entry := range r.buf[:r.next] {
+		fn(entry)
+	}
+}
+
+// Len returns the number of kept entries
+func (r *RingBufferDestination) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.full {
+		return len(r.buf)
+	}
+	return r.next
+}
+
+// Reset drops the kept entries, sequences keep increasing
+func (r *RingBufferDestination) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.buf)
+	r.next = 0
+	r.full = false
+}
+
+// Subscribe returns a channel receiving the entries written from now on, buffering up to buffer
+// of them, and a function to cancel the subscription. The channel is closed on cancel or when the
+// destination is closed.
+func (r *RingBufferDestination) Subscribe(buffer int) (<-chan RecordedEntry, func()) {
+	ch := make(chan RecordedEntry, max(buffer, 1))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	r.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if _, ok := r.subscribers[ch]; ok {
+				delete(r.subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+}
+
+// Close implements Destination, ending the subscriptions. The kept entries can still be queried.
+func (r *RingBufferDestination) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	for ch := range r.subscribers {
+		delete(r.subscribers, ch)
+		close(ch)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messages(entries []RecordedEntry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.Message
+	}
+	return result
+}
+
+func TestRingBufferDestination(t *testing.T) {
+	ring := NewRingBufferDestination(3)
+	assert.Empty(t, ring.Entries())
+
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, ring.Write(LogEntry{Level: InfoLevel, Message: fmt.Sprintf("message %d", i)}))
+	}
+
+	// The oldest entries are replaced
+	entries := ring.Entries()
+	assert.Equal(t, []string{"message 3", "message 4", "message 5"}, messages(entries))
+	assert.Equal(t, uint64(3), entries[0].Sequence)
+	assert.Equal(t, 3, ring.Len())
+
+	ring.Reset()
+	assert.Zero(t, ring.Len())
+	require.NoError(t, ring.Write(LogEntry{Message: "after reset"}))
+	assert.Equal(t, uint64(6), ring.Entries()[0].Sequence, "sequences keep increasing")
+}
+
+func TestRingBufferDestinationQuery(t *testing.T) {
+	ring := NewRingBufferDestination(0)
+	log := New(WithMinLevel(DebugLevel))
+	log.AddDestination("memory", ring)
+	log.SetDefaultDestinations("memory")
+
+	log.Debugf("starting")
+	start := time.Now()
+	log.WithFields(map[string]interface{}{FieldRequestID: "r1"}).Info("Reminder created", map[string]interface{}{"title": "Dentist"})
+	log.WithFields(map[string]interface{}{FieldRequestID: "r2"}).Warn("Slow query", nil)
+	log.Error("Export failed", map[string]interface{}{"error": errors.New("disk FULL")})
+	end := time.Now()
+
+	tests := []struct {
+		name     string
+		query    RingQuery
+		expected []string
+	}{
+		{"all", RingQuery{}, []string{"starting", "Reminder created", "Slow query", "Export failed"}},
+		{"level", RingQuery{MinLevel: WarnLevel}, []string{"Slow query", "Export failed"}},
+		{"request id", RingQuery{RequestID: "r1"}, []string{"Reminder created"}},
+		{"message substring", RingQuery{Contains: "SLOW"}, []string{"Slow query"}},
+		{"field substring", RingQuery{Contains: "dentist"}, []string{"Reminder created"}},
+		{"error field substring", RingQuery{Contains: "disk full"}, []string{"Export failed"}},
+		{"time range", RingQuery{Since: start, Until: end, MinLevel: InfoLevel}, []string{"Reminder created", "Slow query", "Export failed"}},
+		{"after sequence", RingQuery{AfterSequence: 2}, []string{"Slow query", "Export failed"}},
+		{"limit keeps the last", RingQuery{Limit: 2}, []string{"Slow query", "Export failed"}},
+		{"no match", RingQuery{Until: start.Add(-time.Hour)}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, messages(ring.Query(tt.query)))
+		})
+	}
+}
+
+func TestRingBufferDestinationSubscribe(t *testing.T) {
+	ring := NewRingBufferDestination(10)
+	require.NoError(t, ring.Write(LogEntry{Message: "before"}))
+
+	entries, cancel := ring.Subscribe(1)
+	require.NoError(t, ring.Write(LogEntry{Message: "first"}))
+	require.NoError(t, ring.Write(LogEntry{Message: "missed, the subscriber isn't keeping up"}))
+
+	entry := <-entries
+	assert.Equal(t, "first", entry.Message)
+	assert.Equal(t, uint64(2), entry.Sequence)
+
+	cancel()
+	cancel()
+	_, ok := <-entries
+	assert.False(t, ok, "the channel is closed on cancel")
+
+	// Closing the destination ends the subscriptions
+	entries, cancel = ring.Subscribe(1)
+	defer cancel()
+	require.NoError(t, ring.Close())
+	_, ok = <-entries
+	assert.False(t, ok)
+
+	entries, _ = ring.Subscribe(1)
+	_, ok = <-entries
+	assert.False(t, ok, "subscriptions to a closed destination end right away")
+	assert.Len(t, ring.Entries(), 3, "entries can still be queried once closed")
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// DestinationsKey is the attribute naming the destinations of a record, as a []string or a comma
+// separated string. Records without it go to the default destinations of the Logger.
+const DestinationsKey = "destinations"
+
+// Handler is a slog.Handler writing records to the destinations of a Logger. Attributes become
+// fields, with the names of groups joined by dots, and request fields carried by the context of
+// a record are added as well.
+type Handler struct {
+	logger *Logger
+	fields map[string]interface{}
+	prefix string
+	dests  []string
+}
+
+// NewHandler creates a Handler writing to l
+func NewHandler(l *Logger) *Handler {
+	return &Handler{logger: l}
+}
+
+// NewSlog creates a slog.Logger writing to l
+func NewSlog(l *Logger) *slog.Logger {
+	return slog.New(NewHandler(l))
+}
+
+// fromSlogLevel maps slog levels to the nearest LogLevel, levels above error stay errors
+// so a slog call never terminates the program
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// Enabled implements slog.Handler, disabled records are dropped before any attribute is built
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.enabled(fromSlogLevel(level))
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	var scoped map[string]interface{}
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		scoped = l.fields
+	}
+
+	fields := make(map[string]interface{}, len(scoped)+len(h.fields)+record.NumAttrs())
+	for k, v := range scoped {
+		fields[k] = v
+	}
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+
+	dests := h.dests
+	record.Attrs(func(attr slog.Attr) bool {
+		if d, ok := destinationsAttr(h.prefix, attr); ok {
+			dests = d
+			return true
+		}
+		addAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	h.logger.log(fromSlogLevel(record.Level), record.Message, fields, dests...)
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	child := *h
+	child.fields = make(map[string]interface{}, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		child.fields[k] = v
+	}
+	for _, attr := range attrs {
+		if d, ok := destinationsAttr(h.prefix, attr); ok {
+			child.dests = d
+			continue
+		}
+		addAttr(child.fields, h.prefix, attr)
+	}
+	return &child
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+// destinationsAttr returns the destinations named by a top level DestinationsKey attribute
+func destinationsAttr(prefix string, attr slog.Attr) ([]string, bool) {
+	if prefix != "" || attr.Key != DestinationsKey {
+		return nil, false
+	}
+
+	switch v := attr.Value.Resolve().Any().(type) {
+	case []string:
+		return v, true
+	case string:
+		return strings.Split(v, ","), true
+	default:
+		return nil, false
+	}
+}
+
+// addAttr adds an attribute to fields, flattening groups into dotted keys
+func addAttr(fields map[string]interface{}, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		// Attributes of a group without a key are inlined
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			addAttr(fields, groupPrefix, member)
+		}
+		return
+	}
+
+	fields[prefix+attr.Key] = attr.Value.Any()
+}
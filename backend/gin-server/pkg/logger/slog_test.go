@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// discardDestination drops every entry, for benchmarks
+type discardDestination struct{}
+
+func (discardDestination) Write(entry LogEntry) error { return nil }
+func (discardDestination) Close() error               { return nil }
+
+func newSlogTestLogger(minLevel LogLevel) (*Logger, *RingBufferDestination, *RingBufferDestination) {
+	main := NewRingBufferDestination(0)
+	audit := NewRingBufferDestination(0)
+
+	l := New(WithServiceName("test-service"), WithMinLevel(minLevel))
+	l.AddDestination("main", main)
+	l.AddDestination("audit", audit)
+	l.SetDefaultDestinations("main")
+
+	return l, main, audit
+}
+
+func TestSlogHandler(t *testing.T) {
+	l, main, _ := newSlogTestLogger(InfoLevel)
+	log := NewSlog(l)
+
+	log.Debug("dropped")
+	log.Info("created", "id", 42, slog.Group("request", "method", "GET", "path", "/a"))
+	log.With("component", "export").WithGroup("job").Warn("slow", "took", time.Second)
+	log.Log(context.Background(), slog.LevelError+4, "beyond error")
+
+	require.Len(t, main.Entries(), 3)
+
+	assert.Equal(t, InfoLevel, main.Entries()[0].Level)
+	assert.Equal(t, "created", main.Entries()[0].Message)
+	assert.Equal(t, "test-service", main.Entries()[0].ServiceName)
+	assert.Equal(t, map[string]interface{}{
+		"id":             int64(42),
+		"request.method": "GET",
+		"request.path":   "/a",
+	}, main.Entries()[0].Fields)
+
+	assert.Equal(t, WarnLevel, main.Entries()[1].Level)
+	assert.Equal(t, map[string]interface{}{
+		"component": "export",
+		"job.took":  time.Second,
+	}, main.Entries()[1].Fields)
+
+	assert.Equal(t, ErrorLevel, main.Entries()[2].Level, "levels above error never exit")
+}
+
+func TestSlogHandlerDestinations(t *testing.T) {
+	l, main, audit := newSlogTestLogger(InfoLevel)
+	log := NewSlog(l)
+
+	log.Info("per call", DestinationsKey, []string{"audit"})
+	log.With(DestinationsKey, "main,audit").Info("both")
+	// Only a top level attribute names destinations
+	log.WithGroup("g").Info("grouped", DestinationsKey, "audit")
+
+	require.Len(t, audit.Entries(), 2)
+	assert.Equal(t, "per call", audit.Entries()[0].Message)
+	assert.Empty(t, audit.Entries()[0].Fields, "the destinations aren't a field")
+	assert.Equal(t, "both", audit.Entries()[1].Message)
+
+	require.Len(t, main.Entries(), 2)
+	assert.Equal(t, "both", main.Entries()[0].Message)
+	assert.Equal(t, "audit", main.Entries()[1].Fields["g.destinations"])
+}
+
+func TestSlogHandlerContextFields(t *testing.T) {
+	l, main, _ := newSlogTestLogger(InfoLevel)
+
+	ctx := NewContext(context.Background(), l.WithFields(map[string]interface{}{FieldRequestID: "req-1"}))
+	NewSlog(l.WithFields(map[string]interface{}{"component": "api"})).InfoContext(ctx, "handled", "status", 200)
+
+	require.Len(t, main.Entries(), 1)
+	assert.Equal(t, map[string]interface{}{
+		FieldRequestID: "req-1",
+		"component":    "api",
+		"status":       int64(200),
+	}, main.Entries()[0].Fields)
+}
+
+// TestSlogHandlerConformance runs the standard library checks for slog handlers
+func TestSlogHandlerConformance(t *testing.T) {
+	var main *RingBufferDestination
+	slogtest.Run(t, func(t *testing.T) slog.Handler {
+		var l *Logger
+		l, main, _ = newSlogTestLogger(DebugLevel)
+		return NewHandler(l)
+	}, func(t *testing.T) map[string]any {
+		if strings.HasSuffix(t.Name(), "/zero-time") {
+			t.Skip("destinations timestamp entries when writing them")
+		}
+		require.Len(t, main.Entries(), 1)
+		return nest(main.Entries()[0].LogEntry)
+	})
+}
+
+// nest turns an entry back into the nested map slogtest expects
+func nest(entry LogEntry) map[string]any {
+	result := map[string]any{
+		slog.TimeKey:    time.Now(),
+		slog.LevelKey:   entry.Level.String(),
+		slog.MessageKey: entry.Message,
+	}
+	for key, value := range entry.Fields {
+		m := result
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := m[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				m[part] = next
+			}
+			m = next
+		}
+		m[parts[len(parts)-1]] = value
+	}
+	return result
+}
+
+func newBenchmarkLogger(minLevel LogLevel) *Logger {
+	l := New(WithMinLevel(minLevel))
+	l.AddDestination("discard", discardDestination{})
+	l.SetDefaultDestinations("discard")
+	return l
+}
+
+// BenchmarkLogger compares the allocations of a log call through each API. Run with
+// go test -bench=Logger -benchmem
+func BenchmarkLogger(b *testing.B) {
+	l := newBenchmarkLogger(InfoLevel)
+	log := NewSlog(l)
+
+	b.Run("Map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info("request handled", map[string]interface{}{"status": 200, "method": "GET", "path": "/a"})
+		}
+	})
+	b.Run("Printf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Infof("request handled status: %d, method: %s, path: %s", 200, "GET", "/a")
+		}
+	})
+	b.Run("Slog", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Info("request handled", "status", 200, "method", "GET", "path", "/a")
+		}
+	})
+	b.Run("SlogAttrs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.LogAttrs(context.Background(), slog.LevelInfo, "request handled",
+				slog.Int("status", 200), slog.String("method", "GET"), slog.String("path", "/a"))
+		}
+	})
+}
+
+// BenchmarkLoggerDisabled measures calls below the minimum level, which should not allocate
+// besides building the arguments
+func BenchmarkLoggerDisabled(b *testing.B) {
+	l := newBenchmarkLogger(WarnLevel)
+	log := NewSlog(l)
+
+	b.Run("Map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug("request handled", map[string]interface{}{"status": 200, "method": "GET", "path": "/a"})
+		}
+	})
+	b.Run("Printf", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debugf("request handled status: %d, method: %s, path: %s", 200, "GET", "/a")
+		}
+	})
+	b.Run("Slog", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			log.Debug("request handled", "status", 200, "method", "GET", "path", "/a")
+		}
+	})
+}
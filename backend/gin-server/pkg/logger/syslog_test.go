@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syslogPattern matches the header of the messages, the PRI, timestamp, hostname, app name and proc id
+var syslogPattern = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) (\S+) (\d+) - `)
+
+func TestSyslogDestinationUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	dest, err := NewSyslogDestination(SyslogConfig{
+		Network:  "udp",
+		Address:  conn.LocalAddr().String(),
+		Facility: FacilityLocal0,
+		Hostname: "web 1",
+	})
+	require.NoError(t, err)
+	defer dest.Close()
+
+	require.NoError(t, dest.Write(LogEntry{
+		ServiceName: "gin-server",
+		Level:       WarnLevel,
+		Message:     "Slow request",
+		Fields:      map[string]interface{}{"status": 200, "path": `/a"]\`, "bad key": 1},
+	}))
+
+	buf := make([]byte, 2048)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+
+	match := syslogPattern.FindStringSubmatch(msg)
+	require.NotNil(t, match, msg)
+	assert.Equal(t, strconv.Itoa(FacilityLocal0*8+4), match[1], "local0 facility, warning severity")
+	_, err = time.Parse(time.RFC3339Nano, match[2])
+	assert.NoError(t, err)
+	assert.Equal(t, "web1", match[3], "spaces aren't allowed in header fields")
+	assert.Equal(t, "gin-server", match[4], "the app name defaults to the service")
+
+	assert.True(t, strings.HasSuffix(msg, `[fields@32473 badkey="1" path="/a\"\]\\" status="200"] Slow request`), msg)
+}
+
+func TestSyslogDestinationTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go readFrames(conn, received)
+		}
+	}()
+
+	dest, err := NewSyslogDestination(SyslogConfig{Network: "tcp", Address: listener.Addr().String(), AppName: "app"})
+	require.NoError(t, err)
+	defer dest.Close()
+
+	require.NoError(t, dest.Write(LogEntry{Level: InfoLevel, Message: "first"}))
+	require.NoError(t, dest.Write(LogEntry{Level: ErrorLevel, Message: "second line\nwith a newline"}))
+
+	for _, expected := range []string{"first", "second line\nwith a newline"} {
+		select {
+		case msg := <-received:
+			match := syslogPattern.FindStringSubmatch(msg)
+			require.NotNil(t, match, msg)
+			assert.Equal(t, "app", match[4])
+			assert.True(t, strings.HasSuffix(msg, " - "+expected), msg)
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
+
+// readFrames reads octet counted messages
+func readFrames(conn net.Conn, received chan<- string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return
+		}
+		received <- string(msg)
+	}
+}
+
+func TestSyslogDestinationConfig(t *testing.T) {
+	_, err := NewSyslogDestination(SyslogConfig{Network: "unix", Address: "/dev/log"})
+	assert.Error(t, err)
+
+	_, err = NewSyslogDestination(SyslogConfig{Network: "udp"})
+	assert.Error(t, err)
+}
@@ -1,6 +1,31 @@
 package memcache
 
-import "time"
+import (
+	"container/list"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultShards is the number of shards of a cache created without one
+	DefaultShards = 16
+	// DefaultCleanupInterval is how often expired entries are removed by default
+	DefaultCleanupInterval = time.Minute
+	// entryOverhead approximates the memory an entry takes besides its key and value
+	entryOverhead = 64
+)
+
+var (
+	// ErrEntryTooLarge is returned when a single entry exceeds the byte bound of its shard
+	ErrEntryTooLarge = errors.New("cache entry exceeds the size limit")
+	// ErrNotCounter is returned when incrementing a key holding something else than a counter
+	ErrNotCounter = errors.New("cache value is not a counter")
+	// ErrCacheFull is returned by a cache rejecting new entries once it is full
+	ErrCacheFull = errors.New("cache is full")
+)
 
 // Cache defines an interface for token storage
 type Cache interface {
@@ -9,73 +34,363 @@ type Cache interface {
 	Delete(key string) error
 }
 
-// InMemoryCache implements Cache interface with a local map
+// Counter is implemented by caches that can increment integers atomically, e.g. for limits
+// shared between instances
+type Counter interface {
+	// Increment adds delta to the counter at key and returns the new value. A missing counter
+	// starts from zero and expires after expiration, an existing one keeps its expiration.
+	Increment(key string, delta int64, expiration time.Duration) (int64, error)
+}
+
+// Config configures an InMemoryCache, zero values fall back to the defaults
+type Config struct {
+	Shards          int           // Rounded up to a power of two
+	MaxEntries      int           // 0 is unbounded
+	MaxBytes        int64         // 0 is unbounded, sizes come from Sizer
+	CleanupInterval time.Duration // How often the janitor removes expired entries, negative disables it
+	RejectWhenFull  bool          // Fail with ErrCacheFull instead of evicting live entries, e.g. for revocation lists
+
+	// Sizer estimates the size of an entry in bytes, strings and byte slices are measured
+	// and other values count as a fixed overhead by default
+	Sizer func(key string, value interface{}) int64
+}
+
+// Stats holds the counters of a cache
+type Stats struct {
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	Evictions   uint64 `json:"evictions"`   // Entries removed to stay within the bounds
+	Expirations uint64 `json:"expirations"` // Entries removed after expiring
+	Entries     int    `json:"entries"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// InMemoryCache implements Cache with sharded maps, each shard guarded by its own mutex and
+// evicting its least recently used entries once full. Bounds are split evenly across shards,
+// so eviction follows recency per shard rather than across the whole cache.
 type InMemoryCache struct {
-	data    map[string]cacheItem
-	cleanup time.Duration
+	shards         []*shard
+	mask           uint32
+	sizer          func(key string, value interface{}) int64
+	rejectWhenFull bool
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	evictions   atomic.Uint64
+	expirations atomic.Uint64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type shard struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List // Front is the most recently used
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
 }
 
 type cacheItem struct {
+	key        string
 	value      interface{}
+	size       int64
 	expiration time.Time
 }
 
-// NewInMemoryCache creates a new in-memory cache with auto cleanup
+// NewInMemoryCache creates a new unbounded in-memory cache with auto cleanup
 func NewInMemoryCache(cleanupInterval time.Duration) *InMemoryCache {
-	cache := &InMemoryCache{
-		data:    make(map[string]cacheItem),
-		cleanup: cleanupInterval,
+	return New(Config{CleanupInterval: cleanupInterval})
+}
+
+// New creates an in-memory cache. Close stops its cleanup goroutine.
+func New(config Config) *InMemoryCache {
+	shards := DefaultShards
+	if config.Shards > 0 {
+		shards = 1
+		for shards < config.Shards {
+			shards <<= 1
+		}
+	}
+	if config.CleanupInterval == 0 {
+		config.CleanupInterval = DefaultCleanupInterval
+	}
+	if config.Sizer == nil {
+		config.Sizer = defaultSizer
+	}
+
+	c := &InMemoryCache{
+		shards:         make([]*shard, shards),
+		mask:           uint32(shards - 1),
+		sizer:          config.Sizer,
+		rejectWhenFull: config.RejectWhenFull,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i] = &shard{
+			items:      make(map[string]*list.Element),
+			lru:        list.New(),
+			maxEntries: perShard(config.MaxEntries, shards),
+			maxBytes:   int64(perShard(int(config.MaxBytes), shards)),
+		}
 	}
 
-	go cache.startCleanupTimer()
+	if config.CleanupInterval > 0 {
+		go c.startCleanupTimer(config.CleanupInterval)
+	} else {
+		close(c.done)
+	}
+
+	return c
+}
 
-	return cache
+// perShard splits a bound across shards, rounding up so small bounds still allow an entry per shard
+func perShard(limit, shards int) int {
+	if limit <= 0 {
+		return 0
+	}
+	return (limit + shards - 1) / shards
 }
 
-func (c *InMemoryCache) startCleanupTimer() {
-	ticker := time.NewTicker(c.cleanup)
+func (c *InMemoryCache) startCleanupTimer(interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.deleteExpired()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.deleteExpired()
+		}
 	}
 }
 
 func (c *InMemoryCache) deleteExpired() {
 	now := time.Now()
-	for key, item := range c.data {
-		if now.After(item.expiration) {
-			delete(c.data, key)
-		}
+	for _, s := range c.shards {
+		s.mu.Lock()
+		removed := s.removeExpired(now)
+		s.mu.Unlock()
+		c.expirations.Add(uint64(removed))
 	}
 }
 
-// Set adds a value to the cache with an expiration
+// Set adds a value to the cache with an expiration. A non-positive expiration removes the key.
 func (c *InMemoryCache) Set(key string, value interface{}, expiration time.Duration) error {
-	c.data[key] = cacheItem{
+	s := c.shardFor(key)
+	if expiration <= 0 {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if element, exists := s.items[key]; exists {
+			s.remove(element)
+		}
+		return nil
+	}
+
+	item := &cacheItem{
+		key:        key,
 		value:      value,
+		size:       c.sizer(key, value),
 		expiration: time.Now().Add(expiration),
 	}
+	if s.maxBytes > 0 && item.size > s.maxBytes {
+		return ErrEntryTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, exists := s.items[key]; exists {
+		s.remove(element)
+	}
+	return c.insert(s, item, time.Now())
+}
+
+// Increment adds delta to the counter at key, counters are stored as int64 values
+func (c *InMemoryCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if element, exists := s.items[key]; exists {
+		item := element.Value.(*cacheItem)
+		if !now.After(item.expiration) {
+			value, ok := item.value.(int64)
+			if !ok {
+				return 0, ErrNotCounter
+			}
+			item.value = value + delta
+			s.lru.MoveToFront(element)
+			return value + delta, nil
+		}
+		s.remove(element)
+		c.expirations.Add(1)
+	}
+
+	err := c.insert(s, &cacheItem{
+		key:        key,
+		value:      delta,
+		size:       c.sizer(key, delta),
+		expiration: now.Add(expiration),
+	}, now)
+	if err != nil {
+		return 0, err
+	}
+	return delta, nil
+}
+
+// insert adds an item to the shard and evicts entries until it fits, or fails when the cache
+// rejects entries and only expired ones could make room. The caller holds the lock.
+func (c *InMemoryCache) insert(s *shard, item *cacheItem, now time.Time) error {
+	if c.rejectWhenFull && s.overflows(item) {
+		c.expirations.Add(uint64(s.removeExpired(now)))
+		if s.overflows(item) {
+			return ErrCacheFull
+		}
+	}
+
+	s.items[item.key] = s.lru.PushFront(item)
+	s.bytes += item.size
+
+	// The least recently used entries make room, ones that already expired are not counted as evictions
+	for s.full() {
+		oldest := s.lru.Back()
+		if now.After(oldest.Value.(*cacheItem).expiration) {
+			c.expirations.Add(1)
+		} else {
+			c.evictions.Add(1)
+		}
+		s.remove(oldest)
+	}
 	return nil
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache, marking it as recently used
 func (c *InMemoryCache) Get(key string) (interface{}, bool) {
-	item, exists := c.data[key]
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, exists := s.items[key]
 	if !exists {
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	item := element.Value.(*cacheItem)
 	if time.Now().After(item.expiration) {
-		delete(c.data, key)
+		s.remove(element)
+		c.expirations.Add(1)
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	s.lru.MoveToFront(element)
+	c.hits.Add(1)
 	return item.value, true
 }
 
 // Delete removes a value from the cache
 func (c *InMemoryCache) Delete(key string) error {
-	delete(c.data, key)
+	s := c.shardFor(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, exists := s.items[key]; exists {
+		s.remove(element)
+	}
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not cleaned up yet
+func (c *InMemoryCache) Len() int {
+	total := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		total += len(s.items)
+		s.mu.Unlock()
+	}
+	return total
+}
+
+// Stats returns the counters and the current size of the cache
+func (c *InMemoryCache) Stats() Stats {
+	stats := Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+	}
+	for _, s := range c.shards {
+		s.mu.Lock()
+		stats.Entries += len(s.items)
+		stats.Bytes += s.bytes
+		s.mu.Unlock()
+	}
+	return stats
+}
+
+// Close stops the cleanup goroutine, it is safe to call more than once.
+// The cache stays usable, expired entries are then only removed when read.
+func (c *InMemoryCache) Close() error {
+	c.closeOnce.Do(func() { close(c.stop) })
+	<-c.done
 	return nil
 }
+
+func (c *InMemoryCache) shardFor(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()&c.mask]
+}
+
+// full reports whether the shard is over one of its bounds, the caller holds the lock
+func (s *shard) full() bool {
+	return (s.maxEntries > 0 && len(s.items) > s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes > s.maxBytes)
+}
+
+// overflows reports whether adding item would put the shard over one of its bounds, the caller holds the lock
+func (s *shard) overflows(item *cacheItem) bool {
+	return (s.maxEntries > 0 && len(s.items) >= s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes+item.size > s.maxBytes)
+}
+
+// removeExpired removes the expired entries of the shard, the caller holds the lock
+func (s *shard) removeExpired(now time.Time) int {
+	removed := 0
+	for element := s.lru.Back(); element != nil; {
+		prev := element.Prev()
+		if now.After(element.Value.(*cacheItem).expiration) {
+			s.remove(element)
+			removed++
+		}
+		element = prev
+	}
+	return removed
+}
+
+// remove deletes an entry, the caller holds the lock
+func (s *shard) remove(element *list.Element) {
+	item := s.lru.Remove(element).(*cacheItem)
+	delete(s.items, item.key)
+	s.bytes -= item.size
+}
+
+func defaultSizer(key string, value interface{}) int64 {
+	size := int64(len(key) + entryOverhead)
+	switch v := value.(type) {
+	case string:
+		size += int64(len(v))
+	case []byte:
+		size += int64(len(v))
+	}
+	return size
+}
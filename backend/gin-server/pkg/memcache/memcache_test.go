@@ -1,6 +1,8 @@
 package memcache
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -42,3 +44,181 @@ func TestInMemoryCache(t *testing.T) {
 	_, exists = cache.Get("key3")
 	assert.False(t, exists)
 }
+
+func TestIncrement(t *testing.T) {
+	cache := New(Config{CleanupInterval: -1})
+	defer cache.Close()
+
+	var _ Counter = cache
+
+	value, err := cache.Increment("counter", 1, 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	value, err = cache.Increment("counter", 2, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+
+	// The first expiration is kept
+	time.Sleep(40 * time.Millisecond)
+	value, err = cache.Increment("counter", 1, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	assert.NoError(t, cache.Set("text", "value", time.Minute))
+	_, err = cache.Increment("text", 1, time.Minute)
+	assert.ErrorIs(t, err, ErrNotCounter)
+}
+
+func TestLRUEviction(t *testing.T) {
+	cache := New(Config{Shards: 1, MaxEntries: 2})
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", 1, time.Minute))
+	assert.NoError(t, cache.Set("b", 2, time.Minute))
+
+	// Reading "a" makes "b" the least recently used
+	_, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.NoError(t, cache.Set("c", 3, time.Minute))
+
+	_, exists = cache.Get("b")
+	assert.False(t, exists)
+	_, exists = cache.Get("a")
+	assert.True(t, exists)
+	_, exists = cache.Get("c")
+	assert.True(t, exists)
+
+	// Overwriting a key doesn't evict anything
+	assert.NoError(t, cache.Set("c", 4, time.Minute))
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, uint64(1), cache.Stats().Evictions)
+}
+
+func TestMaxBytes(t *testing.T) {
+	sizer := func(key string, value interface{}) int64 { return int64(len(value.(string))) }
+	cache := New(Config{Shards: 1, MaxBytes: 10, Sizer: sizer})
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", "12345", time.Minute))
+	assert.NoError(t, cache.Set("b", "12345", time.Minute))
+	assert.NoError(t, cache.Set("c", "123", time.Minute))
+
+	_, exists := cache.Get("a")
+	assert.False(t, exists, "the oldest entry makes room")
+	assert.Equal(t, int64(8), cache.Stats().Bytes)
+
+	assert.ErrorIs(t, cache.Set("d", "12345678901", time.Minute), ErrEntryTooLarge)
+}
+
+func TestRejectWhenFull(t *testing.T) {
+	cache := New(Config{Shards: 1, MaxEntries: 2, RejectWhenFull: true, CleanupInterval: -1})
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", 1, time.Minute))
+	assert.NoError(t, cache.Set("b", 2, 10*time.Millisecond))
+
+	// Live entries are kept, the new one is rejected
+	assert.ErrorIs(t, cache.Set("c", 3, time.Minute), ErrCacheFull)
+	_, err := cache.Increment("d", 1, time.Minute)
+	assert.ErrorIs(t, err, ErrCacheFull)
+	assert.NoError(t, cache.Set("a", 4, time.Minute), "overwriting a key still fits")
+
+	// Expired entries make room
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, cache.Set("c", 3, time.Minute))
+
+	_, exists := cache.Get("a")
+	assert.True(t, exists)
+	assert.Zero(t, cache.Stats().Evictions)
+}
+
+func TestSetWithoutExpirationRemovesKey(t *testing.T) {
+	cache := New(Config{})
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", 1, time.Minute))
+	assert.NoError(t, cache.Set("a", 1, 0))
+
+	_, exists := cache.Get("a")
+	assert.False(t, exists)
+	assert.Zero(t, cache.Len())
+}
+
+func TestStats(t *testing.T) {
+	cache := New(Config{})
+	defer cache.Close()
+
+	assert.NoError(t, cache.Set("a", "value", time.Minute))
+	assert.NoError(t, cache.Set("b", "value", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+	cache.Get("missing")
+
+	stats := cache.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Expirations)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, int64(len("a")+len("value")+entryOverhead), stats.Bytes)
+}
+
+func TestJanitorAndClose(t *testing.T) {
+	cache := New(Config{CleanupInterval: 5 * time.Millisecond})
+
+	assert.NoError(t, cache.Set("a", 1, time.Millisecond))
+	assert.Eventually(t, func() bool { return cache.Len() == 0 }, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, cache.Close())
+	assert.NoError(t, cache.Close(), "closing twice is fine")
+
+	select {
+	case <-cache.done:
+	default:
+		t.Fatal("the janitor is still running")
+	}
+
+	// The cache keeps working without its janitor
+	assert.NoError(t, cache.Set("b", 2, time.Minute))
+	value, exists := cache.Get("b")
+	assert.True(t, exists)
+	assert.Equal(t, 2, value)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	cache := New(Config{MaxEntries: 500, CleanupInterval: time.Millisecond})
+	defer cache.Close()
+
+	const workers = 32
+	const operations = 2000
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < operations; i++ {
+				key := strconv.Itoa((w*operations + i) % 1000)
+				switch i % 4 {
+				case 0, 1:
+					_ = cache.Set(key, i, time.Duration(i%5+1)*time.Millisecond)
+				case 2:
+					cache.Get(key)
+				case 3:
+					_ = cache.Delete(key)
+				}
+				if i%500 == 0 {
+					cache.Stats()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.LessOrEqual(t, stats.Entries, 500+DefaultShards, "bounds are rounded up per shard")
+	assert.Equal(t, uint64(workers*operations/4), stats.Hits+stats.Misses)
+}
@@ -0,0 +1,407 @@
+package memcache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRedisPoolSize is the number of connections a RedisCache opens at most by default
+	DefaultRedisPoolSize = 10
+	// DefaultRedisTimeout bounds dialing and every command by default
+	DefaultRedisTimeout = 3 * time.Second
+)
+
+var (
+	// ErrCacheClosed is returned when using a cache after Close
+	ErrCacheClosed = errors.New("cache is closed")
+	// ErrPoolTimeout is returned when every connection stayed busy for the whole timeout
+	ErrPoolTimeout = errors.New("redis: timed out waiting for a connection")
+	// errNilReply is the null bulk string Redis returns for missing keys
+	errNilReply = errors.New("redis: nil reply")
+)
+
+// RedisError is an error reply sent by the server
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// Codec serializes cached values
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// GobCodec encodes values with encoding/gob, keeping their types across the round trip.
+// Basic types work as is, other types need gob.Register and exported fields.
+type GobCodec struct{}
+
+func (GobCodec) Marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) Unmarshal(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// RedisConfig configures a RedisCache, zero values fall back to the defaults
+type RedisConfig struct {
+	Addr      string // host:port
+	Password  string
+	DB        int
+	KeyPrefix string // Prepended to every key, to share a server between applications
+	PoolSize  int    // Connections opened at most, callers wait for a free one beyond that
+	Timeout   time.Duration
+	Codec     Codec
+
+	// OnError is called with failures Get can't return, such as a lost connection
+	OnError func(op string, err error)
+}
+
+// RedisCache implements Cache on a server speaking the Redis protocol, so replicas share state.
+// Entries expire on the server, through SET with PX.
+type RedisCache struct {
+	config RedisConfig
+
+	// slots holds a token per connection that may be opened, idle holds open connections
+	slots chan struct{}
+	idle  chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// NewRedisCache creates a cache on the server at config.Addr. Connections are opened on first use.
+func NewRedisCache(config RedisConfig) *RedisCache {
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultRedisPoolSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultRedisTimeout
+	}
+	if config.Codec == nil {
+		config.Codec = GobCodec{}
+	}
+
+	c := &RedisCache{
+		config: config,
+		slots:  make(chan struct{}, config.PoolSize),
+		idle:   make(chan *redisConn, config.PoolSize),
+	}
+	for i := 0; i < config.PoolSize; i++ {
+		c.slots <- struct{}{}
+	}
+	return c
+}
+
+// Set stores a value with an expiration. A non-positive expiration removes the key.
+func (c *RedisCache) Set(key string, value interface{}, expiration time.Duration) error {
+	if expiration <= 0 {
+		return c.Delete(key)
+	}
+
+	data, err := c.config.Codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding cache value: %w", err)
+	}
+
+	// Sub-millisecond expirations would be sent as 0, which the server rejects
+	milliseconds := expiration.Milliseconds()
+	if milliseconds < 1 {
+		milliseconds = 1
+	}
+
+	_, err = c.do("SET", c.config.KeyPrefix+key, string(data), "PX", strconv.FormatInt(milliseconds, 10))
+	return err
+}
+
+// Get retrieves a value. Failures are reported to OnError and read as a miss.
+func (c *RedisCache) Get(key string) (interface{}, bool) {
+	reply, err := c.do("GET", c.config.KeyPrefix+key)
+	if errors.Is(err, errNilReply) {
+		return nil, false
+	}
+	if err != nil {
+		c.reportError("get", err)
+		return nil, false
+	}
+
+	data, ok := reply.([]byte)
+	if !ok {
+		c.reportError("get", fmt.Errorf("unexpected reply %T", reply))
+		return nil, false
+	}
+
+	value, err := c.config.Codec.Unmarshal(data)
+	if err != nil {
+		c.reportError("get", fmt.Errorf("decoding cache value: %w", err))
+		return nil, false
+	}
+	return value, true
+}
+
+// Delete removes a value
+func (c *RedisCache) Delete(key string) error {
+	_, err := c.do("DEL", c.config.KeyPrefix+key)
+	return err
+}
+
+// Increment adds delta to the counter at key. Counters hold plain integers rather than
+// encoded values, so they can't be read with Get.
+func (c *RedisCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	key = c.config.KeyPrefix + key
+
+	// Creating the counter first gives it an expiration, INCRBY keeps it
+	milliseconds := expiration.Milliseconds()
+	if milliseconds < 1 {
+		milliseconds = 1
+	}
+	_, err := c.do("SET", key, "0", "PX", strconv.FormatInt(milliseconds, 10), "NX")
+	if err != nil && !errors.Is(err, errNilReply) {
+		return 0, err
+	}
+
+	reply, err := c.do("INCRBY", key, strconv.FormatInt(delta, 10))
+	if err != nil {
+		return 0, err
+	}
+	value, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply %T", reply)
+	}
+	return value, nil
+}
+
+// Ping checks that the server is reachable
+func (c *RedisCache) Ping() error {
+	_, err := c.do("PING")
+	return err
+}
+
+// Close closes the idle connections, connections in use are closed when released
+func (c *RedisCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// Do sends a command and returns its reply, a string, int64, []byte, nil or []interface{}
+func (c *RedisCache) Do(args ...string) (interface{}, error) {
+	reply, err := c.do(args...)
+	if errors.Is(err, errNilReply) {
+		return nil, nil
+	}
+	return reply, err
+}
+
+func (c *RedisCache) do(args ...string) (interface{}, error) {
+	conn, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.command(c.config.Timeout, args...)
+
+	// Error replies leave the connection usable, anything else may have left it mid-reply
+	var redisErr RedisError
+	healthy := err == nil || errors.Is(err, errNilReply) || errors.As(err, &redisErr)
+	c.release(conn, healthy)
+
+	return reply, err
+}
+
+// acquire returns an idle connection, or dials one when the pool isn't full
+func (c *RedisCache) acquire() (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	timer := time.NewTimer(c.config.Timeout)
+	defer timer.Stop()
+
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	case <-c.slots:
+	case <-timer.C:
+		return nil, ErrPoolTimeout
+	}
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		c.slots <- struct{}{}
+		return nil, ErrCacheClosed
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		c.slots <- struct{}{}
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *RedisCache) release(conn *redisConn, healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !healthy || c.closed {
+		conn.conn.Close()
+		c.slots <- struct{}{}
+		return
+	}
+	c.idle <- conn
+}
+
+func (c *RedisCache) dial() (*redisConn, error) {
+	netConn, err := net.DialTimeout("tcp", c.config.Addr, c.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &redisConn{conn: netConn, r: bufio.NewReader(netConn), w: bufio.NewWriter(netConn)}
+
+	if c.config.Password != "" {
+		if _, err := conn.command(c.config.Timeout, "AUTH", c.config.Password); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+	if c.config.DB != 0 {
+		if _, err := conn.command(c.config.Timeout, "SELECT", strconv.Itoa(c.config.DB)); err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *RedisCache) reportError(op string, err error) {
+	if c.config.OnError != nil {
+		c.config.OnError(op, err)
+	}
+}
+
+// command writes a command as an array of bulk strings and reads the reply
+func (c *redisConn) command(timeout time.Duration, args ...string) (interface{}, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return ReadReply(c.r)
+}
+
+// ReadReply reads a reply in the Redis serialization protocol. Simple strings are returned as
+// string, integers as int64, bulk strings as []byte and arrays as []interface{}. Error replies
+// are returned as a RedisError.
+func ReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if length < 0 {
+			return nil, errNilReply
+		}
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:length], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length: %w", err)
+		}
+		if count < 0 {
+			return nil, errNilReply
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := ReadReply(r)
+			if err != nil && !errors.Is(err, errNilReply) {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("redis: malformed line")
+	}
+	return line[:len(line)-2], nil
+}
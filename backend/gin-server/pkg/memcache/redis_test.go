@@ -0,0 +1,175 @@
+package memcache_test
+
+import (
+	"encoding/gob"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache/redistest"
+)
+
+type session struct {
+	UserID string
+	Scopes []string
+}
+
+func init() {
+	gob.Register(session{})
+}
+
+func setupRedis(t *testing.T, config memcache.RedisConfig) (*memcache.RedisCache, *redistest.Server) {
+	server, err := redistest.NewServer(config.Password)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	config.Addr = server.Addr()
+	cache := memcache.NewRedisCache(config)
+	t.Cleanup(func() { cache.Close() })
+
+	return cache, server
+}
+
+func TestRedisCache(t *testing.T) {
+	cache, _ := setupRedis(t, memcache.RedisConfig{Password: "secret", DB: 2})
+	require.NoError(t, cache.Ping())
+
+	// Values keep their type through the codec
+	require.NoError(t, cache.Set("flag", true, time.Minute))
+	require.NoError(t, cache.Set("session", session{UserID: "alice", Scopes: []string{"read"}}, time.Minute))
+
+	value, exists := cache.Get("flag")
+	assert.True(t, exists)
+	assert.Equal(t, true, value)
+
+	value, exists = cache.Get("session")
+	assert.True(t, exists)
+	assert.Equal(t, session{UserID: "alice", Scopes: []string{"read"}}, value)
+
+	_, exists = cache.Get("missing")
+	assert.False(t, exists)
+
+	require.NoError(t, cache.Delete("flag"))
+	_, exists = cache.Get("flag")
+	assert.False(t, exists)
+}
+
+func TestRedisCacheExpiration(t *testing.T) {
+	cache, _ := setupRedis(t, memcache.RedisConfig{})
+
+	require.NoError(t, cache.Set("short", "value", 20*time.Millisecond))
+	_, exists := cache.Get("short")
+	assert.True(t, exists)
+
+	time.Sleep(40 * time.Millisecond)
+	_, exists = cache.Get("short")
+	assert.False(t, exists)
+
+	require.NoError(t, cache.Set("short", "value", time.Minute))
+	require.NoError(t, cache.Set("short", "value", 0))
+	_, exists = cache.Get("short")
+	assert.False(t, exists, "a non-positive expiration removes the key")
+}
+
+func TestRedisCacheIncrement(t *testing.T) {
+	cache, _ := setupRedis(t, memcache.RedisConfig{KeyPrefix: "app:"})
+
+	var _ memcache.Counter = cache
+
+	value, err := cache.Increment("counter", 1, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	value, err = cache.Increment("counter", 4, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	ttl, err := cache.Do("PTTL", "app:counter")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, ttl, time.Minute.Milliseconds(), "the first expiration is kept")
+	assert.Greater(t, ttl, int64(0))
+
+	require.NoError(t, cache.Set("text", "value", time.Minute))
+	_, err = cache.Increment("text", 1, time.Minute)
+	var redisErr memcache.RedisError
+	assert.ErrorAs(t, err, &redisErr)
+}
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	cache, server := setupRedis(t, memcache.RedisConfig{KeyPrefix: "app:"})
+
+	require.NoError(t, cache.Set("key", "value", time.Minute))
+
+	other := memcache.NewRedisCache(memcache.RedisConfig{Addr: server.Addr()})
+	defer other.Close()
+
+	exists, err := other.Do("EXISTS", "app:key")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), exists)
+
+	_, found := other.Get("key")
+	assert.False(t, found)
+}
+
+func TestRedisCacheErrors(t *testing.T) {
+	var mu sync.Mutex
+	var reported []string
+
+	cache, server := setupRedis(t, memcache.RedisConfig{
+		Password: "secret",
+		Timeout:  200 * time.Millisecond,
+		OnError: func(op string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, op)
+		},
+	})
+
+	wrongPassword := memcache.NewRedisCache(memcache.RedisConfig{Addr: server.Addr(), Password: "wrong"})
+	defer wrongPassword.Close()
+	var redisErr memcache.RedisError
+	assert.ErrorAs(t, wrongPassword.Ping(), &redisErr)
+
+	// Losing the server turns reads into misses and reports them
+	require.NoError(t, cache.Set("key", "value", time.Minute))
+	server.Close()
+
+	_, exists := cache.Get("key")
+	assert.False(t, exists)
+	assert.Error(t, cache.Set("key", "value", time.Minute))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"get"}, reported)
+
+	cache.Close()
+	assert.ErrorIs(t, cache.Ping(), memcache.ErrCacheClosed)
+}
+
+func TestRedisCacheConnectionPool(t *testing.T) {
+	cache, server := setupRedis(t, memcache.RedisConfig{PoolSize: 4})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				key := strconv.Itoa(w*50 + i)
+				assert.NoError(t, cache.Set(key, i, time.Minute))
+				value, exists := cache.Get(key)
+				assert.True(t, exists)
+				assert.Equal(t, i, value)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, server.Connections(), 4)
+	assert.Equal(t, 16*50*2, server.Commands())
+}
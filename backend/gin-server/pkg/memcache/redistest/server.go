@@ -0,0 +1,316 @@
+// Package redistest provides a small in-process server speaking the Redis protocol for tests.
+// It implements PING, AUTH, SELECT, GET, SET with EX, PX and NX, DEL, EXISTS, INCRBY, PTTL and FLUSHALL,
+// with expiration, which is what memcache.RedisCache relies on.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+)
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time // Zero when the key doesn't expire
+}
+
+// Server is a fake Redis server listening on a local port
+type Server struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	data     map[string]entry
+	conns    map[net.Conn]struct{}
+	commands int
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server, clients must AUTH with the password unless it is empty
+func NewServer(password string) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: listener,
+		password: password,
+		data:     make(map[string]entry),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns the host:port the server listens on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Commands returns the number of commands handled so far
+func (s *Server) Commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commands
+}
+
+// Connections returns the number of open client connections
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// Close stops the server and drops the client connections
+func (s *Server) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.listener.Close()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	authenticated := s.password == ""
+
+	for {
+		request, err := memcache.ReadReply(r)
+		if err != nil {
+			return
+		}
+
+		items, ok := request.([]interface{})
+		if !ok || len(items) == 0 {
+			writeError(w, "ERR protocol error")
+			w.Flush()
+			continue
+		}
+
+		args := make([]string, len(items))
+		for i, item := range items {
+			data, _ := item.([]byte)
+			args[i] = string(data)
+		}
+
+		command := strings.ToUpper(args[0])
+		switch {
+		case command == "AUTH":
+			if len(args) == 2 && args[1] == s.password {
+				authenticated = true
+				writeSimple(w, "OK")
+			} else {
+				writeError(w, "WRONGPASS invalid password")
+			}
+		case !authenticated:
+			writeError(w, "NOAUTH Authentication required.")
+		default:
+			s.execute(w, command, args[1:])
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) execute(w *bufio.Writer, command string, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands++
+	now := time.Now()
+
+	switch command {
+	case "PING":
+		writeSimple(w, "PONG")
+	case "SELECT":
+		writeSimple(w, "OK")
+	case "FLUSHALL":
+		s.data = make(map[string]entry)
+		writeSimple(w, "OK")
+	case "GET":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'get' command")
+			return
+		}
+		e, ok := s.lookup(args[0], now)
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		writeBulk(w, e.value)
+	case "SET":
+		s.set(w, args, now)
+	case "DEL", "EXISTS":
+		count := 0
+		for _, key := range args {
+			if _, ok := s.lookup(key, now); ok {
+				count++
+				if command == "DEL" {
+					delete(s.data, key)
+				}
+			}
+		}
+		writeInt(w, int64(count))
+	case "INCRBY":
+		if len(args) != 2 {
+			writeError(w, "ERR wrong number of arguments for 'incrby' command")
+			return
+		}
+		delta, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		e, _ := s.lookup(args[0], now)
+		value := int64(0)
+		if e.value != nil {
+			if value, err = strconv.ParseInt(string(e.value), 10, 64); err != nil {
+				writeError(w, "ERR value is not an integer or out of range")
+				return
+			}
+		}
+		value += delta
+		e.value = []byte(strconv.FormatInt(value, 10))
+		s.data[args[0]] = e
+		writeInt(w, value)
+	case "PTTL":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'pttl' command")
+			return
+		}
+		e, ok := s.lookup(args[0], now)
+		switch {
+		case !ok:
+			writeInt(w, -2)
+		case e.expiresAt.IsZero():
+			writeInt(w, -1)
+		default:
+			writeInt(w, e.expiresAt.Sub(now).Milliseconds())
+		}
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", command))
+	}
+}
+
+func (s *Server) set(w *bufio.Writer, args []string, now time.Time) {
+	if len(args) < 2 {
+		writeError(w, "ERR wrong number of arguments for 'set' command")
+		return
+	}
+
+	e := entry{value: []byte(args[1])}
+	onlyIfMissing := false
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); option {
+		case "NX":
+			onlyIfMissing = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			amount, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || amount <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Millisecond
+			if option == "EX" {
+				unit = time.Second
+			}
+			e.expiresAt = now.Add(time.Duration(amount) * unit)
+			i++
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+
+	if _, exists := s.lookup(args[0], now); exists && onlyIfMissing {
+		w.WriteString("$-1\r\n")
+		return
+	}
+
+	s.data[args[0]] = e
+	writeSimple(w, "OK")
+}
+
+// lookup returns a live entry, removing it once expired. The caller holds the lock.
+func (s *Server) lookup(key string, now time.Time) (entry, bool) {
+	e, ok := s.data[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+		delete(s.data, key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+func writeSimple(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "+%s\r\n", value)
+}
+
+func writeError(w *bufio.Writer, message string) {
+	fmt.Fprintf(w, "-%s\r\n", message)
+}
+
+func writeInt(w *bufio.Writer, value int64) {
+	fmt.Fprintf(w, ":%d\r\n", value)
+}
+
+func writeBulk(w *bufio.Writer, value []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(value))
+	w.Write(value)
+	w.WriteString("\r\n")
+}
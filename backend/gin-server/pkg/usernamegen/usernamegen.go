@@ -1,13 +1,53 @@
+// Package usernamegen generates readable usernames like "brave-panther-4821" from word lists.
+//
+// A Generator builds usernames from a pattern of dash separated segments, each one a word
+// kind, a run of N for random digits, "timestamp", or literal text. Generated names never
+// contain blocked words, not even inside a word, and GenerateAvailable retries with numeric suffixes until an
+// availability check accepts one.
 package usernamegen
 
 import (
+	"bufio"
+	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"math/rand"
+	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Word kinds usable as pattern segments
+const (
+	Adverb    = "adverb"
+	Adjective = "adjective"
+	Noun      = "noun"
+	// Animal is an alias of Noun, the bundled nouns are animals
+	Animal = "animal"
+)
+
+const (
+	// DefaultPattern is used by generators created without WithPattern
+	DefaultPattern = "adjective-noun-NNNN"
+	// LegacyPattern is the pattern of Generate
+	LegacyPattern = "adverb-adjective-noun-timestamp"
+	// DefaultMaxAttempts bounds the candidates GenerateAvailable checks
+	DefaultMaxAttempts = 10
+	// maxSuffixDigits caps the length of the suffixes appended to taken usernames
+	maxSuffixDigits = 6
+)
+
+var (
+	// ErrNoAvailableUsername is returned when every candidate was taken
+	ErrNoAvailableUsername = errors.New("no available username found")
+	// ErrNoUsername is returned when every draw spelled a blocked word
+	ErrNoUsername = errors.New("no username without a blocked word found")
+	// ErrInvalidPattern is returned for patterns without segments or using a kind without words
+	ErrInvalidPattern = errors.New("invalid username pattern")
+)
+
 //go:embed data/adverbs.txt
 var adverbsData string
 
@@ -17,16 +57,21 @@ var adjectivesData string
 //go:embed data/animals.txt
 var animalsData string
 
+//go:embed data/blocklist.txt
+var blocklistData string
+
 var (
 	adverbs    []string
 	adjectives []string
 	animals    []string
+	blocklist  []string
 )
 
 func init() {
-	adverbs = strings.Split(strings.TrimSpace(adverbsData), "\n")
-	adjectives = strings.Split(strings.TrimSpace(adjectivesData), "\n")
-	animals = strings.Split(strings.TrimSpace(animalsData), "\n")
+	adverbs = parseWords(adverbsData)
+	adjectives = parseWords(adjectivesData)
+	animals = parseWords(animalsData)
+	blocklist = parseWords(blocklistData)
 }
 
 // Generate returns a unique username like "swift-sassy-panther-1712938493"
@@ -37,3 +82,308 @@ func Generate() string {
 	timestamp := time.Now().Unix()
 	return fmt.Sprintf("%s-%s-%s-%d", adverb, adjective, animal, timestamp)
 }
+
+// AvailabilityChecker reports whether a username is free to use
+type AvailabilityChecker func(ctx context.Context, username string) (bool, error)
+
+// Generator generates usernames from a pattern. It is safe for concurrent use.
+type Generator struct {
+	pattern     string
+	words       map[string][]string
+	blocklist   []string
+	maxAttempts int
+	now         func() time.Time
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithPattern sets the pattern, e.g. "adverb-noun-NNN" or "user-NNNNNN"
+func WithPattern(pattern string) Option {
+	return func(g *Generator) {
+		g.pattern = pattern
+	}
+}
+
+// WithWords replaces the word list of a kind, words are lower cased and blank ones dropped
+func WithWords(kind string, words []string) Option {
+	return func(g *Generator) {
+		g.words[normalizeKind(kind)] = cleanWords(words)
+	}
+}
+
+// WithBlocklist adds words that must not appear in generated usernames
+func WithBlocklist(words ...string) Option {
+	return func(g *Generator) {
+		g.blocklist = append(g.blocklist, cleanWords(words)...)
+	}
+}
+
+// WithSeed makes the generator deterministic, for tests
+func WithSeed(seed int64) Option {
+	return func(g *Generator) {
+		g.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithClock sets the time source of the "timestamp" segment
+func WithClock(now func() time.Time) Option {
+	return func(g *Generator) {
+		g.now = now
+	}
+}
+
+// WithMaxAttempts sets how many candidates GenerateAvailable checks before giving up
+func WithMaxAttempts(attempts int) Option {
+	return func(g *Generator) {
+		if attempts > 0 {
+			g.maxAttempts = attempts
+		}
+	}
+}
+
+// New creates a Generator using the bundled word lists and blocklist unless overridden
+func New(options ...Option) (*Generator, error) {
+	g := &Generator{
+		pattern: DefaultPattern,
+		words: map[string][]string{
+			Adverb:    adverbs,
+			Adjective: adjectives,
+			Noun:      animals,
+		},
+		blocklist:   append([]string(nil), blocklist...),
+		maxAttempts: DefaultMaxAttempts,
+		now:         time.Now,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	// Words containing a blocked word are removed up front, so only combinations need checking later
+	for kind, words := range g.words {
+		g.words[kind] = g.allowedWords(words)
+	}
+
+	if err := g.validatePattern(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// LoadWords reads a word list file with one word per line, blank lines and lines starting
+// with # are skipped
+func LoadWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	words = cleanWords(words)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("word list %s is empty", path)
+	}
+	return words, nil
+}
+
+// Generate returns a username following the pattern, without checking availability.
+// It returns ErrNoUsername when every draw spelled a blocked word.
+func (g *Generator) Generate() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// A handful of draws is enough, blocked combinations are rare
+	for i := 0; i < 10; i++ {
+		if username := g.generate(); !g.isBlocked(username) {
+			return username, nil
+		}
+	}
+	return "", ErrNoUsername
+}
+
+// GenerateAvailable returns a username the checker accepts. Taken names are retried with
+// numeric suffixes of growing length, e.g. "brave-lion-1234-57", then "brave-lion-1234-318".
+func (g *Generator) GenerateAvailable(ctx context.Context, available AvailabilityChecker) (string, error) {
+	base, err := g.Generate()
+	if err != nil {
+		return "", err
+	}
+
+	for attempt := 0; attempt < g.maxAttempts; attempt++ {
+		candidate := base
+		if attempt > 0 {
+			candidate = base + "-" + g.suffix(attempt+1)
+		}
+
+		ok, err := available(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return candidate, nil
+		}
+	}
+
+	return "", ErrNoAvailableUsername
+}
+
+// Suggest returns up to count distinct available usernames
+func (g *Generator) Suggest(ctx context.Context, count int, available AvailabilityChecker) ([]string, error) {
+	suggestions := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+
+	// Small word lists can run out of fresh names, so the number of tries is bounded
+	for tries := 0; len(suggestions) < count && tries < count*g.maxAttempts; tries++ {
+		username, err := g.GenerateAvailable(ctx, func(ctx context.Context, username string) (bool, error) {
+			if seen[username] {
+				return false, nil
+			}
+			return available(ctx, username)
+		})
+		if errors.Is(err, ErrNoAvailableUsername) || errors.Is(err, ErrNoUsername) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		seen[username] = true
+		suggestions = append(suggestions, username)
+	}
+
+	return suggestions, nil
+}
+
+// generate fills the pattern, the caller holds the lock
+func (g *Generator) generate() string {
+	segments := strings.Split(g.pattern, "-")
+	parts := make([]string, 0, len(segments))
+
+	for _, segment := range segments {
+		switch {
+		case segment == "timestamp":
+			parts = append(parts, fmt.Sprint(g.now().Unix()))
+		case isDigitRun(segment):
+			parts = append(parts, g.digits(len(segment)))
+		default:
+			if words, ok := g.words[normalizeKind(segment)]; ok {
+				parts = append(parts, words[g.rand.Intn(len(words))])
+			} else {
+				parts = append(parts, segment)
+			}
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+func (g *Generator) suffix(length int) string {
+	if length > maxSuffixDigits {
+		length = maxSuffixDigits
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.digits(length)
+}
+
+// digits returns random digits without a leading zero, the caller holds the lock
+func (g *Generator) digits(length int) string {
+	var b strings.Builder
+	b.WriteByte(byte('1' + g.rand.Intn(9)))
+	for i := 1; i < length; i++ {
+		b.WriteByte(byte('0' + g.rand.Intn(10)))
+	}
+	return b.String()
+}
+
+// isBlocked reports whether a blocked word appears in the username, including across the
+// boundary of two segments, such as "ass" in "bass-sole", and inside literal pattern text
+func (g *Generator) isBlocked(username string) bool {
+	return g.containsBlocked(strings.ReplaceAll(username, "-", ""))
+}
+
+// containsBlocked reports whether any blocked word appears in text
+func (g *Generator) containsBlocked(text string) bool {
+	for _, blocked := range g.blocklist {
+		if strings.Contains(text, blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedWords drops words containing a blocked word, like "sassy" for "ass"
+func (g *Generator) allowedWords(words []string) []string {
+	allowed := make([]string, 0, len(words))
+	for _, word := range words {
+		if !g.containsBlocked(word) {
+			allowed = append(allowed, word)
+		}
+	}
+	return allowed
+}
+
+func (g *Generator) validatePattern() error {
+	if strings.Trim(g.pattern, "-") == "" {
+		return ErrInvalidPattern
+	}
+
+	for _, segment := range strings.Split(g.pattern, "-") {
+		if segment == "" {
+			return fmt.Errorf("%w: empty segment in %q", ErrInvalidPattern, g.pattern)
+		}
+		if words, ok := g.words[normalizeKind(segment)]; ok && len(words) == 0 {
+			return fmt.Errorf("%w: no %s words", ErrInvalidPattern, segment)
+		}
+	}
+	return nil
+}
+
+func normalizeKind(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == Animal {
+		return Noun
+	}
+	return kind
+}
+
+func isDigitRun(segment string) bool {
+	return strings.Trim(segment, "N") == "" && len(segment) <= 18
+}
+
+// cleanWords lower cases and trims words, dropping blank ones and ones with a dash,
+// which would be ambiguous with the segment separator
+func cleanWords(words []string) []string {
+	cleaned := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" || strings.Contains(word, "-") {
+			continue
+		}
+		cleaned = append(cleaned, word)
+	}
+	return cleaned
+}
+
+func parseWords(data string) []string {
+	return cleanWords(strings.Split(strings.TrimSpace(data), "\n"))
+}
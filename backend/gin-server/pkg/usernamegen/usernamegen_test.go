@@ -1,6 +1,10 @@
 package usernamegen
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -61,3 +65,194 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// mustGenerate returns a username from g, failing the test on error
+func mustGenerate(t *testing.T, g *Generator) string {
+	t.Helper()
+	username, err := g.Generate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return username
+}
+
+func TestGeneratorPattern(t *testing.T) {
+	g, err := New(WithPattern("adjective-noun-NNNN"), WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parts := strings.Split(mustGenerate(t, g), "-")
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %d", len(parts))
+	}
+	if !contains(adjectives, parts[0]) || !contains(animals, parts[1]) {
+		t.Errorf("unexpected words in %v", parts)
+	}
+	if len(parts[2]) != 4 || strings.Trim(parts[2], "0123456789") != "" || parts[2][0] == '0' {
+		t.Errorf("expected 4 digits without a leading zero, got %q", parts[2])
+	}
+}
+
+func TestGeneratorSeedIsDeterministic(t *testing.T) {
+	clock := func() time.Time { return time.Unix(1700000000, 0) }
+	first, _ := New(WithSeed(42), WithPattern(LegacyPattern), WithClock(clock))
+	second, _ := New(WithSeed(42), WithPattern(LegacyPattern), WithClock(clock))
+
+	for i := 0; i < 5; i++ {
+		a, b := mustGenerate(t, first), mustGenerate(t, second)
+		if a != b {
+			t.Fatalf("expected the same sequence, got %q and %q", a, b)
+		}
+		if !strings.HasSuffix(a, "-1700000000") {
+			t.Errorf("expected the clock's timestamp in %q", a)
+		}
+	}
+}
+
+func TestGeneratorCustomWordsAndLiterals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nouns.txt")
+	if err := os.WriteFile(path, []byte("# planets\nMars\n\nvenus\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	nouns, err := LoadWords(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nouns) != 2 || nouns[0] != "mars" {
+		t.Fatalf("unexpected words %v", nouns)
+	}
+
+	g, err := New(WithPattern("user-noun"), WithWords(Noun, nouns), WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	username := mustGenerate(t, g)
+	if username != "user-mars" && username != "user-venus" {
+		t.Errorf("unexpected username %q", username)
+	}
+}
+
+func TestGeneratorInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"", "-", "noun--NNN"} {
+		if _, err := New(WithPattern(pattern)); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("pattern %q: expected ErrInvalidPattern, got %v", pattern, err)
+		}
+	}
+
+	// Every word being blocked leaves nothing to pick from
+	if _, err := New(WithWords(Noun, []string{"badword"}), WithBlocklist("badword")); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected ErrInvalidPattern, got %v", err)
+	}
+}
+
+func TestGeneratorBlocklist(t *testing.T) {
+	g, err := New(
+		WithPattern("adjective-noun"),
+		WithWords(Adjective, []string{"hot", "grey"}),
+		WithWords(Noun, []string{"wings", "hat"}),
+		WithBlocklist("twin"),
+		WithSeed(3),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 50; i++ {
+		// "hot-wings" spells a blocked word across the boundary
+		if username := mustGenerate(t, g); username == "hot-wings" {
+			t.Fatalf("generated blocked username %q", username)
+		}
+	}
+}
+
+func TestGeneratorBlocklistFiltersCustomWords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nouns.txt")
+	if err := os.WriteFile(path, []byte("Grasshopper\notter\nshellfish\nbadgerling\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	nouns, err := LoadWords(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The bundled blocklist catches "ass" and "hell" inside words, the custom one "badger"
+	g, err := New(WithPattern("noun"), WithWords(Noun, nouns), WithBlocklist("badger"), WithSeed(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if username := mustGenerate(t, g); username != "otter" {
+			t.Fatalf("generated username %q from a word containing a blocked word", username)
+		}
+	}
+}
+
+func TestGenerateFailsWhenEveryDrawIsBlocked(t *testing.T) {
+	// The literal segment is blocked, so no draw can succeed
+	g, err := New(WithPattern("hell-noun"), WithSeed(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if username, err := g.Generate(); !errors.Is(err, ErrNoUsername) {
+		t.Errorf("expected ErrNoUsername, got %q, %v", username, err)
+	}
+	if _, err := g.GenerateAvailable(context.Background(), func(ctx context.Context, username string) (bool, error) {
+		return true, nil
+	}); !errors.Is(err, ErrNoUsername) {
+		t.Errorf("expected ErrNoUsername, got %v", err)
+	}
+}
+
+func TestGenerateAvailableRetriesWithSuffix(t *testing.T) {
+	g, _ := New(WithPattern("noun"), WithWords(Noun, []string{"lion"}), WithSeed(1))
+
+	var checked []string
+	username, err := g.GenerateAvailable(context.Background(), func(ctx context.Context, username string) (bool, error) {
+		checked = append(checked, username)
+		return len(checked) == 3, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if checked[0] != "lion" {
+		t.Errorf("expected the plain name first, got %q", checked[0])
+	}
+	if username != checked[2] || !strings.HasPrefix(username, "lion-") || len(username) != len("lion-123") {
+		t.Errorf("expected a 3 digit suffix, got %q", username)
+	}
+
+	_, err = g.GenerateAvailable(context.Background(), func(ctx context.Context, username string) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, ErrNoAvailableUsername) {
+		t.Errorf("expected ErrNoAvailableUsername, got %v", err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	g, _ := New(WithSeed(7))
+	taken := map[string]bool{}
+
+	suggestions, err := g.Suggest(context.Background(), 5, func(ctx context.Context, username string) (bool, error) {
+		return !taken[username], nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(suggestions) != 5 {
+		t.Fatalf("expected 5 suggestions, got %v", suggestions)
+	}
+
+	seen := map[string]bool{}
+	for _, s := range suggestions {
+		if seen[s] {
+			t.Errorf("duplicate suggestion %q", s)
+		}
+		seen[s] = true
+	}
+}
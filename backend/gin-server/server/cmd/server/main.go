@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/bq"
@@ -20,14 +21,18 @@ func main() {
 	}
 
 	// Initialize logger
+	logLevel, err := logger.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		panic(fmt.Sprintf("invalid LOG_LEVEL: %v", err))
+	}
 	log := logger.New(
 		logger.WithServiceName("gin-server"),
-		logger.WithDefaultDestinations(logger.FileLogger, logger.ConsoleLogger),
-		logger.WithConsoleDestination(),
-		logger.WithFileDestination(utils.ResolvePathFromProjectRoot("logs/gin-server.log"), 10, 5, 30, true),
-		logger.WithMinLevel(logger.DebugLevel),
+		logger.WithMinLevel(logLevel),
 	)
+	addDestinations(log, cfg)
 	defer log.Close()
+	logger.SetDefault(log)
+	slog.SetDefault(logger.NewSlog(log))
 
 	// Initialize BigQuery client
 	ctx := context.Background()
@@ -61,3 +66,68 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// addDestinations adds the console, log file, in-memory buffer and configured syslog, HTTP and OTLP
+// destinations, all default destinations. A network destination that can't be created is skipped, the server runs
+// without it.
+func addDestinations(log *logger.Logger, cfg *config.Config) {
+	var redactor *logger.Redactor
+	if cfg.LogRedaction != "" {
+		strategy, err := logger.ParseMaskStrategy(cfg.LogRedaction)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid LOG_REDACTION, masking values fully: %v\n", err)
+		}
+		redactor = logger.NewRedactor(logger.WithMaskStrategy(strategy))
+	}
+
+	var dests []string
+	add := func(name string, dest logger.Destination) {
+		if redactor != nil {
+			dest = logger.NewRedactingDestination(dest, redactor)
+		}
+		log.AddDestination(name, dest)
+		dests = append(dests, name)
+		log.SetDefaultDestinations(dests...)
+	}
+
+	add(logger.ConsoleLogger, logger.NewConsoleDestination())
+	// Writes to the log file happen in the background, off the request path
+	add(logger.FileLogger, logger.NewAsyncDestination(
+		logger.NewFileDestination(utils.ResolvePathFromProjectRoot("logs/gin-server.log"), 10, 5, 30, true),
+	))
+	// Recent entries for admins to query, see /api/admin/logs
+	if cfg.LogBufferSize > 0 {
+		add(logger.MemoryLogger, logger.NewRingBufferDestination(cfg.LogBufferSize))
+	}
+
+	if cfg.LogSyslogAddress != "" {
+		syslog, err := logger.NewSyslogDestination(logger.SyslogConfig{
+			Network: cfg.LogSyslogNetwork,
+			Address: cfg.LogSyslogAddress,
+		})
+		if err != nil {
+			log.Errorf("Failed to create syslog log destination: %v", err)
+		} else {
+			// Syslog messages are sent as they are written, keep them off the request path
+			add(logger.SyslogLogger, logger.NewAsyncDestination(syslog))
+		}
+	}
+
+	if cfg.LogHTTPURL != "" {
+		httpBatch, err := logger.NewHTTPBatchDestination(logger.BatchConfig{URL: cfg.LogHTTPURL, Gzip: true})
+		if err != nil {
+			log.Errorf("Failed to create HTTP log destination: %v", err)
+		} else {
+			add(logger.HTTPLogger, httpBatch)
+		}
+	}
+
+	if cfg.LogOTLPURL != "" {
+		otlp, err := logger.NewOTLPDestination(logger.BatchConfig{URL: cfg.LogOTLPURL, Gzip: true})
+		if err != nil {
+			log.Errorf("Failed to create OTLP log destination: %v", err)
+		} else {
+			add(logger.OTLPLogger, otlp)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package request
+
+import "time"
+
+// ActivityRequest filters the current user's own activity
+type ActivityRequest struct {
+	Action   string    `form:"action"`
+	Since    time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until    time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page     int       `form:"page" binding:"omitempty,min=1"`
+	PageSize int       `form:"pageSize" binding:"omitempty,min=1"`
+}
+
+// AuditQueryRequest filters the audit trail of all users
+type AuditQueryRequest struct {
+	ActivityRequest
+	ActorID  string `form:"actorId"`
+	TargetID string `form:"targetId"`
+	Outcome  string `form:"outcome" binding:"omitempty,oneof=success failure"`
+}
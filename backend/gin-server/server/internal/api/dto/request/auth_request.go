@@ -2,7 +2,7 @@ package request
 
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=4"`
+	Password string `json:"password" binding:"required"` // Checked against the password policy
 }
 
 type LoginRequest struct {
@@ -11,5 +11,18 @@ type LoginRequest struct {
 }
 
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refreshToken" binding:"required"`
+	RefreshToken string `json:"refreshToken"` // Read from the refresh cookie when empty
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"` // Read from the refresh cookie when empty
+}
+
+type MagicLinkRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	BindDevice bool   `json:"bindDevice"` // Only accept the link in the requesting browser
+}
+
+type ConsumeMagicLinkRequest struct {
+	Token string `json:"token" binding:"required"`
 }
@@ -0,0 +1,9 @@
+package request
+
+// LogLevelRequest changes the minimum log level, or the level of a component or destination when one is named
+type LogLevelRequest struct {
+	Level       string `json:"level"` // Empty resets a component or destination level
+	Component   string `json:"component" binding:"omitempty,max=64"`
+	Destination string `json:"destination" binding:"omitempty,max=64"`
+	RevertAfter string `json:"revertAfter"` // Duration such as "5m" after which the change is undone, at most 24h
+}
@@ -0,0 +1,13 @@
+package request
+
+import "time"
+
+// LogQueryRequest filters the recent log entries kept in memory
+type LogQueryRequest struct {
+	Level     string    `form:"level"` // Minimum level, all levels when empty
+	Since     time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	RequestID string    `form:"requestId"`
+	Query     string    `form:"q" binding:"max=256"` // Substring of the message or a field value, ignoring case
+	Limit     int       `form:"limit" binding:"omitempty,min=1,max=1000"`
+}
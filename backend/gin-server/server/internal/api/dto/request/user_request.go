@@ -0,0 +1,42 @@
+package request
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"` // Checked against the password policy
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ListUsersRequest struct {
+	Search   string `form:"search"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"pageSize" binding:"omitempty,min=1"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required"` // Checked against the password policy
+}
+
+// UsernameSuggestRequest asks for a number of available usernames
+type UsernameSuggestRequest struct {
+	Count int `form:"count" binding:"omitempty,min=1"`
+}
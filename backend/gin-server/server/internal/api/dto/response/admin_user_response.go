@@ -0,0 +1,79 @@
+package response
+
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+)
+
+// AdminUser is the admin view of a user, it adds the account status to UserPublic
+type AdminUser struct {
+	UserPublic
+	Status string `json:"status"`
+}
+
+type AdminUserIdentity struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AdminUserDetails struct {
+	AdminUser
+	Reminders      int                 `json:"reminders"`
+	ReminderGroups int                 `json:"reminderGroups"`
+	APITokens      int                 `json:"apiTokens"`
+	Identities     []AdminUserIdentity `json:"identities"`
+}
+
+type AdminUserList struct {
+	Users    []AdminUser `json:"users"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
+}
+
+func NewAdminUser(user *domain.User) AdminUser {
+	status := user.Status
+	if status == "" {
+		status = domain.UserStatusActive
+	}
+	return AdminUser{
+		UserPublic: *NewUserPublic(user),
+		Status:     status,
+	}
+}
+
+func NewAdminUserDetails(details *service.UserDetails) AdminUserDetails {
+	identities := make([]AdminUserIdentity, 0, len(details.Identities))
+	for _, identity := range details.Identities {
+		identities = append(identities, AdminUserIdentity{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+
+	return AdminUserDetails{
+		AdminUser:      NewAdminUser(details.User),
+		Reminders:      details.Reminders,
+		ReminderGroups: details.ReminderGroups,
+		APITokens:      details.APITokens,
+		Identities:     identities,
+	}
+}
+
+func NewAdminUserList(list *service.UserList) AdminUserList {
+	users := make([]AdminUser, 0, len(list.Users))
+	for i := range list.Users {
+		users = append(users, NewAdminUser(&list.Users[i]))
+	}
+
+	return AdminUserList{
+		Users:    users,
+		Total:    list.Total,
+		Page:     list.Page,
+		PageSize: list.PageSize,
+	}
+}
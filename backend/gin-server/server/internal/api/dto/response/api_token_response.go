@@ -0,0 +1,40 @@
+package response
+
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type APITokenResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedAPITokenResponse is only returned once, when the plain token is still known
+type CreatedAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
+
+func NewAPITokenResponse(token *domain.APIToken) APITokenResponse {
+	res := APITokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		Prefix:    token.Prefix,
+		Scopes:    token.ScopeList(),
+		CreatedAt: token.CreatedAt,
+	}
+	if !token.ExpiresAt.IsZero() {
+		res.ExpiresAt = &token.ExpiresAt
+	}
+	if !token.LastUsedAt.IsZero() {
+		res.LastUsedAt = &token.LastUsedAt
+	}
+	return res
+}
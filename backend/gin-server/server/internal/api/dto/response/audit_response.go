@@ -0,0 +1,63 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+)
+
+type AuditEvent struct {
+	ID        string          `json:"id"`
+	Sequence  int64           `json:"sequence"`
+	ActorID   string          `json:"actorId,omitempty"`
+	Action    string          `json:"action"`
+	TargetID  string          `json:"targetId,omitempty"`
+	IP        string          `json:"ip,omitempty"`
+	UserAgent string          `json:"userAgent,omitempty"`
+	Outcome   string          `json:"outcome"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	Hash      string          `json:"hash"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type AuditEventList struct {
+	Events   []AuditEvent `json:"events"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+}
+
+func NewAuditEvent(event *audit.Event) AuditEvent {
+	response := AuditEvent{
+		ID:        event.ID,
+		Sequence:  event.Sequence,
+		ActorID:   event.ActorID,
+		Action:    event.Action,
+		TargetID:  event.TargetID,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Outcome:   event.Outcome,
+		Hash:      event.Hash,
+		CreatedAt: event.CreatedAt,
+	}
+	// Metadata is stored as a JSON object, embedded as is rather than as an escaped string
+	if event.Metadata != "" && json.Valid([]byte(event.Metadata)) {
+		response.Metadata = json.RawMessage(event.Metadata)
+	}
+	return response
+}
+
+func NewAuditEventList(page *audit.Page) AuditEventList {
+	events := make([]AuditEvent, 0, len(page.Events))
+	for i := range page.Events {
+		events = append(events, NewAuditEvent(&page.Events[i]))
+	}
+
+	return AuditEventList{
+		Events:   events,
+		Total:    page.Total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	}
+}
@@ -1,6 +1,10 @@
 package response
 
-import "time"
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
 
 type AuthResponse struct {
 	AccessToken  string      `json:"accessToken"`
@@ -9,10 +13,22 @@ type AuthResponse struct {
 }
 
 type UserPublic struct {
-	ID        uint      `json:"id"`
+	ID        string    `json:"id"`
 	Username  string    `json:"username"`
 	Role      string    `json:"role"`
 	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// NewUserPublic returns the user fields that are safe to expose, never the password hash
+func NewUserPublic(user *domain.User) *UserPublic {
+	return &UserPublic{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
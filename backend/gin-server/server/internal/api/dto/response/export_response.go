@@ -0,0 +1,36 @@
+package response
+
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type ExportJobResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Size        int64      `json:"size,omitempty"`
+	DownloadURL string     `json:"downloadUrl,omitempty"` // Signed link, only set once the export is done
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func NewExportJobResponse(job *domain.ExportJob, downloadURL string) ExportJobResponse {
+	res := ExportJobResponse{
+		ID:          job.ID,
+		Status:      job.Status,
+		Error:       job.Error,
+		Size:        job.Size,
+		DownloadURL: downloadURL,
+		CreatedAt:   job.CreatedAt,
+	}
+	if !job.CompletedAt.IsZero() {
+		res.CompletedAt = &job.CompletedAt
+	}
+	if !job.ExpiresAt.IsZero() {
+		res.ExpiresAt = &job.ExpiresAt
+	}
+	return res
+}
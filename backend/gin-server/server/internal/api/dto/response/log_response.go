@@ -0,0 +1,48 @@
+package response
+
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+)
+
+type LogEntry struct {
+	Sequence uint64                 `json:"sequence"`
+	Time     time.Time              `json:"time"`
+	Level    logger.LogLevel        `json:"level"`
+	Service  string                 `json:"service,omitempty"`
+	Message  string                 `json:"message"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
+type LogEntryList struct {
+	Entries []LogEntry `json:"entries"`
+}
+
+func NewLogEntry(entry logger.RecordedEntry) LogEntry {
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for k, v := range entry.Fields {
+		// Errors have no exported fields and would be encoded as empty objects
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		fields[k] = v
+	}
+
+	return LogEntry{
+		Sequence: entry.Sequence,
+		Time:     entry.Time,
+		Level:    entry.Level,
+		Service:  entry.ServiceName,
+		Message:  entry.Message,
+		Fields:   fields,
+	}
+}
+
+func NewLogEntryList(entries []logger.RecordedEntry) LogEntryList {
+	list := make([]LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, NewLogEntry(entry))
+	}
+	return LogEntryList{Entries: list}
+}
@@ -0,0 +1,27 @@
+package response
+
+import (
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type LockoutResponse struct {
+	ID          string    `json:"id"`
+	Kind        string    `json:"kind"`
+	Subject     string    `json:"subject"`
+	Failures    int       `json:"failures"`
+	LockedAt    time.Time `json:"lockedAt"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
+
+func NewLockoutResponse(attempt *domain.LoginAttempt) LockoutResponse {
+	return LockoutResponse{
+		ID:          attempt.ID,
+		Kind:        attempt.Kind,
+		Subject:     attempt.Subject,
+		Failures:    attempt.Failures,
+		LockedAt:    attempt.LockedAt,
+		LockedUntil: attempt.LockedUntil,
+	}
+}
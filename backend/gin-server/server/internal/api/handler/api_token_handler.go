@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type APITokenHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+// apiTokenHandler handles personal access token management requests
+type apiTokenHandler struct {
+	apiTokenService service.APITokenService
+	authManager     *auth.AuthManager
+	log             *logger.Logger
+}
+
+// NewAPITokenHandler creates a new APITokenHandler instance
+func NewAPITokenHandler(apiTokenService service.APITokenService, authManager *auth.AuthManager, log *logger.Logger) APITokenHandler {
+	return &apiTokenHandler{
+		apiTokenService: apiTokenService,
+		authManager:     authManager,
+		log:             log,
+	}
+}
+
+// Create issues a new personal access token for the current user
+func (h *apiTokenHandler) Create(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+
+	token, apiToken, err := h.apiTokenService.Create(c.Request.Context(), claims, req.Name, req.Scopes, expiresIn)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidScope), errors.Is(err, service.ErrTooManyAPITokens):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrScopeNotHeld):
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+		default:
+			h.log.Warnf("Creating api token failed for userID: %s, error: %v", claims.EntityID, err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create api token")
+		}
+		return
+	}
+
+	h.log.Infof("Api token created userID: %s, tokenID: %s", claims.EntityID, apiToken.ID)
+	utils.SuccessResponse(c, http.StatusCreated, response.CreatedAPITokenResponse{
+		APITokenResponse: response.NewAPITokenResponse(apiToken),
+		Token:            token,
+	})
+}
+
+// List returns the personal access tokens of the current user
+func (h *apiTokenHandler) List(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(c.Request.Context(), claims.EntityID)
+	if err != nil {
+		h.log.Warnf("Listing api tokens failed for userID: %s, error: %v", claims.EntityID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to list api tokens")
+		return
+	}
+
+	res := make([]response.APITokenResponse, 0, len(tokens))
+	for i := range tokens {
+		res = append(res, response.NewAPITokenResponse(&tokens[i]))
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, res)
+}
+
+// Revoke deletes a personal access token of the current user
+func (h *apiTokenHandler) Revoke(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tokenID := c.Param("id")
+	if err := h.apiTokenService.Revoke(c.Request.Context(), claims.EntityID, tokenID); err != nil {
+		if errors.Is(err, domain.ErrAPITokenNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.log.Warnf("Revoking api token failed for userID: %s, tokenID: %s, error: %v", claims.EntityID, tokenID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to revoke api token")
+		return
+	}
+
+	h.log.Infof("Api token revoked userID: %s, tokenID: %s", claims.EntityID, tokenID)
+	c.Status(http.StatusNoContent)
+}
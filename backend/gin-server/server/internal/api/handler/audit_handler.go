@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type AuditHandler interface {
+	MyActivity(c *gin.Context)
+	Query(c *gin.Context)
+	Verify(c *gin.Context)
+}
+
+// auditHandler exposes the security audit trail
+type auditHandler struct {
+	trail       audit.Trail
+	authManager *auth.AuthManager
+	log         *logger.Logger
+}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler(trail audit.Trail, authManager *auth.AuthManager, log *logger.Logger) AuditHandler {
+	return &auditHandler{
+		trail:       trail,
+		authManager: authManager,
+		log:         log,
+	}
+}
+
+// MyActivity returns the actions performed by the current user, newest first
+func (h *auditHandler) MyActivity(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.ActivityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := h.trail.Query(c.Request.Context(), audit.Filter{
+		ActorID:  claims.EntityID,
+		Action:   req.Action,
+		Since:    req.Since,
+		Until:    req.Until,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		h.log.Warnf("Listing activity failed for userID: %s, error: %v", claims.EntityID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to list activity")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewAuditEventList(page))
+}
+
+// Query returns the audit events of all users matching the filters, newest first
+func (h *auditHandler) Query(c *gin.Context) {
+	var req request.AuditQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := h.trail.Query(c.Request.Context(), audit.Filter{
+		ActorID:  req.ActorID,
+		TargetID: req.TargetID,
+		Action:   req.Action,
+		Outcome:  req.Outcome,
+		Since:    req.Since,
+		Until:    req.Until,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		h.log.Warnf("Querying audit events failed, error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to query audit events")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewAuditEventList(page))
+}
+
+// Verify checks the hash chain of the audit trail
+func (h *auditHandler) Verify(c *gin.Context) {
+	result, err := h.trail.Verify(c.Request.Context())
+	if err != nil {
+		h.log.Warnf("Verifying audit trail failed, error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to verify audit trail")
+		return
+	}
+
+	if !result.Valid {
+		h.log.Errorf("Audit trail is broken at event %d: %s", result.BrokenAt, result.Reason)
+	}
+	utils.SuccessResponse(c, http.StatusOK, result)
+}
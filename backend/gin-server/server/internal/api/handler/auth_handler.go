@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
 )
@@ -15,6 +21,7 @@ type AuthHandler interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
 	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
 	GetMe(c *gin.Context)
 }
 
@@ -46,6 +53,12 @@ func (h *authHandler) Register(c *gin.Context) {
 
 	user, err := h.authService.Register(c.Request.Context(), req.Email, req.Password)
 
+	var policyErr *domain.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "password does not meet the policy", policyErr.Violations)
+		return
+	}
+
 	if err != nil {
 		h.log.Warnf("Registration failed for email: %s, error: %v", req.Email, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -65,13 +78,29 @@ func (h *authHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	accessToken, refreshToken, err := h.authService.Login(c.Request.Context(), req.Email, req.Password, c.ClientIP())
 	if err != nil {
 		h.log.Warnf("Login failed for email: %s, error: %v", req.Email, err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+
+		// Keep the messages generic, they must not reveal whether the email exists
+		var lockedErr *domain.LoginLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": domain.ErrTooManyLoginAttempts.Error()})
+		case errors.Is(err, domain.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidCredentials.Error()})
+		case errors.Is(err, domain.ErrAccountDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrAccountDisabled.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		}
 		return
 	}
 
+	// Browsers get the tokens as cookies too, a no-op unless cookie mode is enabled
+	h.authManager.SetTokenCookies(c.Writer, accessToken, refreshToken)
+
 	// Return the tokens
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"accessToken":  accessToken,
@@ -79,27 +108,69 @@ func (h *authHandler) Login(c *gin.Context) {
 	})
 }
 
-// RefreshToken handles token refresh
+// RefreshToken handles token refresh, the refresh token is taken from the body or its cookie
 func (h *authHandler) RefreshToken(c *gin.Context) {
 	var req request.RefreshTokenRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	// The body is optional for browsers sending the refresh cookie
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		var err error
+		if refreshToken, err = h.authManager.ExtractRefreshTokenFromRequest(c.Request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing refresh token"})
+			return
+		}
+	}
+
+	accessToken, newRefreshToken, err := h.authService.Refresh(c.Request.Context(), refreshToken)
 	if err != nil {
+		// Stale cookies would fail every following request, so they are dropped
+		h.authManager.ClearTokenCookies(c.Writer)
+		if errors.Is(err, domain.ErrAccountDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrAccountDisabled.Error()})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
 		return
 	}
 
+	h.authManager.SetTokenCookies(c.Writer, accessToken, newRefreshToken)
+
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"accessToken":  accessToken,
-		"refreshToken": refreshToken,
+		"refreshToken": newRefreshToken,
 	})
 }
 
+// Logout revokes the session tokens and clears the token cookies. It works without a valid
+// access token, so clients can always log out.
+func (h *authHandler) Logout(c *gin.Context) {
+	var req request.LogoutRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		refreshToken, _ = h.authManager.ExtractRefreshTokenFromRequest(c.Request)
+	}
+	accessToken, _ := h.authManager.ExtractTokenFromRequest(c.Request)
+
+	if err := h.authService.Logout(c.Request.Context(), accessToken, refreshToken); err != nil {
+		h.log.Warnf("Revoking tokens on logout failed, error: %v", err)
+	}
+
+	h.authManager.ClearTokenCookies(c.Writer)
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Logged out"})
+}
+
 func (h *authHandler) GetMe(c *gin.Context) {
 	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
 	if !exists {
@@ -114,5 +185,5 @@ func (h *authHandler) GetMe(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, user)
+	utils.SuccessResponse(c, http.StatusOK, response.NewUserPublic(user))
 }
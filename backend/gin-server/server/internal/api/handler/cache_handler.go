@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type CacheHandler interface {
+	Stats(c *gin.Context)
+}
+
+// cacheHandler reports how well the repository caches work
+type cacheHandler struct {
+	repositories []repository.CachedRepository
+	store        memcache.Cache
+}
+
+// NewCacheHandler creates a new CacheHandler instance
+func NewCacheHandler(repositories []repository.CachedRepository, store memcache.Cache) CacheHandler {
+	return &cacheHandler{
+		repositories: repositories,
+		store:        store,
+	}
+}
+
+// Stats returns the hit ratio of every cached repository, and the size of the cache store when it is kept in memory
+func (h *cacheHandler) Stats(c *gin.Context) {
+	repositories := make(map[string]repository.CacheStats, len(h.repositories))
+	for _, repo := range h.repositories {
+		repositories[repo.CacheName()] = repo.CacheStats()
+	}
+
+	body := gin.H{"repositories": repositories}
+	if store, ok := h.store.(interface{ Stats() memcache.Stats }); ok {
+		body["store"] = store.Stats()
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, body)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type ExportHandler interface {
+	Start(c *gin.Context)
+	Get(c *gin.Context)
+	Download(c *gin.Context)
+}
+
+// exportHandler handles personal data export requests
+type exportHandler struct {
+	exportService service.ExportService
+	authManager   *auth.AuthManager
+	log           *logger.Logger
+}
+
+// NewExportHandler creates a new ExportHandler instance
+func NewExportHandler(exportService service.ExportService, authManager *auth.AuthManager, log *logger.Logger) ExportHandler {
+	return &exportHandler{
+		exportService: exportService,
+		authManager:   authManager,
+		log:           log,
+	}
+}
+
+// Start queues an export of the current user's data, the client polls the job until it is done
+func (h *exportHandler) Start(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	job, err := h.exportService.Start(c.Request.Context(), claims.EntityID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExportInProgress) {
+			utils.ErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		h.log.Warnf("Starting export failed for userID: %s, error: %v", claims.EntityID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to start export")
+		return
+	}
+
+	h.log.Infof("Export started userID: %s, jobID: %s", claims.EntityID, job.ID)
+	utils.SuccessResponse(c, http.StatusAccepted, response.NewExportJobResponse(job, ""))
+}
+
+// Get returns the status of an export job, with a download link once it is done
+func (h *exportHandler) Get(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	job, err := h.exportService.Get(c.Request.Context(), claims.EntityID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, domain.ErrExportJobNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.log.Warnf("Getting export failed for userID: %s, error: %v", claims.EntityID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get export")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewExportJobResponse(job, h.exportService.DownloadURL(job)))
+}
+
+// Download serves an export archive. The signed link is the credential, so no authentication is required.
+func (h *exportHandler) Download(c *gin.Context) {
+	job, err := h.exportService.Open(c.Request.Context(), c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidDownloadLink) {
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		h.log.Warnf("Opening export failed for jobID: %s, error: %v", c.Param("id"), err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to download export")
+		return
+	}
+
+	if _, err := os.Stat(job.FilePath); err != nil {
+		h.log.Warnf("Export archive missing for jobID: %s, error: %v", job.ID, err)
+		utils.ErrorResponse(c, http.StatusGone, "export is no longer available")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(job.FilePath, "reminder-export-"+job.CreatedAt.Format("2006-01-02")+".zip")
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type LockoutHandler interface {
+	List(c *gin.Context)
+	Clear(c *gin.Context)
+}
+
+// lockoutHandler handles the admin requests to inspect and lift login lockouts
+type lockoutHandler struct {
+	loginAttemptService service.LoginAttemptService
+	log                 *logger.Logger
+}
+
+// NewLockoutHandler creates a new LockoutHandler instance
+func NewLockoutHandler(loginAttemptService service.LoginAttemptService, log *logger.Logger) LockoutHandler {
+	return &lockoutHandler{
+		loginAttemptService: loginAttemptService,
+		log:                 log,
+	}
+}
+
+// List returns the accounts and client IPs that are currently locked out
+func (h *lockoutHandler) List(c *gin.Context) {
+	attempts, err := h.loginAttemptService.ListLocked(c.Request.Context())
+	if err != nil {
+		h.log.Warnf("Listing lockouts failed, error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to list lockouts")
+		return
+	}
+
+	res := make([]response.LockoutResponse, 0, len(attempts))
+	for i := range attempts {
+		res = append(res, response.NewLockoutResponse(&attempts[i]))
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, res)
+}
+
+// Clear lifts a lockout
+func (h *lockoutHandler) Clear(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.loginAttemptService.Clear(c.Request.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrLoginAttemptNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.log.Warnf("Clearing lockout failed for id: %s, error: %v", id, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to clear lockout")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+// maxLogLevelRevertAfter bounds temporary level changes, so a forgotten debug level doesn't last
+const maxLogLevelRevertAfter = 24 * time.Hour
+
+type LogLevelHandler interface {
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+}
+
+// logLevelHandler handles the admin requests to read and change log levels at runtime
+type logLevelHandler struct {
+	log *logger.Logger
+}
+
+// NewLogLevelHandler creates a new LogLevelHandler instance
+func NewLogLevelHandler(log *logger.Logger) LogLevelHandler {
+	return &logLevelHandler{log: log}
+}
+
+// Get returns the minimum level, the component and destination overrides, and when temporary changes revert
+func (h *logLevelHandler) Get(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, h.log.Levels())
+}
+
+// Update changes the minimum level, or the level of a component or destination, optionally for a limited time
+func (h *logLevelHandler) Update(c *gin.Context) {
+	var req request.LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+	if req.Component != "" && req.Destination != "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Set either a component or a destination")
+		return
+	}
+	if req.Level == "" && req.Component == "" && req.Destination == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Level is required")
+		return
+	}
+	if req.Destination != "" && !slices.Contains(h.log.DestinationNames(), req.Destination) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unknown destination")
+		return
+	}
+
+	var revertAfter time.Duration
+	if req.RevertAfter != "" {
+		var err error
+		revertAfter, err = time.ParseDuration(req.RevertAfter)
+		if err != nil || revertAfter <= 0 || revertAfter > maxLogLevelRevertAfter {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Revert after must be a positive duration of at most 24h")
+			return
+		}
+	}
+
+	var level logger.LogLevel
+	if req.Level != "" {
+		var err error
+		if level, err = logger.ParseLevel(req.Level); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid level")
+			return
+		}
+	}
+
+	switch {
+	case req.Component != "" && req.Level == "":
+		h.log.ResetComponentLevel(req.Component)
+	case req.Component != "":
+		h.log.SetComponentLevel(req.Component, level, revertAfter)
+	case req.Destination != "" && req.Level == "":
+		h.log.ResetDestinationLevel(req.Destination)
+	case req.Destination != "":
+		h.log.SetDestinationLevel(req.Destination, level, revertAfter)
+	default:
+		h.log.SetLevel(level, revertAfter)
+	}
+
+	h.log.WithContext(c.Request.Context()).Info("Log level changed", map[string]interface{}{
+		"level":        req.Level,
+		"component":    req.Component,
+		"destination":  req.Destination,
+		"revert_after": revertAfter.String(),
+	})
+	utils.SuccessResponse(c, http.StatusOK, h.log.Levels())
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+const (
+	defaultLogQueryLimit = 100
+	// logTailBuffer is the number of entries a slow tail client can fall behind before missing some
+	logTailBuffer = 256
+	// logTailKeepAlive keeps idle tail connections open through proxies
+	logTailKeepAlive = 15 * time.Second
+)
+
+type LogsHandler interface {
+	Query(c *gin.Context)
+	Tail(c *gin.Context)
+}
+
+// logsHandler lets admins read the recent log entries kept in memory
+type logsHandler struct {
+	ring *logger.RingBufferDestination
+	log  *logger.Logger
+}
+
+// NewLogsHandler creates a new LogsHandler instance, ring is nil when the in-memory log buffer is disabled
+func NewLogsHandler(ring *logger.RingBufferDestination, log *logger.Logger) LogsHandler {
+	return &logsHandler{
+		ring: ring,
+		log:  log,
+	}
+}
+
+// Query returns the recent entries matching the filters, oldest first
+func (h *logsHandler) Query(c *gin.Context) {
+	q, ok := h.bindQuery(c)
+	if !ok {
+		return
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultLogQueryLimit
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewLogEntryList(h.ring.Query(q)))
+}
+
+// Tail streams the entries matching the filters as server-sent events, as they are written. A client
+// reconnecting with Last-Event-ID first receives the kept entries it missed.
+func (h *logsHandler) Tail(c *gin.Context) {
+	q, ok := h.bindQuery(c)
+	if !ok {
+		return
+	}
+	// A stream has no end, the limit and end of the time range don't apply
+	q.Limit = 0
+	q.Until = time.Time{}
+
+	entries, cancel := h.ring.Subscribe(logTailBuffer)
+	defer cancel()
+
+	var backlog []logger.RecordedEntry
+	if lastID, err := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64); err == nil {
+		q.AfterSequence = lastID
+		backlog = h.ring.Query(q)
+	}
+
+	keepAlive := time.NewTicker(logTailKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, entry := range backlog {
+		h.sendEntry(c, entry)
+		q.AfterSequence = entry.Sequence
+	}
+	c.Writer.Flush()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := io.WriteString(c.Writer, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			// Entries written while the backlog was read are already sent
+			if !q.Match(entry) {
+				continue
+			}
+			h.sendEntry(c, entry)
+		}
+		c.Writer.Flush()
+	}
+}
+
+func (h *logsHandler) sendEntry(c *gin.Context, entry logger.RecordedEntry) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(entry.Sequence, 10),
+		Event: "log",
+		Data:  response.NewLogEntry(entry),
+	})
+}
+
+// bindQuery converts the query parameters to a ring buffer query, responding with an error when they
+// are invalid or the buffer is disabled
+func (h *logsHandler) bindQuery(c *gin.Context) (logger.RingQuery, bool) {
+	if h.ring == nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "Log buffer is disabled")
+		return logger.RingQuery{}, false
+	}
+
+	var req request.LogQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return logger.RingQuery{}, false
+	}
+
+	q := logger.RingQuery{
+		Since:     req.Since,
+		Until:     req.Until,
+		RequestID: req.RequestID,
+		Contains:  req.Query,
+		Limit:     req.Limit,
+	}
+	if req.Level != "" {
+		level, err := logger.ParseLevel(req.Level)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid level")
+			return logger.RingQuery{}, false
+		}
+		q.MinLevel = level
+	}
+	return q, true
+}
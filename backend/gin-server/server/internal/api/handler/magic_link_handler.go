@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+// magicLinkDeviceCookie holds the nonce binding a login link to the browser that requested it
+const magicLinkDeviceCookie = "magic_link_device"
+
+type MagicLinkHandler interface {
+	Request(c *gin.Context)
+	Consume(c *gin.Context)
+}
+
+// magicLinkHandler handles passwordless login by email
+type magicLinkHandler struct {
+	magicLinkService service.MagicLinkService
+	authManager      *auth.AuthManager
+	log              *logger.Logger
+}
+
+// NewMagicLinkHandler creates a new MagicLinkHandler instance
+func NewMagicLinkHandler(magicLinkService service.MagicLinkService, authManager *auth.AuthManager, log *logger.Logger) MagicLinkHandler {
+	return &magicLinkHandler{
+		magicLinkService: magicLinkService,
+		authManager:      authManager,
+		log:              log,
+	}
+}
+
+// Request emails a login link. The response is the same whether or not the address belongs to
+// an account, or a link was withheld by the rate limit, so it can't be used to find accounts.
+func (h *magicLinkHandler) Request(c *gin.Context) {
+	var req request.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	deviceNonce := ""
+	if req.BindDevice {
+		nonce, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			h.log.Errorf("Generating login link device nonce failed, error: %v", err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send login link")
+			return
+		}
+		deviceNonce = nonce
+	}
+
+	err := h.magicLinkService.Request(c.Request.Context(), req.Email, deviceNonce)
+	switch {
+	case errors.Is(err, domain.ErrTooManyMagicLinks):
+		h.log.Warnf("Login link rate limit reached for email: %s", req.Email)
+	case err != nil:
+		h.log.Warnf("Sending login link failed for email: %s, error: %v", req.Email, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send login link")
+		return
+	}
+
+	// Set even for unknown addresses, a missing cookie would tell them apart
+	if deviceNonce != "" {
+		h.setDeviceCookie(c, deviceNonce, int(service.MagicLinkTTL.Seconds()))
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, gin.H{"message": "If the address belongs to an account, a login link has been sent"})
+}
+
+// Consume exchanges a login link for a token pair
+func (h *magicLinkHandler) Consume(c *gin.Context) {
+	var req request.ConsumeMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	deviceNonce := ""
+	if cookie, err := c.Request.Cookie(magicLinkDeviceCookie); err == nil {
+		deviceNonce = cookie.Value
+	}
+
+	accessToken, refreshToken, err := h.magicLinkService.Consume(c.Request.Context(), req.Token, deviceNonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidMagicLink):
+			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, domain.ErrAccountDisabled):
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+		default:
+			h.log.Warnf("Consuming login link failed, error: %v", err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Login failed")
+		}
+		return
+	}
+
+	if deviceNonce != "" {
+		h.setDeviceCookie(c, "", -1)
+	}
+	h.authManager.SetTokenCookies(c.Writer, accessToken, refreshToken)
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"accessToken":  accessToken,
+		"refreshToken": refreshToken,
+	})
+}
+
+// setDeviceCookie sets or, with a negative max age, clears the device nonce cookie
+func (h *magicLinkHandler) setDeviceCookie(c *gin.Context, value string, maxAge int) {
+	config := h.authManager.Config
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     magicLinkDeviceCookie,
+		Value:    value,
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   config.SecureCookies,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode, // Sent when the link is opened from an email client
+	})
+}
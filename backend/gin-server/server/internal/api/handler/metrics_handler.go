@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type MetricsHandler interface {
+	Metrics(c *gin.Context)
+}
+
+// metricsHandler exposes the metrics to Prometheus
+type metricsHandler struct {
+	registry *metrics.Registry
+	token    string
+	log      *logger.Logger
+}
+
+// NewMetricsHandler creates a new MetricsHandler instance, scrapers must send token as a bearer token when it isn't empty
+func NewMetricsHandler(registry *metrics.Registry, token string, log *logger.Logger) MetricsHandler {
+	return &metricsHandler{
+		registry: registry,
+		token:    token,
+		log:      log,
+	}
+}
+
+// Metrics writes the metrics in the Prometheus text exposition format
+func (h *metricsHandler) Metrics(c *gin.Context) {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+h.token)) != 1 {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	c.Header("Content-Type", metrics.ContentType)
+	c.Status(http.StatusOK)
+	if err := h.registry.Write(c.Writer); err != nil {
+		h.log.Warnf("Writing metrics failed, error: %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+const (
+	// oidcStateCookie binds the login flow to the browser that started it
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+type OIDCHandler interface {
+	Login(c *gin.Context)
+	Callback(c *gin.Context)
+}
+
+// oidcHandler handles OpenID Connect login requests
+type oidcHandler struct {
+	oidcService service.OIDCService
+	authManager *auth.AuthManager
+	log         *logger.Logger
+}
+
+// NewOIDCHandler creates a new OIDCHandler instance
+func NewOIDCHandler(oidcService service.OIDCService, authManager *auth.AuthManager, log *logger.Logger) OIDCHandler {
+	return &oidcHandler{
+		oidcService: oidcService,
+		authManager: authManager,
+		log:         log,
+	}
+}
+
+// Login redirects the user to the provider's authorization endpoint
+func (h *oidcHandler) Login(c *gin.Context) {
+	provider := c.Param("provider")
+
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context(), provider)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownProvider) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		h.log.Warnf("Starting oidc login failed for provider: %s, error: %v", provider, err)
+		utils.ErrorResponse(c, http.StatusBadGateway, "Failed to start login")
+		return
+	}
+
+	h.setStateCookie(c, state, int(service.OIDCFlowTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the login when the provider redirects the user back
+func (h *oidcHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.log.Warnf("Oidc login rejected by provider: %s, error: %s", provider, providerErr)
+		utils.ErrorResponse(c, http.StatusUnauthorized, "login was not approved")
+		return
+	}
+
+	state := c.Query("state")
+	cookieState, err := c.Cookie(oidcStateCookie)
+	h.setStateCookie(c, "", -1)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		utils.ErrorResponse(c, http.StatusBadRequest, domain.ErrInvalidOIDCState.Error())
+		return
+	}
+
+	accessToken, refreshToken, err := h.oidcService.CompleteLogin(c.Request.Context(), provider, state, c.Query("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownProvider):
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, domain.ErrInvalidOIDCState):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrUnverifiedEmail), errors.Is(err, domain.ErrAccountDisabled):
+			utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+		default:
+			h.log.Warnf("Completing oidc login failed for provider: %s, error: %v", provider, err)
+			utils.ErrorResponse(c, http.StatusUnauthorized, "login failed")
+		}
+		return
+	}
+
+	h.authManager.SetTokenCookies(c.Writer, accessToken, refreshToken)
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"accessToken":  accessToken,
+		"refreshToken": refreshToken,
+	})
+}
+
+// setStateCookie stores the state for the callback. SameSite=Lax is required since the
+// callback is a top level navigation coming from the provider.
+func (h *oidcHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.authManager.Config.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
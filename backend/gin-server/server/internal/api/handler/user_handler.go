@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/response"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type UserHandler interface {
+	ChangeRole(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RequestEmailChange(c *gin.Context)
+	ConfirmEmailChange(c *gin.Context)
+	DeleteMe(c *gin.Context)
+
+	// Admin user management
+	ListUsers(c *gin.Context)
+	GetUser(c *gin.Context)
+	DisableUser(c *gin.Context)
+	EnableUser(c *gin.Context)
+	LogoutUser(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	DeleteUser(c *gin.Context)
+}
+
+// userHandler handles user management requests
+type userHandler struct {
+	userService service.UserService
+	authManager *auth.AuthManager
+	log         *logger.Logger
+}
+
+// NewUserHandler creates a new UserHandler instance
+func NewUserHandler(userService service.UserService, authManager *auth.AuthManager, log *logger.Logger) UserHandler {
+	return &userHandler{
+		userService: userService,
+		authManager: authManager,
+		log:         log,
+	}
+}
+
+// ChangeRole assigns a new role to a user
+func (h *userHandler) ChangeRole(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	userID := c.Param("id")
+	user, err := h.userService.ChangeRole(c.Request.Context(), claims.EntityID, userID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidRole), errors.Is(err, domain.ErrCannotChangeOwnRole):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, domain.ErrUserNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+		default:
+			h.log.Warnf("Changing role failed for userID: %s, error: %v", userID, err)
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to change role")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewUserPublic(user))
+}
+
+// UpdateMe updates the profile of the current user
+func (h *userHandler) UpdateMe(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), claims.EntityID, req.Username)
+	if err != nil {
+		h.respondError(c, claims.EntityID, "Updating profile", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewUserPublic(user))
+}
+
+// ChangePassword replaces the password of the current user. Other sessions are logged out and
+// the caller gets new tokens, unless it authenticated with a personal access token.
+func (h *userHandler) ChangePassword(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	accessToken, refreshToken, err := h.userService.ChangePassword(c.Request.Context(), claims.EntityID, req.CurrentPassword, req.NewPassword, c.ClientIP())
+	if err != nil {
+		h.respondError(c, claims.EntityID, "Changing password", err)
+		return
+	}
+
+	// A personal access token must not turn into a session
+	if claims.TokenType == auth.PersonalAccessToken {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	h.authManager.SetTokenCookies(c.Writer, accessToken, refreshToken)
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"accessToken":  accessToken,
+		"refreshToken": refreshToken,
+	})
+}
+
+// RequestEmailChange sends a confirmation link to the new email of the current user
+func (h *userHandler) RequestEmailChange(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	if err := h.userService.RequestEmailChange(c.Request.Context(), claims.EntityID, req.Email, req.Password, c.ClientIP()); err != nil {
+		h.respondError(c, claims.EntityID, "Requesting email change", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, utils.Response{Message: "Confirmation link sent to the new email address"})
+}
+
+// ConfirmEmailChange applies an email change with the token from the confirmation link. Existing
+// sessions are logged out and new tokens returned.
+func (h *userHandler) ConfirmEmailChange(c *gin.Context) {
+	var req request.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.userService.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		h.respondError(c, "", "Confirming email change", err)
+		return
+	}
+
+	h.authManager.SetTokenCookies(c.Writer, accessToken, refreshToken)
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"user":         response.NewUserPublic(user),
+		"accessToken":  accessToken,
+		"refreshToken": refreshToken,
+	})
+}
+
+// DeleteMe deletes the account of the current user and all of their data. The current password
+// is required and personal access tokens are rejected.
+func (h *userHandler) DeleteMe(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req request.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c.Request.Context(), claims, req.Password, c.ClientIP()); err != nil {
+		h.respondError(c, claims.EntityID, "Deleting account", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListUsers returns a page of users, optionally filtered by email or username
+func (h *userHandler) ListUsers(c *gin.Context) {
+	var req request.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	list, err := h.userService.ListUsers(c.Request.Context(), service.UserListQuery{
+		Search:   req.Search,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		h.log.Warnf("Listing users failed, error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewAdminUserList(list))
+}
+
+// GetUser returns a user with a summary of their data
+func (h *userHandler) GetUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	details, err := h.userService.GetUserDetails(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, userID, "Getting user", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewAdminUserDetails(details))
+}
+
+// DisableUser blocks a user from logging in and rejects their existing tokens
+func (h *userHandler) DisableUser(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// EnableUser lets a disabled user log in again
+func (h *userHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+func (h *userHandler) setDisabled(c *gin.Context, disabled bool) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID := c.Param("id")
+	user, err := h.userService.SetDisabled(c.Request.Context(), claims.EntityID, userID, disabled)
+	if err != nil {
+		h.respondError(c, userID, "Changing user status", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response.NewAdminUser(user))
+}
+
+// LogoutUser revokes every session of a user
+func (h *userHandler) LogoutUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	if err := h.userService.RevokeSessions(c.Request.Context(), userID); err != nil {
+		h.respondError(c, userID, "Revoking sessions", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ResetPassword sets a new password for a user and logs them out everywhere
+func (h *userHandler) ResetPassword(c *gin.Context) {
+	var req request.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	userID := c.Param("id")
+	if err := h.userService.ResetPassword(c.Request.Context(), userID, req.Password); err != nil {
+		h.respondError(c, userID, "Resetting password", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteUser deletes a user's account and data
+func (h *userHandler) DeleteUser(c *gin.Context) {
+	claims, exists := utils.GetClaimsFromGinContext(c, h.authManager)
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	userID := c.Param("id")
+	if err := h.userService.DeleteUser(c.Request.Context(), claims.EntityID, userID); err != nil {
+		h.respondError(c, userID, "Deleting user", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps user service errors to responses
+func (h *userHandler) respondError(c *gin.Context, userID, action string, err error) {
+	var policyErr *domain.PasswordPolicyError
+	var lockedErr *domain.LoginLockedError
+	switch {
+	case errors.As(err, &policyErr):
+		utils.ValidationErrorResponse(c, http.StatusBadRequest, "password does not meet the policy", policyErr.Violations)
+	case errors.As(err, &lockedErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		utils.ErrorResponse(c, http.StatusTooManyRequests, domain.ErrTooManyLoginAttempts.Error())
+	case errors.Is(err, domain.ErrSessionRequired):
+		utils.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrInvalidUsername), errors.Is(err, domain.ErrInvalidEmailChangeToken), errors.Is(err, domain.ErrCannotManageSelf):
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		utils.ErrorResponse(c, http.StatusForbidden, "current password is incorrect")
+	case errors.Is(err, domain.ErrUserNotFound):
+		utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrUsernameTaken), errors.Is(err, domain.ErrEmailAlreadyInUse):
+		utils.ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		h.log.Warnf("%s failed for userID: %s, error: %v", action, userID, err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, action+" failed")
+	}
+}
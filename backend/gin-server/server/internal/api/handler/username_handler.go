@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/dto/request"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type UsernameHandler interface {
+	Suggest(c *gin.Context)
+}
+
+// usernameHandler suggests generated usernames
+type usernameHandler struct {
+	usernameService service.UsernameService
+	log             *logger.Logger
+}
+
+// NewUsernameHandler creates a new UsernameHandler instance
+func NewUsernameHandler(usernameService service.UsernameService, log *logger.Logger) UsernameHandler {
+	return &usernameHandler{
+		usernameService: usernameService,
+		log:             log,
+	}
+}
+
+// Suggest returns usernames that are not taken, the count is capped by the service
+func (h *usernameHandler) Suggest(c *gin.Context) {
+	var req request.UsernameSuggestRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	usernames, err := h.usernameService.Suggest(c.Request.Context(), req.Count)
+	if err != nil {
+		h.log.Warnf("Suggesting usernames failed, error: %v", err)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to suggest usernames")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"usernames": usernames})
+}
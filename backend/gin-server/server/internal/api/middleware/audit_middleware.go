@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type auditMiddleware struct {
+	log         *logger.Logger
+	authManager *auth.AuthManager
+	recorder    audit.Recorder
+}
+
+func NewAuditMiddleware(log *logger.Logger, authManager *auth.AuthManager, recorder audit.Recorder) AuditMiddleware {
+	return &auditMiddleware{
+		log:         log,
+		authManager: authManager,
+		recorder:    recorder,
+	}
+}
+
+// AuditContext attaches the client IP and user agent to the request context, so events recorded
+// by services during the request carry them
+func (m *auditMiddleware) AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(audit.WithRequest(c.Request.Context(), c.ClientIP(), c.Request.UserAgent()))
+		c.Next()
+	}
+}
+
+// Audit records the action once the handler has run, with the authenticated user as actor and the
+// ":id" route parameter as target. Rejected requests are recorded as failures.
+func (m *auditMiddleware) Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		claims, exists := utils.GetClaimsFromGinContext(c, m.authManager)
+		if !exists {
+			return
+		}
+
+		status := c.Writer.Status()
+		event := &audit.Event{
+			ActorID:   claims.EntityID,
+			Action:    action,
+			TargetID:  c.Param("id"),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Outcome:   audit.OutcomeSuccess,
+		}
+		if status >= http.StatusBadRequest {
+			event.Outcome = audit.OutcomeFailure
+		}
+		_ = event.SetMetadata(map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": status,
+		})
+
+		// The response is already written, a failed record can only be logged
+		if err := m.recorder.Record(c.Request.Context(), event); err != nil {
+			m.log.Errorf("Recording audit event failed, action: %s, actorID: %s, targetID: %s, error: %v", action, event.ActorID, event.TargetID, err)
+		}
+	}
+}
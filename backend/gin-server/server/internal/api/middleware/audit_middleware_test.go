@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRecorder keeps recorded audit events in memory
+type memoryRecorder struct {
+	events []audit.Event
+}
+
+func (r *memoryRecorder) Record(ctx context.Context, event *audit.Event) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func TestAudit_RecordsActorTargetAndOutcome(t *testing.T) {
+	config := auth.DefaultConfig()
+	config.AccessSecret = "test-access-secret"
+	authManager := auth.NewAuthManager(config)
+	recorder := &memoryRecorder{}
+
+	authM := NewAuthMiddleware(logger.New(), authManager, &fakeAPITokenService{}, &fakeSessionValidator{}, nil)
+	auditM := NewAuditMiddleware(logger.New(), authManager, recorder)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(authM.Authenticate())
+	router.POST("/users/:id/disable", auditM.Audit(audit.ActionAdminUserDisable), func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	token, err := authManager.GenerateToken("admin-1", auth.AccessToken, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, performPathRequest(router, http.MethodPost, "/users/user-1/disable", token))
+	assert.Equal(t, http.StatusNotFound, performPathRequest(router, http.MethodPost, "/users/missing/disable", token))
+	// Unauthenticated requests never reach the action
+	assert.Equal(t, http.StatusUnauthorized, performPathRequest(router, http.MethodPost, "/users/user-1/disable", ""))
+
+	require.Len(t, recorder.events, 2)
+
+	event := recorder.events[0]
+	assert.Equal(t, "admin-1", event.ActorID)
+	assert.Equal(t, audit.ActionAdminUserDisable, event.Action)
+	assert.Equal(t, "user-1", event.TargetID)
+	assert.Equal(t, audit.OutcomeSuccess, event.Outcome)
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(event.Metadata), &metadata))
+	assert.Equal(t, "/users/user-1/disable", metadata["path"])
+
+	assert.Equal(t, "missing", recorder.events[1].TargetID)
+	assert.Equal(t, audit.OutcomeFailure, recorder.events[1].Outcome)
+}
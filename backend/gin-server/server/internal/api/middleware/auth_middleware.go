@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
 )
 
@@ -14,14 +18,20 @@ const (
 )
 
 type authMiddleware struct {
-	log         *logger.Logger
-	authManager *auth.AuthManager
+	log             *logger.Logger
+	authManager     *auth.AuthManager
+	apiTokenService service.APITokenService
+	sessions        service.SessionValidator
+	roles           *auth.RoleRegistry
 }
 
-func NewAuthMiddleware(log *logger.Logger, authManager *auth.AuthManager) AuthMiddleware {
+func NewAuthMiddleware(log *logger.Logger, authManager *auth.AuthManager, apiTokenService service.APITokenService, sessions service.SessionValidator, roles *auth.RoleRegistry) AuthMiddleware {
 	return &authMiddleware{
-		log:         log,
-		authManager: authManager,
+		log:             log,
+		authManager:     authManager,
+		apiTokenService: apiTokenService,
+		sessions:        sessions,
+		roles:           roles,
 	}
 }
 
@@ -34,8 +44,13 @@ func (m *authMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		// Parse and validate token
-		claims, err := m.authManager.ParseToken(tokenString, auth.AccessToken)
+		// Personal access tokens are opaque and resolved through the database, everything else is a JWT
+		var claims *auth.CustomClaims
+		if strings.HasPrefix(tokenString, domain.APITokenPrefix) {
+			claims, err = m.apiTokenService.Authenticate(c.Request.Context(), tokenString)
+		} else {
+			claims, err = m.authManager.ParseToken(tokenString, auth.AccessToken)
+		}
 		if err != nil {
 			if err == auth.ErrExpiredToken {
 				utils.ErrorResponse(c, http.StatusUnauthorized, "token expired")
@@ -46,8 +61,30 @@ func (m *authMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
-		// Set claims in Gin context
+		// Tokens stay valid until they expire, so disabled users and revoked sessions are checked on every request
+		user, err := m.sessions.ValidateSession(c.Request.Context(), claims)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrAccountDisabled):
+				utils.ErrorResponseWithAbort(c, http.StatusForbidden, err.Error())
+			case errors.Is(err, domain.ErrSessionRevoked), errors.Is(err, domain.ErrUserNotFound):
+				utils.ErrorResponseWithAbort(c, http.StatusUnauthorized, "invalid token")
+			default:
+				m.log.Warnf("Validating session failed for userID: %s, error: %v", claims.EntityID, err)
+				utils.ErrorResponseWithAbort(c, http.StatusInternalServerError, "Failed to authenticate")
+			}
+			return
+		}
+
+		// Roles can change while a token is valid, so permissions follow the stored user
+		if claims.Custom == nil {
+			claims.Custom = make(map[string]interface{})
+		}
+		claims.Custom[UserRoleKey] = user.Role
+
+		// Set claims in Gin context, scopes are exposed through claims.Scopes
 		c.Set(m.authManager.Config.IdentityKey, claims)
+		addLogFields(c, m.log, map[string]interface{}{logger.FieldUserID: claims.EntityID})
 
 		c.Next()
 	}
@@ -71,3 +108,46 @@ func (m *authMiddleware) Authorize(roles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireScope rejects requests whose token was not granted every given scope.
+// Session tokens are unrestricted, so this only limits personal access tokens.
+func (m *authMiddleware) RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := utils.GetClaimsFromGinContext(c, m.authManager)
+		if !exists {
+			utils.ErrorResponseWithAbort(c, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		for _, scope := range scopes {
+			if !claims.HasScope(scope) {
+				utils.ErrorResponseWithAbort(c, http.StatusForbidden, "forbidden: missing scope "+scope)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequirePermission rejects requests unless the user's role grants every given permission.
+// Personal access tokens are additionally limited to their scopes.
+func (m *authMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := utils.GetClaimsFromGinContext(c, m.authManager)
+		if !exists {
+			utils.ErrorResponseWithAbort(c, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		roles := claims.Roles(UserRoleKey)
+		for _, permission := range permissions {
+			if !m.roles.Can(roles, permission) || !claims.HasScope(permission) {
+				utils.ErrorResponseWithAbort(c, http.StatusForbidden, "forbidden: missing permission "+permission)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIToken = domain.APITokenPrefix + "test-token"
+
+// fakeAPITokenService resolves a single known personal access token
+type fakeAPITokenService struct {
+	scopes []string
+}
+
+func (s *fakeAPITokenService) Create(ctx context.Context, caller *auth.CustomClaims, name string, scopes []string, expiresIn time.Duration) (string, *domain.APIToken, error) {
+	return "", nil, nil
+}
+
+func (s *fakeAPITokenService) List(ctx context.Context, userID string) ([]domain.APIToken, error) {
+	return nil, nil
+}
+
+func (s *fakeAPITokenService) Revoke(ctx context.Context, userID, tokenID string) error {
+	return nil
+}
+
+func (s *fakeAPITokenService) Authenticate(ctx context.Context, token string) (*auth.CustomClaims, error) {
+	if token != testAPIToken {
+		return nil, domain.ErrInvalidAPIToken
+	}
+	return &auth.CustomClaims{
+		TokenType: auth.PersonalAccessToken,
+		EntityID:  "user-1",
+		Scopes:    s.scopes,
+		Custom:    map[string]interface{}{UserRoleKey: domain.UserRoleAdmin},
+	}, nil
+}
+
+// fakeSessionValidator rejects the sessions of the listed users. Users have the stored role
+// listed in roles, or the role of their token.
+type fakeSessionValidator struct {
+	rejected map[string]error
+	roles    map[string]string
+}
+
+func (v *fakeSessionValidator) ValidateSession(ctx context.Context, claims *auth.CustomClaims) (*domain.User, error) {
+	if err := v.rejected[claims.EntityID]; err != nil {
+		return nil, err
+	}
+
+	role, ok := v.roles[claims.EntityID]
+	if !ok {
+		role = strings.Join(claims.Roles(UserRoleKey), ",")
+	}
+	return &domain.User{ID: claims.EntityID, Role: role}, nil
+}
+
+func setupAuthTest(scopes []string) (*gin.Engine, *auth.AuthManager) {
+	return setupAuthTestWithSessions(scopes, &fakeSessionValidator{})
+}
+
+func setupAuthTestWithSessions(scopes []string, sessions *fakeSessionValidator) (*gin.Engine, *auth.AuthManager) {
+	config := auth.DefaultConfig()
+	config.AccessSecret = "test-access-secret"
+	config.RefreshSecret = "test-refresh-secret"
+	authManager := auth.NewAuthManager(config)
+
+	m := NewAuthMiddleware(logger.New(), authManager, &fakeAPITokenService{scopes: scopes}, sessions, domain.NewRoleRegistry())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.Authenticate())
+	router.GET("/reminders", m.RequireScope(domain.ScopeRemindersRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.POST("/reminders", m.RequireScope(domain.ScopeRemindersWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/admin/users", m.RequirePermission(domain.PermissionUsersManage), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/admin/stats", m.Authorize(domain.UserRoleAdmin), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+
+	return router, authManager
+}
+
+func performAuthRequest(router *gin.Engine, method, token string) int {
+	return performPathRequest(router, method, "/reminders", token)
+}
+
+func performPathRequest(router *gin.Engine, method, path, token string) int {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAuthenticate_APIToken(t *testing.T) {
+	router, _ := setupAuthTest([]string{domain.ScopeRemindersRead})
+
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodGet, testAPIToken))
+	assert.Equal(t, http.StatusUnauthorized, performAuthRequest(router, http.MethodGet, domain.APITokenPrefix+"unknown"))
+	assert.Equal(t, http.StatusUnauthorized, performAuthRequest(router, http.MethodGet, ""))
+}
+
+func TestAuthenticate_RejectsInvalidSessions(t *testing.T) {
+	router, authManager := setupAuthTestWithSessions(nil, &fakeSessionValidator{rejected: map[string]error{
+		"disabled-user": domain.ErrAccountDisabled,
+		"revoked-user":  domain.ErrSessionRevoked,
+		"deleted-user":  domain.ErrUserNotFound,
+	}})
+
+	for userID, status := range map[string]int{
+		"user-1":        http.StatusOK,
+		"disabled-user": http.StatusForbidden,
+		"revoked-user":  http.StatusUnauthorized,
+		"deleted-user":  http.StatusUnauthorized,
+	} {
+		accessToken, err := authManager.GenerateToken(userID, auth.AccessToken, nil)
+		require.NoError(t, err)
+		assert.Equal(t, status, performAuthRequest(router, http.MethodGet, accessToken), userID)
+	}
+}
+
+func TestRequireScope_APIToken(t *testing.T) {
+	router, _ := setupAuthTest([]string{domain.ScopeRemindersRead})
+
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodGet, testAPIToken), "Granted scope should be allowed")
+	assert.Equal(t, http.StatusForbidden, performAuthRequest(router, http.MethodPost, testAPIToken), "Missing scope should be rejected")
+}
+
+func TestRequireScope_WildcardAPIToken(t *testing.T) {
+	router, _ := setupAuthTest([]string{"reminders:*"})
+
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodGet, testAPIToken))
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodPost, testAPIToken))
+}
+
+func TestRequireScope_SessionTokenIsUnrestricted(t *testing.T) {
+	router, authManager := setupAuthTest(nil)
+
+	accessToken, err := authManager.GenerateToken("user-1", auth.AccessToken, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodGet, accessToken))
+	assert.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodPost, accessToken))
+}
+
+func TestRequirePermission_Roles(t *testing.T) {
+	router, authManager := setupAuthTest(nil)
+
+	userToken, err := authManager.GenerateToken("user-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleUser})
+	require.NoError(t, err)
+	adminToken, err := authManager.GenerateToken("admin-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleAdmin})
+	require.NoError(t, err)
+	noRoleToken, err := authManager.GenerateToken("user-2", auth.AccessToken, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/users", userToken))
+	assert.Equal(t, http.StatusOK, performPathRequest(router, http.MethodGet, "/admin/users", adminToken))
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/users", noRoleToken))
+}
+
+func TestRequirePermission_FollowsStoredRole(t *testing.T) {
+	router, authManager := setupAuthTestWithSessions(nil, &fakeSessionValidator{roles: map[string]string{
+		"demoted-1":  domain.UserRoleUser,
+		"promoted-1": domain.UserRoleAdmin,
+	}})
+
+	// The role changed after the tokens were issued
+	demotedToken, err := authManager.GenerateToken("demoted-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleAdmin})
+	require.NoError(t, err)
+	promotedToken, err := authManager.GenerateToken("promoted-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleUser})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/users", demotedToken))
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/stats", demotedToken))
+	assert.Equal(t, http.StatusOK, performPathRequest(router, http.MethodGet, "/admin/users", promotedToken))
+}
+
+func TestRequirePermission_APITokenLimitedByScopes(t *testing.T) {
+	// The token owner is an admin but the token was only granted read access to reminders
+	router, _ := setupAuthTest([]string{domain.ScopeRemindersRead})
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/users", testAPIToken))
+
+	router, _ = setupAuthTest([]string{domain.PermissionUsersManage})
+	assert.Equal(t, http.StatusOK, performPathRequest(router, http.MethodGet, "/admin/users", testAPIToken))
+}
+
+func TestAuthorize_SingleRoleClaim(t *testing.T) {
+	router, authManager := setupAuthTest(nil)
+
+	userToken, err := authManager.GenerateToken("user-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleUser})
+	require.NoError(t, err)
+	adminToken, err := authManager.GenerateToken("admin-1", auth.AccessToken, map[string]interface{}{UserRoleKey: domain.UserRoleAdmin})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, performPathRequest(router, http.MethodGet, "/admin/stats", userToken))
+	assert.Equal(t, http.StatusOK, performPathRequest(router, http.MethodGet, "/admin/stats", adminToken))
+}
+
+func TestAuthenticate_AddsUserToRequestLogger(t *testing.T) {
+	dest := logger.NewRingBufferDestination(0)
+	log := logger.New()
+	log.AddDestination("test", dest)
+	log.SetDefaultDestinations("test")
+
+	m := NewAuthMiddleware(log, auth.NewAuthManager(auth.DefaultConfig()), &fakeAPITokenService{}, &fakeSessionValidator{}, domain.NewRoleRegistry())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRequestIDMiddleware(log).RequestID(), m.Authenticate())
+	router.GET("/reminders", func(c *gin.Context) {
+		logger.FromContext(c.Request.Context()).Infof("Listing reminders")
+		c.Status(http.StatusOK)
+	})
+
+	require.Equal(t, http.StatusOK, performAuthRequest(router, http.MethodGet, testAPIToken))
+
+	entries := dest.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "user-1", entries[0].Fields[logger.FieldUserID])
+	assert.NotEmpty(t, entries[0].Fields[logger.FieldRequestID])
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+type csrfMiddleware struct {
+	log         *logger.Logger
+	authManager *auth.AuthManager
+}
+
+func NewCSRFMiddleware(log *logger.Logger, authManager *auth.AuthManager) CSRFMiddleware {
+	return &csrfMiddleware{
+		log:         log,
+		authManager: authManager,
+	}
+}
+
+// CSRF rejects state-changing requests authenticated by cookie unless they echo the CSRF
+// cookie in the CSRF header. Requests sending their token in a header can't be forged by
+// other sites and pass unchecked, as does everything when cookie mode is off.
+func (m *csrfMiddleware) CSRF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.authManager.Config.SendCookies || isSafeMethod(c.Request.Method) || !m.usesCookies(c.Request) {
+			c.Next()
+			return
+		}
+
+		if err := m.authManager.VerifyCSRF(c.Request); err != nil {
+			m.log.Warnf("Rejected request without valid CSRF token, method: %s, path: %s, clientIP: %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			utils.ErrorResponseWithAbort(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// usesCookies reports whether the request is authenticated by a token cookie, either the access
+// token or, when no token is sent at all, the refresh token read by refresh and logout
+func (m *csrfMiddleware) usesCookies(r *http.Request) bool {
+	if m.authManager.TokenFromCookie(r) {
+		return true
+	}
+	if _, err := m.authManager.ExtractTokenFromRequest(r); err != auth.ErrMissingToken {
+		return false
+	}
+	_, err := m.authManager.ExtractRefreshTokenFromRequest(r)
+	return err == nil
+}
+
+// isSafeMethod reports whether the method is read-only by definition
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
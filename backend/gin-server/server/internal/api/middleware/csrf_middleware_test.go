@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCSRFTest(sendCookies bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	config := auth.DefaultConfig()
+	config.SendCookies = sendCookies
+	config.TokenLookup = "header:Authorization,cookie:" + config.AccessCookieName
+	authManager := auth.NewAuthManager(config)
+
+	r := gin.New()
+	r.Use(NewCSRFMiddleware(logger.New(), authManager).CSRF())
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/resource", handler)
+	r.POST("/resource", handler)
+
+	return r
+}
+
+func TestCSRF(t *testing.T) {
+	const csrfToken = "csrf-token"
+
+	tests := []struct {
+		name        string
+		sendCookies bool
+		method      string
+		cookies     map[string]string
+		headers     map[string]string
+		wantStatus  int
+	}{
+		{
+			name:        "cookie mode off",
+			sendCookies: false,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_access_token": "token"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "safe method",
+			sendCookies: true,
+			method:      http.MethodGet,
+			cookies:     map[string]string{"jwt_access_token": "token"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "no credentials",
+			sendCookies: true,
+			method:      http.MethodPost,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "access cookie without csrf header",
+			sendCookies: true,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_access_token": "token", "csrf_token": csrfToken},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "access cookie with wrong csrf header",
+			sendCookies: true,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_access_token": "token", "csrf_token": csrfToken},
+			headers:     map[string]string{"X-CSRF-Token": "forged"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "access cookie with matching csrf header",
+			sendCookies: true,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_access_token": "token", "csrf_token": csrfToken},
+			headers:     map[string]string{"X-CSRF-Token": csrfToken},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "refresh cookie only",
+			sendCookies: true,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_refresh_token": "token"},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "bearer header with cookies",
+			sendCookies: true,
+			method:      http.MethodPost,
+			cookies:     map[string]string{"jwt_access_token": "token", "jwt_refresh_token": "token"},
+			headers:     map[string]string{"Authorization": "Bearer token"},
+			wantStatus:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupCSRFTest(tt.sendCookies)
+
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
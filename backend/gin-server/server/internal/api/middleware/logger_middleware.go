@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +18,8 @@ func NewLoggerMiddleware(log *logger.Logger) LoggerMiddleware {
 	}
 }
 
+// Logger writes an access log entry for every request, with the fields of the request scoped
+// logger. Server errors are logged as errors and client errors as warnings.
 func (m *loggerMiddleware) Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -25,24 +28,32 @@ func (m *loggerMiddleware) Logger() gin.HandlerFunc {
 
 		c.Next()
 
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
 		statusCode := c.Writer.Status()
-		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
-
+		fields := map[string]interface{}{
+			"status":     statusCode,
+			"method":     c.Request.Method,
+			"path":       path,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+			"bytes":      c.Writer.Size(),
+		}
 		if query != "" {
-			path = path + "?" + query
+			fields["query"] = query
+		}
+		if errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String(); errorMessage != "" {
+			fields["errors"] = errorMessage
 		}
 
-		m.log.Infof("[GIN] %s | %3d | %13v | %15s | %-7s %s %s",
-			start.Format("2006/01/02 - 15:04:05"),
-			statusCode,
-			latency,
-			clientIP,
-			method,
-			path,
-			errorMessage,
-		)
+		// Downstream middlewares such as Authenticate add to the request scoped logger
+		log := m.log.WithContext(c.Request.Context())
+		switch {
+		case statusCode >= http.StatusInternalServerError:
+			log.Error("HTTP request", fields)
+		case statusCode >= http.StatusBadRequest:
+			log.Warn("HTTP request", fields)
+		default:
+			log.Info("HTTP request", fields)
+		}
 	}
 }
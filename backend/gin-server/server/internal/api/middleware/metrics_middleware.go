@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
+)
+
+// unmatchedRoute labels the requests matching no route, so unknown paths don't each create a series
+const unmatchedRoute = "unmatched"
+
+type metricsMiddleware struct {
+	metrics *metrics.Metrics
+}
+
+func NewMetricsMiddleware(m *metrics.Metrics) MetricsMiddleware {
+	return &metricsMiddleware{
+		metrics: m,
+	}
+}
+
+// Metrics records the duration of every request by route template and status, and the requests
+// being served. It must run first so the duration covers the other middlewares.
+func (m *metricsMiddleware) Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.metrics.HTTPRequestsInFlight.Inc()
+		defer m.metrics.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.metrics.HTTPRequestDuration.
+			With(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewMetricsMiddleware(m).Metrics())
+	router.GET("/reminders/:id", func(c *gin.Context) {
+		assert.Equal(t, 1.0, m.HTTPRequestsInFlight.Value())
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/reminders/1", "/reminders/2", "/unknown/1", "/unknown/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Requests are grouped by route template, whatever their path
+	count, ok := m.Registry.Value("http_request_duration_seconds", http.MethodGet, "/reminders/:id", "200")
+	require.True(t, ok)
+	assert.Equal(t, 2.0, count)
+	count, ok = m.Registry.Value("http_request_duration_seconds", http.MethodGet, unmatchedRoute, "404")
+	require.True(t, ok)
+	assert.Equal(t, 2.0, count)
+	assert.Zero(t, m.HTTPRequestsInFlight.Value())
+}
@@ -1,25 +1,49 @@
 package middleware
 
 import (
-	"time"
-
 	"github.com/gin-gonic/gin"
 
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
 )
 
 type Middleware interface {
+	RequestID() gin.HandlerFunc
 	Authenticate() gin.HandlerFunc
 	Authorize(roles ...string) gin.HandlerFunc
+	RequireScope(scopes ...string) gin.HandlerFunc
+	RequirePermission(permissions ...string) gin.HandlerFunc
+	CSRF() gin.HandlerFunc
+	AuditContext() gin.HandlerFunc
+	Audit(action string) gin.HandlerFunc
 	Logger() gin.HandlerFunc
 	Recovery() gin.HandlerFunc
-	RateLimiter() gin.HandlerFunc
+	RateLimit(tier string) gin.HandlerFunc
+	Metrics() gin.HandlerFunc
 }
 
 type AuthMiddleware interface {
 	Authenticate() gin.HandlerFunc
 	Authorize(roles ...string) gin.HandlerFunc
+	RequireScope(scopes ...string) gin.HandlerFunc
+	RequirePermission(permissions ...string) gin.HandlerFunc
+}
+
+type CSRFMiddleware interface {
+	CSRF() gin.HandlerFunc
+}
+
+type AuditMiddleware interface {
+	AuditContext() gin.HandlerFunc
+	Audit(action string) gin.HandlerFunc
+}
+
+type RequestIDMiddleware interface {
+	RequestID() gin.HandlerFunc
 }
 
 type LoggerMiddleware interface {
@@ -27,25 +51,37 @@ type LoggerMiddleware interface {
 }
 
 type RateLimiterMiddleware interface {
-	RateLimiter() gin.HandlerFunc
+	RateLimit(tier string) gin.HandlerFunc
 }
 
 type RecoveryMiddleware interface {
 	Recovery() gin.HandlerFunc
 }
 
+type MetricsMiddleware interface {
+	Metrics() gin.HandlerFunc
+}
+
 type middleware struct {
+	requestIDMiddleware
 	authMiddleware
+	csrfMiddleware
+	auditMiddleware
 	loggerMiddleware
 	recoveryMiddleware
 	rateLimiterMiddleware
+	metricsMiddleware
 }
 
-func NewMiddleware(log *logger.Logger, authManager *auth.AuthManager) Middleware {
+func NewMiddleware(log *logger.Logger, authManager *auth.AuthManager, apiTokenService service.APITokenService, sessions service.SessionValidator, roles *auth.RoleRegistry, recorder audit.Recorder, limiter *ratelimit.Limiter, m *metrics.Metrics) Middleware {
 	return &middleware{
-		authMiddleware:        authMiddleware{log: log, authManager: authManager},
+		requestIDMiddleware:   requestIDMiddleware{log: log},
+		authMiddleware:        authMiddleware{log: log, authManager: authManager, apiTokenService: apiTokenService, sessions: sessions, roles: roles},
+		csrfMiddleware:        csrfMiddleware{log: log, authManager: authManager},
+		auditMiddleware:       auditMiddleware{log: log, authManager: authManager, recorder: recorder},
 		loggerMiddleware:      loggerMiddleware{log: log},
 		recoveryMiddleware:    recoveryMiddleware{log: log},
-		rateLimiterMiddleware: rateLimiterMiddleware{log: log, limit: 100, window: 1 * time.Minute},
+		rateLimiterMiddleware: rateLimiterMiddleware{log: log, authManager: authManager, limiter: limiter},
+		metricsMiddleware:     metricsMiddleware{metrics: m},
 	}
 }
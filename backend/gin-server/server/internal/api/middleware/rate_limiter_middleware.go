@@ -1,65 +1,83 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
 )
 
+// Rate limit headers, see the IETF RateLimit header fields draft
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+)
+
 type rateLimiterMiddleware struct {
-	log     *logger.Logger
-	limit   int
-	window  time.Duration
-	clients map[string][]time.Time
-	mu      sync.Mutex
+	log         *logger.Logger
+	authManager *auth.AuthManager
+	limiter     *ratelimit.Limiter
 }
 
-func NewRateLimiterMiddleware(log *logger.Logger, limit int, window time.Duration) RateLimiterMiddleware {
-	return &rateLimiterMiddleware{
-		log:     log,
-		limit:   limit,
-		window:  window,
-		clients: make(map[string][]time.Time),
-	}
+// NewRateLimiterMiddleware creates a RateLimiterMiddleware, a nil limiter disables rate limiting
+func NewRateLimiterMiddleware(log *logger.Logger, authManager *auth.AuthManager, limiter *ratelimit.Limiter) RateLimiterMiddleware {
+	return &rateLimiterMiddleware{log: log, authManager: authManager, limiter: limiter}
 }
 
-func (m *rateLimiterMiddleware) RateLimiter() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-
-		m.mu.Lock()
-		defer m.mu.Unlock()
-
-		now := time.Now()
-
-		// Create new entry for this client if it doesn't exist
-		if _, exists := m.clients[clientIP]; !exists {
-			m.clients[clientIP] = []time.Time{}
-		}
+// RateLimit limits requests with the given tier. Authenticated requests are limited per user,
+// so it must run after Authenticate on protected routes, other requests per client IP.
+func (m *rateLimiterMiddleware) RateLimit(tier string) gin.HandlerFunc {
+	if m.limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if _, ok := m.limiter.Tier(tier); !ok {
+		m.log.Warnf("Rate limit tier %q is not configured, requests are not limited", tier)
+		return func(c *gin.Context) { c.Next() }
+	}
 
-		// Remove timestamps outside the current window
-		var validRequests []time.Time
-		for _, timestamp := range m.clients[clientIP] {
-			if now.Sub(timestamp) <= m.window {
-				validRequests = append(validRequests, timestamp)
+	return func(c *gin.Context) {
+		result, err := m.limiter.Allow(c.Request.Context(), tier, m.clientKey(c))
+		if err != nil {
+			m.log.Errorf("Rate limiter failed: %v", err)
+			if !result.Allowed {
+				utils.ErrorResponseWithAbort(c, http.StatusServiceUnavailable, "Rate limiter unavailable")
+				return
 			}
+			c.Next()
+			return
 		}
 
-		m.clients[clientIP] = validRequests
+		c.Header(headerRateLimitLimit, strconv.Itoa(result.Limit))
+		c.Header(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Header(headerRateLimitReset, strconv.Itoa(ceilSeconds(result.ResetAfter)))
 
-		// Check if the client has exceeded the limit
-		if len(m.clients[clientIP]) >= m.limit {
+		if !result.Allowed {
+			c.Header(headerRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
 			utils.ErrorResponseWithAbort(c, http.StatusTooManyRequests, "Rate limit exceeded")
 			return
 		}
 
-		// Add current request timestamp
-		m.clients[clientIP] = append(m.clients[clientIP], now)
-
 		c.Next()
 	}
 }
+
+// clientKey identifies the client, by user id when authenticated and by IP otherwise
+func (m *rateLimiterMiddleware) clientKey(c *gin.Context) string {
+	if claims, ok := utils.GetClaimsFromGinContext(c, m.authManager); ok && claims.EntityID != "" {
+		return "user:" + claims.EntityID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as the headers are in seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
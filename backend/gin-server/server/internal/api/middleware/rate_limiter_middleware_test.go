@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,8 +12,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ErrorResponse struct {
@@ -19,55 +24,95 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-func setupRateLimiterTest(limit int, window time.Duration) (*gin.Engine, *rateLimiterMiddleware) {
-	log := logger.New()
-	rl := NewRateLimiterMiddleware(log, limit, window).(*rateLimiterMiddleware)
+// rateLimiterClock is a settable clock shared by the limiter and the test
+type rateLimiterClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *rateLimiterClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *rateLimiterClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// setupRateLimiterTest limits /test with the default tier and /auth with the auth tier.
+// Requests with an X-User header are treated as authenticated by that user.
+func setupRateLimiterTest(t *testing.T, limit int, window time.Duration) (*gin.Engine, *rateLimiterClock) {
+	clock := &rateLimiterClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := ratelimit.NewMemoryStore(0)
+	limiter := ratelimit.NewLimiter(store, []ratelimit.Tier{
+		{Name: ratelimit.TierDefault, Limit: limit, Period: window},
+		{Name: ratelimit.TierAuth, Limit: 1, Period: window},
+	}, ratelimit.WithClock(clock.Now))
+	t.Cleanup(func() { limiter.Close() })
+
+	authManager := auth.NewAuthManager(auth.DefaultConfig())
+	rl := NewRateLimiterMiddleware(logger.New(), authManager, limiter)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(rl.RateLimiter())
-	router.GET("/test", func(c *gin.Context) {
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User"); userID != "" {
+			c.Set(authManager.Config.IdentityKey, &auth.CustomClaims{EntityID: userID})
+		}
+	})
+	router.GET("/test", rl.RateLimit(ratelimit.TierDefault), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/auth", rl.RateLimit(ratelimit.TierAuth), func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "ok"})
 	})
 
-	return router, rl
+	return router, clock
+}
+
+func performRateLimitedRequest(router *gin.Engine, path, remoteAddr, userID string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	req.RemoteAddr = remoteAddr
+	if userID != "" {
+		req.Header.Set("X-User", userID)
+	}
+	router.ServeHTTP(w, req)
+	return w
 }
 
 func TestRateLimiter_AllowedRequestsWithinLimit(t *testing.T) {
 	limit := 5
-	window := time.Minute
-	router, _ := setupRateLimiterTest(limit, window)
+	router, _ := setupRateLimiterTest(t, limit, time.Minute)
 
 	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = "127.0.0.1:1234" // Set a fixed IP for testing
-		router.ServeHTTP(w, req)
+		w := performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
 
 		assert.Equal(t, http.StatusOK, w.Code, "Request should be allowed within limit")
+		assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, fmt.Sprint(limit-i-1), w.Header().Get("RateLimit-Remaining"))
 	}
 }
 
 func TestRateLimiter_BlockedRequestsOverLimit(t *testing.T) {
 	limit := 3
-	window := time.Minute
-	router, _ := setupRateLimiterTest(limit, window)
+	router, _ := setupRateLimiterTest(t, limit, time.Minute)
 
 	// Make requests up to the limit
 	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = "127.0.0.1:1234"
-		router.ServeHTTP(w, req)
+		performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
 	}
 
 	// Make one more request that should be blocked
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = "127.0.0.1:1234"
-	router.ServeHTTP(w, req)
+	w := performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
 
 	assert.Equal(t, http.StatusTooManyRequests, w.Code, "Request should be blocked over limit")
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	// One request is regained every 20 seconds
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
 
 	var response ErrorResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -76,60 +121,64 @@ func TestRateLimiter_BlockedRequestsOverLimit(t *testing.T) {
 }
 
 func TestRateLimiter_DifferentIPAddresses(t *testing.T) {
-	limit := 2
-	window := time.Minute
-	router, _ := setupRateLimiterTest(limit, window)
+	router, _ := setupRateLimiterTest(t, 1, time.Minute)
 
-	// Make requests from different IPs
-	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = fmt.Sprintf("127.0.0.%d:1234", i+1)
-		router.ServeHTTP(w, req)
+	for i := 0; i < 3; i++ {
+		w := performRateLimitedRequest(router, "/test", fmt.Sprintf("127.0.0.%d:1234", i+1), "")
 		assert.Equal(t, http.StatusOK, w.Code, "Requests from different IPs should be allowed")
 	}
 }
 
-func TestRateLimiter_WindowExpiration(t *testing.T) {
+func TestRateLimiter_KeyedByUserWhenAuthenticated(t *testing.T) {
+	router, _ := setupRateLimiterTest(t, 1, time.Minute)
+
+	// Users behind the same IP have their own limits
+	assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "10.0.0.1:1234", "alice").Code)
+	assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "10.0.0.1:1234", "bob").Code)
+	assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "10.0.0.1:1234", "").Code)
+
+	// A user keeps their limit when their IP changes
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimitedRequest(router, "/test", "10.0.0.2:1234", "alice").Code)
+}
+
+func TestRateLimiter_TiersAreIndependent(t *testing.T) {
+	router, _ := setupRateLimiterTest(t, 2, time.Minute)
+
+	assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/auth", "127.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimitedRequest(router, "/auth", "127.0.0.1:1234", "").Code)
+
+	// The default tier has its own bucket
+	w := performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimiter_Refill(t *testing.T) {
 	limit := 2
-	window := 100 * time.Millisecond // Short window for testing
-	router, rl := setupRateLimiterTest(limit, window)
+	window := time.Minute
+	router, clock := setupRateLimiterTest(t, limit, window)
 
-	// Make requests up to the limit
 	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = "127.0.0.1:1234"
-		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
 	}
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
 
-	// Make one more request that should be blocked
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = "127.0.0.1:1234"
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusTooManyRequests, w.Code, "Request should be blocked initially")
-
-	// Wait for the window to expire
-	time.Sleep(window + 10*time.Millisecond)
+	// Tokens are regained one at a time rather than at the end of a window
+	clock.Advance(window / 2)
+	assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
 
-	// Now requests should be allowed again
-	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = "127.0.0.1:1234"
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code, "Request should be allowed after window expires")
-
-	// Verify the client map was cleaned up
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-	assert.Len(t, rl.clients["127.0.0.1"], 1, "Only one timestamp should remain after window expiration")
+	// A full bucket after a whole window
+	clock.Advance(window)
+	for i := 0; i < limit; i++ {
+		assert.Equal(t, http.StatusOK, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code)
 }
 
 func TestRateLimiter_ConcurrentRequests(t *testing.T) {
 	limit := 10
-	window := time.Minute
-	router, _ := setupRateLimiterTest(limit, window)
+	router, _ := setupRateLimiterTest(t, limit, time.Minute)
 
 	var wg sync.WaitGroup
 	results := make(chan int, limit*2)
@@ -138,11 +187,7 @@ func TestRateLimiter_ConcurrentRequests(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			w := httptest.NewRecorder()
-			req, _ := http.NewRequest("GET", "/test", nil)
-			req.RemoteAddr = "127.0.0.1:1234" // All from same IP
-			router.ServeHTTP(w, req)
-			results <- w.Code
+			results <- performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "").Code
 		}()
 	}
 
@@ -164,112 +209,73 @@ func TestRateLimiter_ConcurrentRequests(t *testing.T) {
 }
 
 func TestRateLimiter_ClientIPExtraction(t *testing.T) {
-	limit := 1
-	window := time.Minute
-	router, rl := setupRateLimiterTest(limit, window)
-
-	testCases := []struct {
-		name       string
-		remoteAddr string
-		xForwarded string
-		expectedIP string
-	}{
-		{"Direct IP", "192.168.1.1:1234", "", "192.168.1.1"},
-		{"With X-Forwarded-For", "192.168.1.2:1234", "203.0.113.195, 70.41.3.18", "203.0.113.195"},
-		{"Empty X-Forwarded-For", "192.168.1.3:1234", "", "192.168.1.3"},
-	}
+	router, _ := setupRateLimiterTest(t, 1, time.Minute)
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			w := httptest.NewRecorder()
-			req, _ := http.NewRequest("GET", "/test", nil)
-			req.RemoteAddr = tc.remoteAddr
-			if tc.xForwarded != "" {
-				req.Header.Set("X-Forwarded-For", tc.xForwarded)
-			}
-			router.ServeHTTP(w, req)
-
-			rl.mu.Lock()
-			_, exists := rl.clients[tc.expectedIP]
-			rl.mu.Unlock()
-			assert.True(t, exists, "Client IP should be correctly extracted and stored")
-		})
-	}
-}
-
-func TestRateLimiter_CleanupOldEntries(t *testing.T) {
-	limit := 5
-	window := 100 * time.Millisecond
-	router, rl := setupRateLimiterTest(limit, window)
-
-	// Make initial request
-	w := httptest.NewRecorder()
+	// The first request from the forwarded client exhausts its limit
 	req, _ := http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = "127.0.0.1:1234"
+	req.RemoteAddr = "192.168.1.2:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.195, 70.41.3.18")
+	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
 
-	// Verify entry exists
-	rl.mu.Lock()
-	assert.Len(t, rl.clients["127.0.0.1"], 1)
-	rl.mu.Unlock()
-
-	// Wait for window to expire plus some buffer
-	time.Sleep(window + 10*time.Millisecond)
-
-	// Make another request which should trigger cleanup
-	w = httptest.NewRecorder()
+	// Another proxy forwarding the same client shares its limit
 	req, _ = http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = "127.0.0.1:1234"
+	req.RemoteAddr = "192.168.1.3:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.195")
+	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-
-	// Verify old timestamp was cleaned up
-	rl.mu.Lock()
-	assert.Len(t, rl.clients["127.0.0.1"], 1, "Only the new timestamp should remain")
-	rl.mu.Unlock()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 }
 
-func TestRateLimiter_MultipleWindows(t *testing.T) {
-	limit := 2
-	window := 200 * time.Millisecond
-	router, _ := setupRateLimiterTest(limit, window)
-	ip := "127.0.0.1:1234"
+func TestRateLimiter_Disabled(t *testing.T) {
+	rl := NewRateLimiterMiddleware(logger.New(), auth.NewAuthManager(auth.DefaultConfig()), nil)
 
-	// First window - make exactly 'limit' requests
-	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = ip
-		router.ServeHTTP(w, req)
-		assert.Equal(t, http.StatusOK, w.Code, fmt.Sprintf("Request %d should be allowed", i+1))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/test", rl.RateLimit(ratelimit.TierDefault), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i := 0; i < 5; i++ {
+		w := performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
 	}
+}
 
-	// Verify next request is blocked
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = ip
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusTooManyRequests, w.Code, "Request over limit should be blocked")
+// unavailableStore fails every request, like an unreachable database
+type unavailableStore struct{}
 
-	// Wait for window to fully expire
-	time.Sleep(window + 50*time.Millisecond) // Added more buffer time
+func (unavailableStore) Take(ctx context.Context, key string, tier ratelimit.Tier, now time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
 
-	// After window expires, we should be able to make 'limit' requests again
-	allowedCount := 0
-	for i := 0; i < limit; i++ {
-		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/test", nil)
-		req.RemoteAddr = ip
-		router.ServeHTTP(w, req)
-		if w.Code == http.StatusOK {
-			allowedCount++
-		}
-	}
-	assert.Equal(t, limit, allowedCount, "Should allow full limit after window expires")
+func TestRateLimiter_StoreUnavailable(t *testing.T) {
+	tiers := []ratelimit.Tier{{Name: ratelimit.TierDefault, Limit: 1, Period: time.Minute}}
 
-	// Verify we get blocked again after using the new window's limit
-	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("GET", "/test", nil)
-	req.RemoteAddr = ip
-	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusTooManyRequests, w.Code, "Should block again after using new window's limit")
+	for _, tc := range []struct {
+		name       string
+		failOpen   bool
+		wantStatus int
+	}{
+		{"fail open", true, http.StatusOK},
+		{"fail closed", false, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := ratelimit.NewLimiter(unavailableStore{}, tiers, ratelimit.WithFailOpen(tc.failOpen))
+			rl := NewRateLimiterMiddleware(logger.New(), auth.NewAuthManager(auth.DefaultConfig()), limiter)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/test", rl.RateLimit(ratelimit.TierDefault), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			for i := 0; i < 2; i++ {
+				w := performRateLimitedRequest(router, "/test", "127.0.0.1:1234", "")
+				assert.Equal(t, tc.wantStatus, w.Code)
+			}
+		})
+	}
 }
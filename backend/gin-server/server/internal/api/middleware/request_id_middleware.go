@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+)
+
+const (
+	// RequestIDHeader carries the request id from clients and proxies, and back in responses
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the gin context key of the request id
+	RequestIDKey = "request_id"
+	// maxRequestIDLength bounds incoming request ids, longer ones are replaced
+	maxRequestIDLength = 128
+)
+
+type requestIDMiddleware struct {
+	log *logger.Logger
+}
+
+func NewRequestIDMiddleware(log *logger.Logger) RequestIDMiddleware {
+	return &requestIDMiddleware{log: log}
+}
+
+// RequestID assigns every request an id, reusing a valid X-Request-ID header, and stores a
+// logger carrying the id and route in the request context. It must run before the other middlewares.
+func (m *requestIDMiddleware) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		fields := map[string]interface{}{logger.FieldRequestID: requestID}
+		if route := c.FullPath(); route != "" {
+			fields[logger.FieldRoute] = route
+		}
+		addLogFields(c, m.log, fields)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts ids of printable ASCII characters, so they can't forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// addLogFields adds fields to the request scoped logger, starting from log when there is none yet
+func addLogFields(c *gin.Context, log *logger.Logger, fields map[string]interface{}) {
+	ctx := c.Request.Context()
+	c.Request = c.Request.WithContext(logger.NewContext(ctx, log.WithContext(ctx).WithFields(fields)))
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRequestLoggingTest() (*gin.Engine, *logger.RingBufferDestination) {
+	dest := logger.NewRingBufferDestination(0)
+	log := logger.New(logger.WithServiceName("test"))
+	log.AddDestination("test", dest)
+	log.SetDefaultDestinations("test")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRequestIDMiddleware(log).RequestID(), NewLoggerMiddleware(log).Logger())
+	router.GET("/reminders/:id", func(c *gin.Context) {
+		// Handlers and services log through the request scoped logger
+		logger.FromContext(c.Request.Context()).Infof("Handling %s", c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	return router, dest
+}
+
+func TestRequestID(t *testing.T) {
+	router, _ := setupRequestLoggingTest()
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"incoming", "abc-123", true},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+		{"control characters", "abc\ninjected", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/reminders/1", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			if tt.keep {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				_, err := uuid.Parse(requestID)
+				assert.NoError(t, err, "a new id is generated")
+			}
+		})
+	}
+}
+
+func TestRequestScopedLogging(t *testing.T) {
+	router, dest := setupRequestLoggingTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/reminders/42?full=1", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req.Header.Set("User-Agent", "test-agent")
+	router.ServeHTTP(w, req)
+
+	entries := dest.Entries()
+	require.Len(t, entries, 2)
+
+	// The handler message carries the request fields
+	assert.Equal(t, "Handling 42", entries[0].Message)
+	assert.Equal(t, "req-1", entries[0].Fields[logger.FieldRequestID])
+	assert.Equal(t, "/reminders/:id", entries[0].Fields[logger.FieldRoute])
+
+	// The access log is a structured entry
+	access := entries[1]
+	assert.Equal(t, logger.InfoLevel, access.Level)
+	assert.Equal(t, "HTTP request", access.Message)
+	assert.Equal(t, "req-1", access.Fields[logger.FieldRequestID])
+	assert.Equal(t, http.StatusOK, access.Fields["status"])
+	assert.Equal(t, http.MethodGet, access.Fields["method"])
+	assert.Equal(t, "/reminders/42", access.Fields["path"])
+	assert.Equal(t, "full=1", access.Fields["query"])
+	assert.Equal(t, "test-agent", access.Fields["user_agent"])
+	assert.Contains(t, access.Fields, "latency_ms")
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/fail", nil)
+	router.ServeHTTP(w, req)
+
+	entries = dest.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, logger.ErrorLevel, entries[2].Level, "server errors are logged as errors")
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,15 +14,18 @@ import (
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
 )
 
 // App represents the application
 type App struct {
-	httpServer *http.Server
-	router     *gin.Engine
-	dbManager  *db.DBManager
-	log        *logger.Logger
-	cfg        *config.Config
+	httpServer    *http.Server
+	metricsServer *http.Server // nil when metrics are disabled
+	router        *gin.Engine
+	dbManager     *db.DBManager
+	container     *Container
+	log           *logger.Logger
+	cfg           *config.Config
 }
 
 // New creates a new App instance
@@ -33,11 +37,22 @@ func New(cfg *config.Config, log *logger.Logger) (*App, error) {
 		return nil, err
 	}
 
+	// Time the operations of every collection
+	appMetrics := metrics.New()
+	if cfg.MetricsEnabled {
+		dbManager.DB = db.Instrument(dbManager.DB, appMetrics.DBObserver(cfg.DBType))
+	}
+
 	// Create dependency container
-	container := NewContainer(cfg, log, dbManager)
+	container := NewContainer(cfg, log, dbManager, appMetrics)
 
 	// Initialize router with dependency container
-	router := NewRouter(container)
+	router, err := NewRouter(container)
+	if err != nil {
+		container.Close()
+		dbManager.DB.Close(context.Background())
+		return nil, err
+	}
 
 	// Create HTTP server
 	server := &http.Server{
@@ -45,12 +60,22 @@ func New(cfg *config.Config, log *logger.Logger) (*App, error) {
 		Handler: router,
 	}
 
+	var metricsServer *http.Server
+	if cfg.MetricsEnabled {
+		metricsServer = &http.Server{
+			Addr:    cfg.MetricsAddr,
+			Handler: NewMetricsRouter(container),
+		}
+	}
+
 	return &App{
-		httpServer: server,
-		router:     router,
-		dbManager:  dbManager,
-		log:        log,
-		cfg:        cfg,
+		httpServer:    server,
+		metricsServer: metricsServer,
+		router:        router,
+		dbManager:     dbManager,
+		container:     container,
+		log:           log,
+		cfg:           cfg,
 	}, nil
 }
 
@@ -69,6 +94,13 @@ func (a *App) Run() error {
 		a.log.Errorf("Failed to migrate database: %v", err)
 	}
 
+	// Background jobs stop when the server does
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go a.container.AuditTrail.RunRetention(jobsCtx, a.cfg.AuditRetention, time.Hour)
+	go a.container.ExportService.RunPurge(jobsCtx, 15*time.Minute)
+	go a.container.LoginAttemptService.RunCleanup(jobsCtx, 5*time.Minute)
+
 	// Channel to listen for errors coming from the listener
 	serverErrors := make(chan error, 1)
 
@@ -77,6 +109,15 @@ func (a *App) Run() error {
 		a.log.Infof("Starting server at port %d", a.cfg.Port)
 		serverErrors <- a.httpServer.ListenAndServe()
 	}()
+	if a.metricsServer != nil {
+		go func() {
+			a.log.Infof("Serving metrics at %s", a.metricsServer.Addr)
+			// The app keeps serving without metrics
+			if err := a.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				a.log.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+	}
 	// Channel to listen for an interrupt or terminate signal from the OS
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -92,6 +133,8 @@ func (a *App) Run() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 
+		a.shutdownMetrics(ctx)
+
 		// Gracefully shutdown the server by waiting on existing requests
 		if err := a.httpServer.Shutdown(ctx); err != nil {
 			// If shutdown timed out, force close
@@ -117,6 +160,23 @@ func (a *App) Cleanup() error {
 		a.log.Errorf("Error closing database: %v", err)
 	}
 
-	// Shutdown HTTP server
+	// Stop background cleanup of the caches
+	if err := a.container.Close(); err != nil {
+		a.log.Errorf("Error closing dependencies: %v", err)
+	}
+
+	// Shutdown HTTP servers
+	a.shutdownMetrics(ctx)
 	return a.httpServer.Shutdown(ctx)
 }
+
+// shutdownMetrics stops the metrics listener, scrapes in flight are dropped if they take too long
+func (a *App) shutdownMetrics(ctx context.Context) {
+	if a.metricsServer == nil {
+		return
+	}
+	if err := a.metricsServer.Shutdown(ctx); err != nil {
+		a.log.Errorf("Metrics server shutdown failed: %v", err)
+		a.metricsServer.Close()
+	}
+}
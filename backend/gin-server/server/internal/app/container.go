@@ -1,15 +1,29 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth/oidc"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/usernamegen"
 
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/handler"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/api/middleware"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/mailer"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/metrics"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/service"
 )
@@ -17,30 +31,66 @@ import (
 // Container holds all dependencies
 type Container struct {
 	// Core components
-	DBManager *db.DBManager
-	Log       *logger.Logger
-	Cfg       *config.Config
+	DBManager  *db.DBManager
+	Log        *logger.Logger
+	Cfg        *config.Config
+	Mailer     mailer.Mailer
+	AuditTrail audit.Trail
+	Metrics    *metrics.Metrics
 
 	// Middlewares
 	Middleware middleware.Middleware
 
 	// Repositories
-	UserRepository     repository.UserRepository
-	ReminderRepository repository.ReminderRepository
+	UserRepository          repository.UserRepository
+	ReminderRepository      repository.ReminderRepository
+	ReminderGroupRepository repository.ReminderGroupRepository
+	APITokenRepository      repository.APITokenRepository
+	IdentityRepository      repository.UserIdentityRepository
+	LoginAttemptRepository  repository.LoginAttemptRepository
+	EmailChangeRepository   repository.EmailChangeRepository
+	MagicLinkRepository     repository.MagicLinkRepository
+	ExportJobRepository     repository.ExportJobRepository
 
 	// Services
-	AuthService service.AuthService
+	AuthService         service.AuthService
+	LoginAttemptService service.LoginAttemptService
+	APITokenService     service.APITokenService
+	OIDCService         service.OIDCService
+	UserService         service.UserService
+	ReminderService     service.ReminderService
+	MagicLinkService    service.MagicLinkService
+	ExportService       service.ExportService
+	UsernameService     service.UsernameService
 
 	// Handlers
-	AuthHandler handler.AuthHandler
+	AuthHandler      handler.AuthHandler
+	APITokenHandler  handler.APITokenHandler
+	OIDCHandler      handler.OIDCHandler
+	UserHandler      handler.UserHandler
+	LockoutHandler   handler.LockoutHandler
+	ExportHandler    handler.ExportHandler
+	AuditHandler     handler.AuditHandler
+	MagicLinkHandler handler.MagicLinkHandler
+	UsernameHandler  handler.UsernameHandler
+	CacheHandler     handler.CacheHandler
+	LogLevelHandler  handler.LogLevelHandler
+	LogsHandler      handler.LogsHandler
+	MetricsHandler   handler.MetricsHandler
+
+	// Resources released by Close, such as cache cleanup goroutines
+	closers []io.Closer
 }
 
 // NewContainer creates a new dependency container
-func NewContainer(cfg *config.Config, log *logger.Logger, dbManager *db.DBManager) *Container {
+func NewContainer(cfg *config.Config, log *logger.Logger, dbManager *db.DBManager, m *metrics.Metrics) *Container {
 	c := &Container{
-		DBManager: dbManager,
-		Log:       log,
-		Cfg:       cfg,
+		DBManager:  dbManager,
+		Log:        log,
+		Cfg:        cfg,
+		Mailer:     mailer.NewLogMailer(log),
+		AuditTrail: m.CountAuditEvents(audit.NewTrail(dbManager, log.WithComponent("audit"))),
+		Metrics:    m,
 	}
 
 	// Initialize JWT manager with configuration
@@ -50,20 +100,273 @@ func NewContainer(cfg *config.Config, log *logger.Logger, dbManager *db.DBManage
 	config.AccessTokenDuration = 15 * time.Minute
 	config.RefreshTokenDuration = 7 * 24 * time.Hour
 	config.IdentityKey = "user" // Key to store user ID in claims
+	// The social login state and magic link device cookies are set in every mode, so local
+	// development over HTTP needs the Secure flag off even without cookie mode
+	config.SecureCookies = cfg.AuthCookieSecure
+	if cfg.AuthCookieMode {
+		// Header clients keep working, the cookie is only used when no Authorization header is sent
+		config.SendCookies = true
+		config.TokenLookup = "header:Authorization,cookie:" + config.AccessCookieName
+		config.CookieDomain = cfg.AuthCookieDomain
+	}
+
+	// State shared between instances, in memory caches are bounded so they can't exhaust memory
+	var oidcFlows, repositoryCache, sharedCache memcache.Cache
+	if cfg.CacheStore == constants.CacheStoreRedis {
+		redisCache := memcache.NewRedisCache(memcache.RedisConfig{
+			Addr:      cfg.RedisAddr,
+			Password:  cfg.RedisPassword,
+			DB:        cfg.RedisDB,
+			KeyPrefix: cfg.RedisKeyPrefix,
+			PoolSize:  cfg.RedisPoolSize,
+			OnError: func(op string, err error) {
+				log.Errorf("Redis cache %s failed: %v", op, err)
+			},
+		})
+		if err := redisCache.Ping(); err != nil {
+			log.Errorf("Redis at %s is unreachable: %v", cfg.RedisAddr, err)
+		}
+		c.closers = append(c.closers, redisCache)
+
+		config.BlacklistedTokenCache = redisCache
+		oidcFlows = redisCache
+		repositoryCache = redisCache
+		sharedCache = redisCache
+	} else {
+		flows := memcache.New(memcache.Config{MaxEntries: 10000, CleanupInterval: time.Minute})
+		records := memcache.New(memcache.Config{MaxEntries: 50000, CleanupInterval: time.Minute})
+		c.closers = append(c.closers, flows, records)
+		oidcFlows = flows
+		repositoryCache = records
+
+		// Revoked tokens stay listed until they expire, evicting one would make its token valid
+		// again. Once full, logouts and refreshes fail instead. The auth manager closes it.
+		config.BlacklistedTokenCache = memcache.New(memcache.Config{MaxEntries: 200000, RejectWhenFull: true, CleanupInterval: time.Minute})
+	}
 
 	authManager := auth.NewAuthManager(config)
+	c.closers = append(c.closers, authManager)
+	roles := domain.NewRoleRegistry()
 
 	// Initialize repositories
 	c.UserRepository = repository.NewUserRepository(dbManager)
+	c.ReminderRepository = repository.NewReminderRepository(dbManager)
+	c.ReminderGroupRepository = repository.NewReminderGroupRepository(dbManager)
+	if cfg.RepositoryCache {
+		c.UserRepository = repository.NewCachedUserRepository(c.UserRepository, repositoryCache)
+		c.ReminderRepository = repository.NewCachedReminderRepository(c.ReminderRepository, repositoryCache)
+		c.ReminderGroupRepository = repository.NewCachedReminderGroupRepository(c.ReminderGroupRepository, repositoryCache)
+	}
+	c.APITokenRepository = repository.NewAPITokenRepository(dbManager)
+	c.IdentityRepository = repository.NewUserIdentityRepository(dbManager)
+	c.EmailChangeRepository = repository.NewEmailChangeRepository(dbManager)
+	c.MagicLinkRepository = repository.NewMagicLinkRepository(dbManager)
+	c.ExportJobRepository = repository.NewExportJobRepository(dbManager)
+	if cfg.LoginAttemptStore != constants.LoginAttemptStoreMemory {
+		var err error
+		if c.LoginAttemptRepository, err = repository.NewLoginAttemptRepository(dbManager); err != nil {
+			log.Errorf("Login attempt store %s can't be used, lockouts are per instance: %v", cfg.LoginAttemptStore, err)
+		}
+	}
+	if c.LoginAttemptRepository == nil {
+		c.LoginAttemptRepository = repository.NewInMemoryLoginAttemptRepository()
+	}
+
+	// Initialize OpenID Connect providers, discovery happens on first use
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+		}))
+	}
+
+	// Components whose log level can be changed at runtime on their own
+	authLog := log.WithComponent("auth")
+	exportLog := log.WithComponent("export")
+	rateLimitLog := log.WithComponent("ratelimit")
+
+	// Used by the rate limiter and the limit on login links, which applies even when the rate
+	// limiter is disabled
+	rateLimitStore := newRateLimitStore(cfg, dbManager.DB, sharedCache, rateLimitLog)
+	if closer, ok := rateLimitStore.(io.Closer); ok {
+		c.closers = append(c.closers, closer)
+	}
 
 	// Initialize services
-	c.AuthService = service.NewAuthService(c.UserRepository, log, authManager)
+	c.LoginAttemptService = service.NewLoginAttemptService(c.LoginAttemptRepository, service.LoginAttemptPolicy{
+		MaxFailures:   cfg.LoginMaxFailures,
+		IPMaxFailures: cfg.LoginIPMaxFailures,
+		LockoutBase:   cfg.LoginLockoutBase,
+		LockoutMax:    cfg.LoginLockoutMax,
+		FailureWindow: cfg.LoginFailureWindow,
+	}, authLog)
+	passwordPolicy := domain.PasswordPolicy{
+		MinLength:      cfg.PasswordMinLength,
+		MinCharClasses: cfg.PasswordMinCharClasses,
+		RejectBreached: cfg.PasswordRejectBreached,
+	}
+	c.UsernameService = service.NewUsernameService(c.UserRepository, newUsernameGenerator(cfg, log))
+	c.AuthService = service.NewAuthService(c.UserRepository, c.UsernameService, c.LoginAttemptService, passwordPolicy, c.AuditTrail, authLog, authManager)
+	c.APITokenService = service.NewAPITokenService(c.APITokenRepository, c.UserRepository, log)
+	c.UserService = service.NewUserService(c.UserRepository, service.UserDataRepositories{
+		Reminders:      c.ReminderRepository,
+		ReminderGroups: c.ReminderGroupRepository,
+		APITokens:      c.APITokenRepository,
+		Identities:     c.IdentityRepository,
+		EmailChanges:   c.EmailChangeRepository,
+		MagicLinks:     c.MagicLinkRepository,
+		LoginAttempts:  c.LoginAttemptRepository,
+		ExportJobs:     c.ExportJobRepository,
+	}, c.LoginAttemptService, roles, passwordPolicy, c.Mailer, c.AuditTrail, cfg.AppBaseURL, log, authManager)
+	c.ReminderService = service.NewReminderService(c.ReminderRepository, c.AuditTrail, log)
+	c.MagicLinkService = service.NewMagicLinkService(c.UserRepository, c.MagicLinkRepository, rateLimitStore, c.Mailer, c.AuditTrail, authManager, cfg.AppBaseURL, authLog)
+	c.ExportService = service.NewExportService(dbManager, c.ExportJobRepository, service.DefaultExportSections(), service.ExportConfig{
+		Dir:        cfg.ExportDir,
+		TTL:        cfg.ExportTTL,
+		SigningKey: []byte(cfg.ExportSigningSecret),
+		BaseURL:    cfg.AppBaseURL,
+	}, exportLog)
+	c.OIDCService = service.NewOIDCService(oidcProviders, c.UserRepository, c.IdentityRepository, c.UsernameService, oidcFlows, authManager, authLog)
 
 	// Initialize handlers
-	c.AuthHandler = handler.NewAuthHandler(c.AuthService, authManager, log)
+	c.AuthHandler = handler.NewAuthHandler(c.AuthService, authManager, authLog)
+	c.APITokenHandler = handler.NewAPITokenHandler(c.APITokenService, authManager, log)
+	c.OIDCHandler = handler.NewOIDCHandler(c.OIDCService, authManager, authLog)
+	c.UserHandler = handler.NewUserHandler(c.UserService, authManager, log)
+	c.LockoutHandler = handler.NewLockoutHandler(c.LoginAttemptService, authLog)
+	c.ExportHandler = handler.NewExportHandler(c.ExportService, authManager, exportLog)
+	c.AuditHandler = handler.NewAuditHandler(c.AuditTrail, authManager, log)
+	c.MagicLinkHandler = handler.NewMagicLinkHandler(c.MagicLinkService, authManager, authLog)
+	c.UsernameHandler = handler.NewUsernameHandler(c.UsernameService, log)
+	cached := cachedRepositories(c.UserRepository, c.ReminderRepository, c.ReminderGroupRepository)
+	c.CacheHandler = handler.NewCacheHandler(cached, repositoryCache)
+	c.LogLevelHandler = handler.NewLogLevelHandler(log)
+	c.LogsHandler = handler.NewLogsHandler(ringBuffer(log), log)
+	c.MetricsHandler = handler.NewMetricsHandler(m.Registry, cfg.MetricsToken, log)
+	m.ObserveCaches(cached)
 
 	// Initialize middlewares
-	c.Middleware = middleware.NewMiddleware(log, authManager)
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimitEnabled {
+		limiter = newRateLimiter(cfg, rateLimitStore, m)
+	}
+	c.Middleware = middleware.NewMiddleware(log, authManager, c.APITokenService, c.AuthService, roles, c.AuditTrail, limiter, m)
 
 	return c
 }
+
+// Close releases the resources held by the dependencies
+func (c *Container) Close() error {
+	var errs []error
+	for _, closer := range c.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cachedRepositories returns the repositories reading through a cache
+func cachedRepositories(repositories ...interface{}) []repository.CachedRepository {
+	var cached []repository.CachedRepository
+	for _, repo := range repositories {
+		if c, ok := repo.(repository.CachedRepository); ok {
+			cached = append(cached, c)
+		}
+	}
+	return cached
+}
+
+// ringBuffer returns the in-memory log destination, found through the destinations wrapping it, or
+// nil when LOG_BUFFER_SIZE disables it
+func ringBuffer(log *logger.Logger) *logger.RingBufferDestination {
+	dest, _ := log.Destination(logger.MemoryLogger)
+	for dest != nil {
+		if ring, ok := dest.(*logger.RingBufferDestination); ok {
+			return ring
+		}
+		wrapper, ok := dest.(interface{ Unwrap() logger.Destination })
+		if !ok {
+			return nil
+		}
+		dest = wrapper.Unwrap()
+	}
+	return nil
+}
+
+// newRateLimitStore builds the configured rate limit store. When it can't be used the limits fall
+// back to each instance, rather than leaving the API unprotected.
+func newRateLimitStore(cfg *config.Config, database db.Database, sharedCache memcache.Cache, log *logger.Logger) ratelimit.Store {
+	var store ratelimit.Store
+	var err error
+	switch cfg.RateLimitStore {
+	case constants.RateLimitStoreDatabase:
+		store, err = ratelimit.NewDatabaseStore(database, time.Minute)
+	case constants.RateLimitStoreCache:
+		if sharedCache == nil {
+			err = errors.New("the cache store isn't shared, set CACHE_STORE=redis")
+			break
+		}
+		store, err = ratelimit.NewCacheStore(sharedCache)
+	case constants.RateLimitStoreMemory:
+	default:
+		err = fmt.Errorf("unknown store %q", cfg.RateLimitStore)
+	}
+	if err != nil {
+		log.Errorf("Rate limit store %s can't be used, limits are per instance: %v", cfg.RateLimitStore, err)
+	}
+	if store == nil {
+		store = ratelimit.NewMemoryStore(time.Minute)
+	}
+	return store
+}
+
+// newRateLimiter builds the rate limiter with the configured tiers
+func newRateLimiter(cfg *config.Config, store ratelimit.Store, m *metrics.Metrics) *ratelimit.Limiter {
+	return ratelimit.NewLimiter(store, []ratelimit.Tier{
+		{Name: ratelimit.TierDefault, Limit: cfg.RateLimitDefault, Period: time.Minute},
+		{Name: ratelimit.TierAuth, Limit: cfg.RateLimitAuth, Period: time.Minute},
+		{Name: ratelimit.TierLowPriority, Limit: cfg.RateLimitLowPriority, Period: time.Minute},
+	}, ratelimit.WithFailOpen(cfg.RateLimitFailOpen), ratelimit.WithOnReject(m.RateLimitRejected))
+}
+
+// newUsernameGenerator builds the username generator from the configured pattern and word lists.
+// A broken configuration is logged and the bundled defaults are used, so users can still register.
+func newUsernameGenerator(cfg *config.Config, log *logger.Logger) *usernamegen.Generator {
+	options := []usernamegen.Option{usernamegen.WithPattern(cfg.UsernamePattern)}
+
+	if cfg.UsernameWordsDir != "" {
+		for file, kind := range map[string]string{
+			"adverbs.txt":    usernamegen.Adverb,
+			"adjectives.txt": usernamegen.Adjective,
+			"nouns.txt":      usernamegen.Noun,
+			"blocklist.txt":  "",
+		} {
+			words, err := usernamegen.LoadWords(filepath.Join(cfg.UsernameWordsDir, file))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				log.Errorf("Loading username words failed, file: %s, error: %v", file, err)
+				continue
+			}
+
+			if kind == "" {
+				options = append(options, usernamegen.WithBlocklist(words...))
+			} else {
+				options = append(options, usernamegen.WithWords(kind, words))
+			}
+		}
+	}
+
+	generator, err := usernamegen.New(options...)
+	if err != nil {
+		log.Errorf("Invalid username generator configuration, using defaults, error: %v", err)
+		generator, _ = usernamegen.New()
+	}
+	return generator
+}
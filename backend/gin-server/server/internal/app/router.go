@@ -1,41 +1,125 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
 )
 
-func NewRouter(container *Container) *gin.Engine {
+// NewMetricsRouter serves the metrics on their own listener, so traffic and business counters
+// aren't exposed on the public port
+func NewMetricsRouter(container *Container) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	r.GET("/metrics", container.MetricsHandler.Metrics)
+
+	return r
+}
+
+func NewRouter(container *Container) (*gin.Engine, error) {
 	r := gin.Default()
 
+	// Gin trusts X-Forwarded-For from any peer by default, which would let clients pick the IP
+	// their rate limits are keyed by
+	if err := r.SetTrustedProxies(container.Cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
 	r.Use(cors.Default())
 
-	// Apply global middlewares
+	// Apply global middlewares, metrics first so request durations include the others
+	if container.Cfg.MetricsEnabled {
+		r.Use(container.Middleware.Metrics())
+	}
+	r.Use(container.Middleware.RequestID())
 	r.Use(container.Middleware.Logger())
 	r.Use(container.Middleware.Recovery())
+	r.Use(container.Middleware.AuditContext())
 
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
 	api := r.Group("/api")
+	api.Use(container.Middleware.CSRF())
 	{
 		// Public routes
 		auth := api.Group("/auth")
+		auth.Use(container.Middleware.RateLimit(ratelimit.TierAuth))
 		{
 			auth.POST("/register", container.AuthHandler.Register)
 			auth.POST("/login", container.AuthHandler.Login)
 			auth.POST("/refresh", container.AuthHandler.RefreshToken)
+			auth.POST("/logout", container.AuthHandler.Logout)
+
+			// Passwordless login by email
+			auth.POST("/magic-link", container.MagicLinkHandler.Request)
+			auth.POST("/magic-link/consume", container.MagicLinkHandler.Consume)
+			auth.POST("/email/confirm", container.UserHandler.ConfirmEmailChange)
+
+			// OpenID Connect login
+			auth.GET("/oidc/:provider/login", container.OIDCHandler.Login)
+			auth.GET("/oidc/:provider/callback", container.OIDCHandler.Callback)
 		}
 
+		// Export downloads are authorized by the signed link
+		api.GET("/exports/:id/download", container.Middleware.RateLimit(ratelimit.TierLowPriority), container.ExportHandler.Download)
+
 		// Protected routes
 		protected := api.Group("/")
-		protected.Use(container.Middleware.Authenticate())
+		protected.Use(container.Middleware.Authenticate(), container.Middleware.RateLimit(ratelimit.TierDefault))
 		{
 			// User routes
 			users := protected.Group("/users")
 			{
-				users.GET("/me", container.AuthHandler.GetMe)
+				users.GET("/me", container.Middleware.RequireScope(domain.ScopeUsersRead), container.AuthHandler.GetMe)
+				users.PATCH("/me", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.UserHandler.UpdateMe)
+				users.DELETE("/me", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.UserHandler.DeleteMe)
+				users.PUT("/me/password", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.UserHandler.ChangePassword)
+				users.POST("/me/email", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.UserHandler.RequestEmailChange)
+				users.POST("/me/export", container.Middleware.RequireScope(domain.ScopeUsersRead), container.Middleware.RateLimit(ratelimit.TierLowPriority), container.ExportHandler.Start)
+				users.GET("/me/exports/:id", container.Middleware.RequireScope(domain.ScopeUsersRead), container.ExportHandler.Get)
+				users.GET("/me/activity", container.Middleware.RequireScope(domain.ScopeUsersRead), container.AuditHandler.MyActivity)
+
+				// Personal access tokens
+				tokens := users.Group("/me/tokens")
+				{
+					tokens.POST("", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.APITokenHandler.Create)
+					tokens.GET("", container.Middleware.RequireScope(domain.ScopeUsersRead), container.APITokenHandler.List)
+					tokens.DELETE("/:id", container.Middleware.RequireScope(domain.ScopeUsersWrite), container.APITokenHandler.Revoke)
+				}
+			}
+
+			// Available usernames to pick from when changing one's username
+			protected.GET("/usernames/suggest", container.Middleware.RequireScope(domain.ScopeUsersRead), container.Middleware.RateLimit(ratelimit.TierLowPriority), container.UsernameHandler.Suggest)
+
+			// Admin routes, every action is recorded in the audit trail
+			admin := protected.Group("/admin")
+			admin.Use(container.Middleware.RequirePermission(domain.PermissionUsersManage))
+			{
+				admin.GET("/users", container.UserHandler.ListUsers)
+				admin.GET("/users/:id", container.UserHandler.GetUser)
+				admin.PUT("/users/:id/role", container.Middleware.Audit(audit.ActionAdminUserRoleChange), container.UserHandler.ChangeRole)
+				admin.POST("/users/:id/disable", container.Middleware.Audit(audit.ActionAdminUserDisable), container.UserHandler.DisableUser)
+				admin.POST("/users/:id/enable", container.Middleware.Audit(audit.ActionAdminUserEnable), container.UserHandler.EnableUser)
+				admin.POST("/users/:id/logout", container.Middleware.Audit(audit.ActionAdminUserLogout), container.UserHandler.LogoutUser)
+				admin.POST("/users/:id/password", container.Middleware.Audit(audit.ActionAdminUserPasswordReset), container.UserHandler.ResetPassword)
+				admin.DELETE("/users/:id", container.Middleware.Audit(audit.ActionAdminUserDelete), container.UserHandler.DeleteUser)
+				admin.GET("/lockouts", container.LockoutHandler.List)
+				admin.DELETE("/lockouts/:id", container.Middleware.Audit(audit.ActionAdminLockoutClear), container.LockoutHandler.Clear)
+				admin.GET("/audit", container.AuditHandler.Query)
+				admin.GET("/audit/verify", container.AuditHandler.Verify)
+				admin.GET("/cache", container.CacheHandler.Stats)
+				admin.GET("/log-levels", container.LogLevelHandler.Get)
+				admin.PUT("/log-levels", container.Middleware.Audit(audit.ActionAdminLogLevelChange), container.LogLevelHandler.Update)
+				admin.GET("/logs", container.LogsHandler.Query)
+				admin.GET("/logs/tail", container.LogsHandler.Tail)
 			}
 
 			// 	reminders := protected.Group("/reminders")
@@ -50,5 +134,5 @@ func NewRouter(container *Container) *gin.Engine {
 		}
 	}
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,109 @@
+// Package audit records security relevant actions, such as logins, password changes and
+// admin changes to user accounts. Events are chained by hash so tampering can be detected.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Outcomes of an audited action
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Actions performed by users on their own account and data
+const (
+	ActionRegister           = "auth.register"
+	ActionLogin              = "auth.login"
+	ActionLogout             = "auth.logout"
+	ActionMagicLinkRequest   = "auth.magic_link_request"
+	ActionPasswordChange     = "user.password_change"
+	ActionProfileUpdate      = "user.profile_update"
+	ActionEmailChangeRequest = "user.email_change_request"
+	ActionEmailChange        = "user.email_change"
+	ActionAccountDelete      = "user.delete"
+	ActionReminderCreate     = "reminder.create"
+	ActionReminderDelete     = "reminder.delete"
+)
+
+// Actions performed by admins on user accounts
+const (
+	ActionAdminUserRoleChange    = "admin.user.role_change"
+	ActionAdminUserDisable       = "admin.user.disable"
+	ActionAdminUserEnable        = "admin.user.enable"
+	ActionAdminUserLogout        = "admin.user.logout"
+	ActionAdminUserPasswordReset = "admin.user.password_reset"
+	ActionAdminUserDelete        = "admin.user.delete"
+	ActionAdminLockoutClear      = "admin.lockout.clear"
+	ActionAdminLogLevelChange    = "admin.log_level.change"
+)
+
+// ActionPrune is recorded by the trail itself when retention removes old events
+const ActionPrune = "audit.prune"
+
+// Event is a single entry of the audit trail
+type Event struct {
+	ID        string    `json:"id" db:"id" firestore:"id"`
+	Sequence  int64     `json:"sequence" db:"sequence" firestore:"sequence"` // Position in the hash chain, starting at 1
+	ActorID   string    `json:"actorId,omitempty" db:"actor_id" firestore:"actor_id"`
+	Action    string    `json:"action" db:"action" firestore:"action"`
+	TargetID  string    `json:"targetId,omitempty" db:"target_id" firestore:"target_id"`
+	IP        string    `json:"ip,omitempty" db:"ip" firestore:"ip"`
+	UserAgent string    `json:"userAgent,omitempty" db:"user_agent" firestore:"user_agent"`
+	Outcome   string    `json:"outcome" db:"outcome" firestore:"outcome"`
+	Metadata  string    `json:"metadata,omitempty" db:"metadata" firestore:"metadata"` // JSON object with action specific details
+	PrevHash  string    `json:"prevHash,omitempty" db:"prev_hash" firestore:"prev_hash"`
+	Hash      string    `json:"hash" db:"hash" firestore:"hash"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+}
+
+// SetMetadata stores details of the action as a JSON object
+func (e *Event) SetMetadata(metadata map[string]interface{}) error {
+	if len(metadata) == 0 {
+		e.Metadata = ""
+		return nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	e.Metadata = string(data)
+	return nil
+}
+
+// Recorder appends events to the audit trail
+type Recorder interface {
+	Record(ctx context.Context, event *Event) error
+}
+
+type requestInfoKey struct{}
+
+// requestInfo describes the client of the request an event is recorded in
+type requestInfo struct {
+	ip        string
+	userAgent string
+}
+
+// WithRequest attaches the client IP and user agent to the context. Events recorded with
+// the context, e.g. by services that never see the HTTP request, carry them automatically.
+func WithRequest(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, requestInfo{ip: ip, userAgent: userAgent})
+}
+
+// fillRequest sets the client fields of an event from the context, unless already set
+func fillRequest(ctx context.Context, event *Event) {
+	info, ok := ctx.Value(requestInfoKey{}).(requestInfo)
+	if !ok {
+		return
+	}
+	if event.IP == "" {
+		event.IP = info.ip
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = info.userAgent
+	}
+}
@@ -0,0 +1,534 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+)
+
+const (
+	// chainHeadID is the id of the mark tracking the newest event of the hash chain
+	chainHeadID = "head"
+	// prunedMarkID is the id of the mark tracking the last pruned event
+	prunedMarkID = "pruned"
+	// DefaultPageSize is used when a query has no page size
+	DefaultPageSize = 50
+	// MaxPageSize caps the page size of a query
+	MaxPageSize = 200
+	// batchSize is the number of events read or deleted per query when verifying and pruning
+	batchSize = 500
+	// maxAttempts bounds the retries of appends and verifications racing another instance
+	maxAttempts = 5
+)
+
+// chainMark records a position in the chain. The head marks the newest event, so removing the
+// newest events is detected, and the pruned mark the last pruned event, so verification can start
+// from the oldest event still stored. Marks only move forward.
+type chainMark struct {
+	ID        string    `db:"id" firestore:"id"`
+	Sequence  int64     `db:"sequence" firestore:"sequence"`
+	Hash      string    `db:"hash" firestore:"hash"`
+	UpdatedAt time.Time `db:"updated_at" firestore:"updated_at"`
+}
+
+// Filter selects events of the trail, empty fields match everything
+type Filter struct {
+	ActorID  string
+	TargetID string
+	Action   string
+	Outcome  string
+	Since    time.Time // Inclusive
+	Until    time.Time // Exclusive
+	Page     int       // Starts at 1
+	PageSize int
+}
+
+// Page is a page of events, newest first
+type Page struct {
+	Events   []Event
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// Verification is the result of checking the hash chain
+type Verification struct {
+	Valid         bool   `json:"valid"`
+	Events        int    `json:"events"` // Number of events checked
+	FirstSequence int64  `json:"firstSequence,omitempty"`
+	LastSequence  int64  `json:"lastSequence,omitempty"`
+	BrokenAt      int64  `json:"brokenAt,omitempty"` // Sequence of the first event that doesn't match the chain
+	Reason        string `json:"reason,omitempty"`
+}
+
+// Trail is the persisted, hash chained audit trail
+type Trail interface {
+	Recorder
+	Query(ctx context.Context, filter Filter) (*Page, error)
+	Verify(ctx context.Context) (*Verification, error)
+	Prune(ctx context.Context, before time.Time) (int, error)
+	RunRetention(ctx context.Context, retention, interval time.Duration)
+}
+
+// trail appends events to the hash chain. The id of an event is derived from its sequence, so
+// the database accepts a single event after each one and instances sharing it can't fork the
+// chain: the loser of a race appends again after the new end.
+type trail struct {
+	events *eventsCollection
+	marks  db.Collection
+	log    *logger.Logger
+	now    func() time.Time
+
+	// mu serializes the appends of this instance, tail is the end of the chain they continue, nil
+	// until read from the database
+	mu   sync.Mutex
+	tail *link
+}
+
+// link is the position of an event in the chain
+type link struct {
+	sequence int64
+	hash     string
+}
+
+// eventsCollection wraps the events collection with typed helpers
+type eventsCollection struct {
+	db.Collection
+}
+
+func (c *eventsCollection) find(ctx context.Context, query db.Query) ([]Event, error) {
+	var events []Event
+	if err := c.Find(ctx, query, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// after returns the next batch of events following the sequence, oldest first
+func (c *eventsCollection) after(ctx context.Context, sequence int64) ([]Event, error) {
+	return c.find(ctx, db.Query{OrderBy: "sequence", Limit: batchSize}.Where("sequence", ">", sequence))
+}
+
+// NewTrail creates a Trail persisting events through the database
+func NewTrail(dbManager *db.DBManager, log *logger.Logger) Trail {
+	return &trail{
+		events: &eventsCollection{dbManager.DB.Collection(constants.AuditEventsCollection)},
+		marks:  dbManager.DB.Collection(constants.AuditChainCollection),
+		log:    log,
+		// Stored with microsecond precision, the most every database keeps, so hashes survive a round trip
+		now: func() time.Time { return time.Now().UTC().Truncate(time.Microsecond) },
+	}
+}
+
+// eventID returns the id of the event at the sequence, zero padded so ids sort like sequences
+func eventID(sequence int64) string {
+	return fmt.Sprintf("%020d", sequence)
+}
+
+// Record appends an event to the chain, assigning its id, sequence, time and hashes
+func (t *trail) Record(ctx context.Context, event *Event) error {
+	fillRequest(ctx, event)
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+
+	if err := t.append(ctx, event); err != nil {
+		return err
+	}
+
+	// The event is chained even if the head isn't moved, Verify accepts a head behind the newest
+	// event and the next one moves it
+	if err := t.advance(ctx, chainHeadID, event.Sequence, event.Hash); err != nil {
+		t.log.WithContext(ctx).Errorf("Moving the audit chain head to event %d failed: %v", event.Sequence, err)
+	}
+	return nil
+}
+
+// append inserts the event after the end of the chain. Only the insert happens under the lock,
+// the end of the chain is read again when another instance appended first.
+func (t *trail) append(ctx context.Context, event *Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		if t.tail == nil {
+			tail, err := t.loadTail(ctx)
+			if err != nil {
+				return err
+			}
+			t.tail = tail
+		}
+
+		event.Sequence = t.tail.sequence + 1
+		event.ID = eventID(event.Sequence)
+		event.PrevHash = t.tail.hash
+		event.CreatedAt = t.now()
+		event.Hash = hashEvent(event)
+
+		_, err := t.events.Create(ctx, event)
+		if err == nil {
+			t.tail = &link{sequence: event.Sequence, hash: event.Hash}
+			return nil
+		}
+
+		// The stored chain is unknown after a failure
+		t.tail = nil
+		if !errors.Is(err, db.ErrDuplicate) || attempt == maxAttempts {
+			return err
+		}
+	}
+}
+
+// loadTail reads the end of the chain: the newest event, or a mark further than it when the
+// newest events were removed, so their sequences are never reused
+func (t *trail) loadTail(ctx context.Context) (*link, error) {
+	tail := &link{}
+	for _, id := range []string{chainHeadID, prunedMarkID} {
+		mark, err := t.mark(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if mark.Sequence > tail.sequence {
+			tail = &link{sequence: mark.Sequence, hash: mark.Hash}
+		}
+	}
+
+	newest, err := t.events.find(ctx, db.Query{OrderBy: "sequence", Descending: true, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(newest) > 0 && newest[0].Sequence > tail.sequence {
+		tail = &link{sequence: newest[0].Sequence, hash: newest[0].Hash}
+	}
+
+	return tail, nil
+}
+
+// Query returns a page of events matching the filter, filtered, counted and paginated by the database
+func (t *trail) Query(ctx context.Context, filter Filter) (*Page, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 {
+		filter.PageSize = DefaultPageSize
+	}
+	if filter.PageSize > MaxPageSize {
+		filter.PageSize = MaxPageSize
+	}
+
+	query := db.Query{}
+	for _, condition := range []struct{ field, value string }{
+		{"actor_id", filter.ActorID},
+		{"target_id", filter.TargetID},
+		{"action", filter.Action},
+		{"outcome", filter.Outcome},
+	} {
+		if condition.value != "" {
+			query = query.Where(condition.field, "==", condition.value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at", ">=", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at", "<", filter.Until)
+	}
+
+	total, err := t.events.CountByQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Events:   []Event{},
+		Total:    int(total),
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if offset >= page.Total {
+		return page, nil
+	}
+
+	query.OrderBy = "sequence"
+	query.Descending = true
+	query.Offset = offset
+	query.Limit = filter.PageSize
+
+	events, err := t.events.find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		page.Events = events
+	}
+
+	return page, nil
+}
+
+// Verify recomputes the hash chain. Modified, removed or inserted events break the chain
+// at their position, and removing the newest events is caught by the chain head.
+func (t *trail) Verify(ctx context.Context) (*Verification, error) {
+	for attempt := 1; ; attempt++ {
+		pruned, err := t.mark(ctx, prunedMarkID)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := t.verify(ctx, pruned)
+		if err != nil || result.Valid || attempt == maxAttempts {
+			return result, err
+		}
+
+		// A concurrent prune moves the mark past the events it deletes, check again from there
+		current, err := t.mark(ctx, prunedMarkID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Sequence == pruned.Sequence {
+			return result, nil
+		}
+	}
+}
+
+// verify checks the chain continuing from the pruned mark, reading the events in batches
+func (t *trail) verify(ctx context.Context, pruned *chainMark) (*Verification, error) {
+	// Read before the events, so events appended meanwhile are past the head
+	head, err := t.mark(ctx, chainHeadID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Verification{Valid: true}
+	broken := func(sequence int64, reason string) (*Verification, error) {
+		result.Valid = false
+		result.BrokenAt = sequence
+		result.Reason = reason
+		return result, nil
+	}
+
+	expectedSequence := pruned.Sequence + 1
+	expectedPrevHash := pruned.Hash
+
+	for {
+		events, err := t.events.after(ctx, expectedSequence-1)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range events {
+			event := &events[i]
+			if result.Events == 0 {
+				result.FirstSequence = event.Sequence
+			}
+			result.Events++
+			result.LastSequence = event.Sequence
+
+			if event.Sequence != expectedSequence {
+				return broken(expectedSequence, fmt.Sprintf("expected event %d, found %d", expectedSequence, event.Sequence))
+			}
+			if event.PrevHash != expectedPrevHash {
+				return broken(event.Sequence, "previous hash does not match")
+			}
+			if hashEvent(event) != event.Hash {
+				return broken(event.Sequence, "event was modified")
+			}
+			if event.Sequence == head.Sequence && event.Hash != head.Hash {
+				return broken(event.Sequence, "event does not match the chain head")
+			}
+
+			expectedSequence++
+			expectedPrevHash = event.Hash
+		}
+
+		if len(events) < batchSize {
+			break
+		}
+	}
+
+	// The head may be behind the newest event when moving it failed, never ahead
+	if head.Sequence > expectedSequence-1 {
+		return broken(expectedSequence, fmt.Sprintf("chain ends at event %d, head is at %d", expectedSequence-1, head.Sequence))
+	}
+
+	return result, nil
+}
+
+// Prune deletes the events recorded before the given time and records the pruning.
+// Only the oldest run of events is removed, so the remaining chain stays verifiable.
+func (t *trail) Prune(ctx context.Context, before time.Time) (int, error) {
+	pruned, lastSequence, err := t.prune(ctx, before)
+	if err != nil || pruned == 0 {
+		return pruned, err
+	}
+
+	event := &Event{Action: ActionPrune}
+	_ = event.SetMetadata(map[string]interface{}{
+		"events":         pruned,
+		"before":         before.UTC(),
+		"prunedSequence": lastSequence,
+	})
+	if err := t.Record(ctx, event); err != nil {
+		return pruned, err
+	}
+
+	return pruned, nil
+}
+
+// prune moves the pruned mark to the last event of the oldest run recorded before the given time,
+// then deletes the events behind the mark in batches. Verification starts after the mark, so an
+// interrupted prune leaves a verifiable chain and its leftovers are deleted by the next one.
+func (t *trail) prune(ctx context.Context, before time.Time) (int, int64, error) {
+	mark, err := t.mark(ctx, prunedMarkID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	last := link{sequence: mark.Sequence, hash: mark.Hash}
+	for reachedCutoff := false; !reachedCutoff; {
+		events, err := t.events.after(ctx, last.sequence)
+		if err != nil {
+			return 0, mark.Sequence, err
+		}
+
+		for i := range events {
+			if !events[i].CreatedAt.Before(before) {
+				reachedCutoff = true
+				break
+			}
+			last = link{sequence: events[i].Sequence, hash: events[i].Hash}
+		}
+
+		if len(events) < batchSize {
+			reachedCutoff = true
+		}
+	}
+
+	if last.sequence > mark.Sequence {
+		if err := t.advance(ctx, prunedMarkID, last.sequence, last.hash); err != nil {
+			return 0, mark.Sequence, err
+		}
+	}
+
+	pruned := 0
+	for {
+		events, err := t.events.find(ctx, db.Query{Limit: batchSize}.Where("sequence", "<=", last.sequence))
+		if err != nil {
+			return pruned, last.sequence, err
+		}
+
+		for i := range events {
+			if err := t.events.DeleteById(ctx, events[i].ID); err != nil && !errors.Is(err, db.ErrNotFound) {
+				return pruned, last.sequence, err
+			}
+			pruned++
+		}
+
+		if len(events) < batchSize {
+			return pruned, last.sequence, nil
+		}
+	}
+}
+
+// RunRetention prunes events older than the retention every interval until the context is done.
+// A retention of zero keeps events forever.
+func (t *trail) RunRetention(ctx context.Context, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		pruned, err := t.Prune(ctx, t.now().Add(-retention))
+		if err != nil {
+			t.log.Errorf("Pruning audit events failed: %v", err)
+		} else if pruned > 0 {
+			t.log.Infof("Pruned %d audit events older than %s", pruned, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// mark returns a mark of the chain, an empty one before it is first moved
+func (t *trail) mark(ctx context.Context, id string) (*chainMark, error) {
+	var mark chainMark
+	err := t.marks.GetById(ctx, id, &mark)
+	if errors.Is(err, db.ErrNotFound) {
+		return &chainMark{ID: id}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mark, nil
+}
+
+// advance moves a mark of the chain to the event at the sequence, unless it is already further
+func (t *trail) advance(ctx context.Context, id string, sequence int64, hash string) error {
+	mark := &chainMark{ID: id, Sequence: sequence, Hash: hash, UpdatedAt: t.now()}
+	behind := []db.Condition{{Field: "sequence", Operator: "<", Value: sequence}}
+
+	err := t.marks.UpdateByIdIf(ctx, id, behind, mark)
+	if errors.Is(err, db.ErrNotFound) {
+		_, err = t.marks.Create(ctx, mark)
+		if errors.Is(err, db.ErrDuplicate) {
+			// Created concurrently
+			err = t.marks.UpdateByIdIf(ctx, id, behind, mark)
+		}
+	}
+	if errors.Is(err, db.ErrConflict) {
+		// Already further
+		return nil
+	}
+	return err
+}
+
+// hashEvent returns the hash of an event's content chained to the previous event
+func hashEvent(event *Event) string {
+	// Field order is fixed by the struct, so the encoding is stable
+	content, _ := json.Marshal(struct {
+		Sequence  int64  `json:"sequence"`
+		PrevHash  string `json:"prevHash"`
+		ID        string `json:"id"`
+		ActorID   string `json:"actorId"`
+		Action    string `json:"action"`
+		TargetID  string `json:"targetId"`
+		IP        string `json:"ip"`
+		UserAgent string `json:"userAgent"`
+		Outcome   string `json:"outcome"`
+		Metadata  string `json:"metadata"`
+		CreatedAt string `json:"createdAt"`
+	}{
+		Sequence:  event.Sequence,
+		PrevHash:  event.PrevHash,
+		ID:        event.ID,
+		ActorID:   event.ActorID,
+		Action:    event.Action,
+		TargetID:  event.TargetID,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Outcome:   event.Outcome,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,303 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+)
+
+// setupTrail returns a trail over a migrated SQLite database whose clock is advanced by hand
+func setupTrail(t *testing.T) (*trail, db.Collection, *time.Time) {
+	ctx := context.Background()
+
+	database, err := db.NewSQLiteDatabase(&config.Config{
+		DBType:     constants.SQLite,
+		SQLiteFile: filepath.Join(t.TempDir(), "test.db"),
+	}, logger.New())
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx))
+	require.NoError(t, database.Migrate(ctx))
+	t.Cleanup(func() { database.Close(ctx) })
+
+	dbManager := &db.DBManager{DB: database}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 123456000, time.UTC)
+
+	tr := NewTrail(dbManager, logger.New()).(*trail)
+	tr.now = func() time.Time { return now }
+
+	return tr, dbManager.DB.Collection(constants.AuditEventsCollection), &now
+}
+
+// recordEvents records one event per action, a minute apart
+func recordEvents(t *testing.T, tr *trail, now *time.Time, actorID string, actions ...string) []*Event {
+	events := make([]*Event, 0, len(actions))
+	for _, action := range actions {
+		event := &Event{ActorID: actorID, Action: action, TargetID: actorID}
+		require.NoError(t, event.SetMetadata(map[string]interface{}{"note": action}))
+		require.NoError(t, tr.Record(context.Background(), event))
+		events = append(events, event)
+		*now = now.Add(time.Minute)
+	}
+	return events
+}
+
+func TestRecordChainsEvents(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := WithRequest(context.Background(), "203.0.113.7", "test-agent")
+
+	first := &Event{ActorID: "alice", Action: ActionLogin}
+	require.NoError(t, tr.Record(ctx, first))
+	*now = now.Add(time.Minute)
+	second := &Event{ActorID: "alice", Action: ActionPasswordChange, IP: "198.51.100.1"}
+	require.NoError(t, tr.Record(ctx, second))
+
+	assert.Equal(t, int64(1), first.Sequence)
+	assert.Empty(t, first.PrevHash)
+	assert.Equal(t, OutcomeSuccess, first.Outcome)
+	assert.Equal(t, "203.0.113.7", first.IP)
+	assert.Equal(t, "test-agent", first.UserAgent)
+
+	assert.Equal(t, int64(2), second.Sequence)
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.Equal(t, "198.51.100.1", second.IP, "an explicit IP wins over the request")
+
+	result, err := tr.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, 2, result.Events)
+	assert.Equal(t, int64(1), result.FirstSequence)
+	assert.Equal(t, int64(2), result.LastSequence)
+}
+
+func TestRecordFromSeveralInstances(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := context.Background()
+
+	// Another instance sharing the database, each one caches the end of the chain it appended to
+	other := &trail{events: tr.events, marks: tr.marks, log: tr.log, now: tr.now}
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, tr.Record(ctx, &Event{ActorID: "alice", Action: ActionLogin}))
+		require.NoError(t, other.Record(ctx, &Event{ActorID: "bob", Action: ActionLogin}))
+		*now = now.Add(time.Minute)
+	}
+
+	result, err := tr.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, 6, result.Events)
+	assert.Equal(t, int64(6), result.LastSequence)
+}
+
+func TestVerifyAcceptsHeadBehindNewestEvent(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := context.Background()
+
+	recorded := recordEvents(t, tr, now, "alice", ActionLogin, ActionProfileUpdate, ActionPasswordChange)
+
+	// As if moving the head to the last two events failed
+	require.NoError(t, tr.marks.UpdateById(ctx, chainHeadID, &chainMark{Sequence: recorded[0].Sequence, Hash: recorded[0].Hash}))
+
+	result, err := tr.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+
+	// A head that doesn't match its event is still caught
+	require.NoError(t, tr.marks.UpdateById(ctx, chainHeadID, &chainMark{Sequence: recorded[1].Sequence, Hash: recorded[0].Hash}))
+
+	result, err = tr.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, recorded[1].Sequence, result.BrokenAt)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(t *testing.T, events db.Collection, recorded []*Event)
+		brokenAt int64
+	}{
+		{
+			name: "modified event",
+			tamper: func(t *testing.T, events db.Collection, recorded []*Event) {
+				modified := *recorded[1]
+				modified.Outcome = OutcomeFailure
+				require.NoError(t, events.UpdateById(context.Background(), modified.ID, &modified))
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "deleted event",
+			tamper: func(t *testing.T, events db.Collection, recorded []*Event) {
+				require.NoError(t, events.DeleteById(context.Background(), recorded[1].ID))
+			},
+			brokenAt: 2,
+		},
+		{
+			name: "deleted newest event",
+			tamper: func(t *testing.T, events db.Collection, recorded []*Event) {
+				require.NoError(t, events.DeleteById(context.Background(), recorded[2].ID))
+			},
+			brokenAt: 3,
+		},
+		{
+			name: "rehashed event",
+			tamper: func(t *testing.T, events db.Collection, recorded []*Event) {
+				// Recomputing the hash of a modified event breaks the link to the next one
+				modified := *recorded[0]
+				modified.ActorID = "mallory"
+				modified.Hash = hashEvent(&modified)
+				require.NoError(t, events.UpdateById(context.Background(), modified.ID, &modified))
+			},
+			brokenAt: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, events, now := setupTrail(t)
+			recorded := recordEvents(t, tr, now, "alice", ActionLogin, ActionProfileUpdate, ActionPasswordChange)
+
+			tt.tamper(t, events, recorded)
+
+			result, err := tr.Verify(context.Background())
+			require.NoError(t, err)
+			assert.False(t, result.Valid)
+			assert.Equal(t, tt.brokenAt, result.BrokenAt)
+			assert.NotEmpty(t, result.Reason)
+		})
+	}
+}
+
+func TestPruneKeepsChainVerifiable(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := context.Background()
+
+	recordEvents(t, tr, now, "alice", ActionLogin, ActionProfileUpdate, ActionPasswordChange)
+	cutoff := *now
+	recordEvents(t, tr, now, "alice", ActionEmailChange)
+
+	pruned, err := tr.Prune(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pruned)
+
+	page, err := tr.Query(ctx, Filter{})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Total)
+	assert.Equal(t, ActionPrune, page.Events[0].Action)
+	assert.Equal(t, ActionEmailChange, page.Events[1].Action)
+
+	result, err := tr.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, int64(4), result.FirstSequence)
+	assert.Equal(t, int64(5), result.LastSequence)
+
+	// Nothing left to prune records nothing
+	pruned, err = tr.Prune(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Zero(t, pruned)
+}
+
+func TestPruneDeletesLeftoversOfInterruptedPrune(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := context.Background()
+
+	recorded := recordEvents(t, tr, now, "alice", ActionLogin, ActionProfileUpdate, ActionPasswordChange)
+
+	// Interrupted after moving the mark past the first two events, before deleting them
+	require.NoError(t, tr.advance(ctx, prunedMarkID, recorded[1].Sequence, recorded[1].Hash))
+
+	result, err := tr.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, recorded[2].Sequence, result.FirstSequence)
+
+	pruned, err := tr.Prune(ctx, recorded[0].CreatedAt)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+
+	page, err := tr.Query(ctx, Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total, "the password change and the pruning")
+}
+
+func TestRunRetention(t *testing.T) {
+	tr, _, now := setupTrail(t)
+
+	recordEvents(t, tr, now, "alice", ActionLogin)
+	*now = now.Add(48 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tr.RunRetention(ctx, 24*time.Hour, time.Hour)
+	}()
+
+	// The first run happens right away
+	require.Eventually(t, func() bool {
+		page, err := tr.Query(context.Background(), Filter{Action: ActionPrune})
+		require.NoError(t, err)
+		return page.Total == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	page, err := tr.Query(context.Background(), Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total, "the pruned login is gone")
+}
+
+func TestQueryFilters(t *testing.T) {
+	tr, _, now := setupTrail(t)
+	ctx := context.Background()
+
+	start := *now
+	recordEvents(t, tr, now, "alice", ActionLogin, ActionProfileUpdate)
+	middle := *now
+	recordEvents(t, tr, now, "bob", ActionLogin)
+	recordEvents(t, tr, now, "alice", ActionLogin)
+	require.NoError(t, tr.Record(ctx, &Event{ActorID: "bob", Action: ActionLogin, Outcome: OutcomeFailure}))
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		total   int
+		actions []string
+	}{
+		{name: "actor", filter: Filter{ActorID: "alice"}, total: 3, actions: []string{ActionLogin, ActionProfileUpdate, ActionLogin}},
+		{name: "action and outcome", filter: Filter{Action: ActionLogin, Outcome: OutcomeFailure}, total: 1, actions: []string{ActionLogin}},
+		{name: "time range", filter: Filter{Since: start, Until: middle}, total: 2, actions: []string{ActionProfileUpdate, ActionLogin}},
+		{name: "page", filter: Filter{ActorID: "alice", Page: 2, PageSize: 2}, total: 3, actions: []string{ActionLogin}},
+		{name: "past the end", filter: Filter{Page: 10}, total: 5, actions: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := tr.Query(ctx, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.total, page.Total)
+
+			actions := make([]string, 0, len(page.Events))
+			for i, event := range page.Events {
+				actions = append(actions, event.Action)
+				if i > 0 {
+					assert.Less(t, event.Sequence, page.Events[i-1].Sequence, "newest first")
+				}
+			}
+			assert.Equal(t, tt.actions, actions)
+		})
+	}
+}
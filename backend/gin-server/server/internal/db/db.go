@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
@@ -23,6 +24,8 @@ var (
 	ErrInternal = errors.New("internal database error")
 	// ErrNotImplemented is returned when a method is not implemented
 	ErrNotImplemented = errors.New("method not implemented")
+	// ErrConflict is returned when a conditional update doesn't match the stored entity
+	ErrConflict = errors.New("entity does not match the conditions")
 )
 
 // Database defines the interface for database operations
@@ -62,11 +65,57 @@ type Collection interface {
 	// Updates a document/record by ID
 	UpdateById(ctx context.Context, id string, data interface{}) error
 
+	// Updates a document/record by ID if it matches all the conditions, atomically. Returns
+	// ErrConflict when it doesn't.
+	UpdateByIdIf(ctx context.Context, id string, conditions []Condition, data interface{}) error
+
 	// Removes a document/record by ID
 	DeleteById(ctx context.Context, id string) error
 
 	// Returns the number of documents/records matching the filter
 	Count(ctx context.Context, filter map[string]interface{}) (int64, error)
+
+	// Retrieves documents/records matching the query, in its order and up to its limit
+	Find(ctx context.Context, query Query, results interface{}) error
+
+	// Returns the number of documents/records matching the conditions of the query
+	CountByQuery(ctx context.Context, query Query) (int64, error)
+}
+
+// Condition compares a field of the documents/records with a value, Operator is one of
+// ==, <, <=, > and >=
+type Condition struct {
+	Field    string
+	Operator string
+	Value    interface{}
+}
+
+// Query selects documents/records with Find. Its conditions must all hold, results are sorted by
+// OrderBy when set, the first Offset of them are skipped and at most Limit of them are returned
+// when it is positive.
+type Query struct {
+	Conditions []Condition
+	OrderBy    string
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+// Where returns a copy of the query with one more condition
+func (q Query) Where(field, operator string, value interface{}) Query {
+	q.Conditions = append(append([]Condition(nil), q.Conditions...), Condition{Field: field, Operator: operator, Value: value})
+	return q
+}
+
+// Counters is implemented by databases that can increment expiring counters atomically,
+// e.g. for limits shared between instances
+type Counters interface {
+	// IncrementCounter adds delta to the counter at key and returns the new value. A missing or
+	// expired counter starts from zero and expires at expiresAt, a live one keeps its expiry.
+	IncrementCounter(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+
+	// DeleteExpiredCounters removes the counters expired at now
+	DeleteExpiredCounters(ctx context.Context, now time.Time) (int64, error)
 }
 
 // DBManager will hold the active database connection.
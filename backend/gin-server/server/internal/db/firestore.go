@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
 	firebase "firebase.google.com/go/v4"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -164,7 +165,7 @@ type FirestoreCollection struct {
 }
 
 func (f *FirestoreCollection) Create(ctx context.Context, data interface{}) (string, error) {
-	f.db.logger.Infof("Creating document in Firestore collection: %s", f.collectionName)
+	f.db.logger.WithContext(ctx).Infof("Creating document in Firestore collection: %s", f.collectionName)
 
 	if f.db.client == nil {
 		return "", errors.New("firestore client is not initialized")
@@ -202,18 +203,21 @@ func (f *FirestoreCollection) Create(ctx context.Context, data interface{}) (str
 		return "", fmt.Errorf("failed to convert struct to map: %v", err)
 	}
 
-	// Use the ID from the struct to create the document
-	_, err = f.db.client.Collection(f.collectionName).Doc(docID).Set(ctx, dataMap)
+	// Use the ID from the struct to create the document, failing if it already exists
+	_, err = f.db.client.Collection(f.collectionName).Doc(docID).Create(ctx, dataMap)
 	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return "", fmt.Errorf("%w: document %s", ErrDuplicate, docID)
+		}
 		return "", fmt.Errorf("failed to create document: %v", err)
 	}
 
-	f.db.logger.Infof(fmt.Sprintf("Created document in collection %s with ID: %s", f.collectionName, docID))
+	f.db.logger.WithContext(ctx).Infof("Created document in collection %s with ID: %s", f.collectionName, docID)
 	return docID, nil
 }
 
 func (f *FirestoreCollection) GetById(ctx context.Context, id string, result interface{}) error {
-	f.db.logger.Infof("Getting document by ID from Firestore collection: %s", f.collectionName)
+	f.db.logger.WithContext(ctx).Infof("Getting document by ID from Firestore collection: %s", f.collectionName)
 
 	if f.db.client == nil {
 		return errors.New("firestore client is not initialized")
@@ -223,7 +227,7 @@ func (f *FirestoreCollection) GetById(ctx context.Context, id string, result int
 	docSnap, err := f.db.client.Collection(f.collectionName).Doc(id).Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
-			return fmt.Errorf("document %s not found", id)
+			return fmt.Errorf("%w: document %s not found", ErrNotFound, id)
 		}
 		return fmt.Errorf("failed to get document: %v", err)
 	}
@@ -238,7 +242,7 @@ func (f *FirestoreCollection) GetById(ctx context.Context, id string, result int
 }
 
 func (f *FirestoreCollection) GetOne(ctx context.Context, filter map[string]interface{}, result interface{}) error {
-	f.db.logger.Infof("Getting one document from Firestore collection: %s with filter: %v", f.collectionName, filter)
+	f.db.logger.WithContext(ctx).Infof("Getting one document from Firestore collection: %s with filter: %v", f.collectionName, filter)
 
 	if f.db.client == nil {
 		return errors.New("firestore client is not initialized")
@@ -273,7 +277,7 @@ func (f *FirestoreCollection) GetOne(ctx context.Context, filter map[string]inte
 }
 
 func (f *FirestoreCollection) GetAllByCondition(ctx context.Context, filter map[string]interface{}, results interface{}) error {
-	f.db.logger.Infof("Getting all documents from Firestore collection: %s with filter: %v", f.collectionName, filter)
+	f.db.logger.WithContext(ctx).Infof("Getting all documents from Firestore collection: %s with filter: %v", f.collectionName, filter)
 
 	if f.db.client == nil {
 		return errors.New("firestore client is not initialized")
@@ -285,8 +289,68 @@ func (f *FirestoreCollection) GetAllByCondition(ctx context.Context, filter map[
 		query = query.Where(field, "==", value)
 	}
 
-	// Execute the query
-	iter := query.Documents(ctx)
+	return collectDocuments(query.Documents(ctx), results)
+}
+
+// Find fetches the documents matching the query, sorted and limited by Firestore
+func (f *FirestoreCollection) Find(ctx context.Context, q Query, results interface{}) error {
+	f.db.logger.WithContext(ctx).Infof("Finding documents in Firestore collection: %s with query: %+v", f.collectionName, q)
+
+	if f.db.client == nil {
+		return errors.New("firestore client is not initialized")
+	}
+
+	query := f.where(q.Conditions)
+	if q.OrderBy != "" {
+		direction := firestore.Asc
+		if q.Descending {
+			direction = firestore.Desc
+		}
+		query = query.OrderBy(q.OrderBy, direction)
+	}
+	if q.Offset > 0 {
+		query = query.Offset(q.Offset)
+	}
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit)
+	}
+
+	return collectDocuments(query.Documents(ctx), results)
+}
+
+// CountByQuery counts the documents matching the conditions of the query with an aggregation,
+// without fetching them
+func (f *FirestoreCollection) CountByQuery(ctx context.Context, q Query) (int64, error) {
+	f.db.logger.WithContext(ctx).Infof("Counting documents in Firestore collection: %s with query: %+v", f.collectionName, q)
+
+	if f.db.client == nil {
+		return 0, errors.New("firestore client is not initialized")
+	}
+
+	query := f.where(q.Conditions)
+	result, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %v", err)
+	}
+
+	count, ok := result["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("unexpected count result: %v", result["count"])
+	}
+	return count.GetIntegerValue(), nil
+}
+
+// where returns a query of the collection filtered by the conditions
+func (f *FirestoreCollection) where(conditions []Condition) firestore.Query {
+	query := f.db.client.Collection(f.collectionName).Query
+	for _, condition := range conditions {
+		query = query.Where(condition.Field, condition.Operator, condition.Value)
+	}
+	return query
+}
+
+// collectDocuments maps every document of iter into results, a pointer to a slice
+func collectDocuments(iter *firestore.DocumentIterator, results interface{}) error {
 	defer iter.Stop()
 
 	// Collect all documents
@@ -338,7 +402,7 @@ func (f *FirestoreCollection) GetAllByCondition(ctx context.Context, filter map[
 }
 
 func (f *FirestoreCollection) UpdateById(ctx context.Context, id string, data interface{}) error {
-	f.db.logger.Infof("Updating document by ID in Firestore collection: %s", f.collectionName)
+	f.db.logger.WithContext(ctx).Infof("Updating document by ID in Firestore collection: %s", f.collectionName)
 
 	if f.db.client == nil {
 		return errors.New("firestore client is not initialized")
@@ -374,8 +438,52 @@ func (f *FirestoreCollection) UpdateById(ctx context.Context, id string, data in
 	return nil
 }
 
+// UpdateByIdIf checks the conditions and merges the data in a transaction, so the document can't
+// change in between
+func (f *FirestoreCollection) UpdateByIdIf(ctx context.Context, id string, conditions []Condition, data interface{}) error {
+	f.db.logger.WithContext(ctx).Infof("Conditionally updating document by ID in Firestore collection: %s", f.collectionName)
+
+	if f.db.client == nil {
+		return errors.New("firestore client is not initialized")
+	}
+
+	dataMap, err := structToMap(data)
+	if err != nil {
+		return fmt.Errorf("failed to convert struct to map: %v", err)
+	}
+
+	ref := f.db.client.Collection(f.collectionName).Doc(id)
+	query := f.where(conditions).Where(firestore.DocumentID, "==", ref)
+
+	err = f.db.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		matches, err := tx.Documents(query).GetAll()
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			// Either the document is missing or it doesn't match
+			if _, err := tx.Get(ref); err != nil {
+				if status.Code(err) == codes.NotFound {
+					return fmt.Errorf("%w: document %s not found", ErrNotFound, id)
+				}
+				return err
+			}
+			return fmt.Errorf("%w: document %s", ErrConflict, id)
+		}
+		return tx.Set(ref, dataMap, firestore.MergeAll)
+	})
+	if err != nil {
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict) {
+			return err
+		}
+		return fmt.Errorf("failed to update document: %v", err)
+	}
+
+	return nil
+}
+
 func (f *FirestoreCollection) DeleteById(ctx context.Context, id string) error {
-	f.db.logger.Infof("Deleting document by ID from Firestore collection: %s", f.collectionName)
+	f.db.logger.WithContext(ctx).Infof("Deleting document by ID from Firestore collection: %s", f.collectionName)
 
 	if f.db.client == nil {
 		return errors.New("firestore client is not initialized")
@@ -391,7 +499,7 @@ func (f *FirestoreCollection) DeleteById(ctx context.Context, id string) error {
 }
 
 func (f *FirestoreCollection) Count(ctx context.Context, filter map[string]interface{}) (int64, error) {
-	f.db.logger.Infof("Counting documents in Firestore collection: %s with filter: %v", f.collectionName, filter)
+	f.db.logger.WithContext(ctx).Infof("Counting documents in Firestore collection: %s with filter: %v", f.collectionName, filter)
 
 	if f.db.client == nil {
 		return 0, errors.New("firestore client is not initialized")
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Collection operations reported to an OperationObserver
+const (
+	OpCreate            = "create"
+	OpGetById           = "get_by_id"
+	OpGetOne            = "get_one"
+	OpGetAllByCondition = "get_all_by_condition"
+	OpUpdateById        = "update_by_id"
+	OpUpdateByIdIf      = "update_by_id_if"
+	OpDeleteById        = "delete_by_id"
+	OpCount             = "count"
+	OpFind              = "find"
+	OpCountByQuery      = "count_by_query"
+)
+
+// OperationObserver receives the duration and error of every collection operation of an
+// instrumented database
+type OperationObserver func(collection, operation string, duration time.Duration, err error)
+
+// Instrument wraps database so the operations of its collections are reported to observe. The
+// returned Database implements Counters when database does.
+func Instrument(database Database, observe OperationObserver) Database {
+	instrumented := &instrumentedDatabase{Database: database, observe: observe}
+	if counters, ok := database.(Counters); ok {
+		return &instrumentedCountersDatabase{instrumentedDatabase: instrumented, Counters: counters}
+	}
+	return instrumented
+}
+
+type instrumentedDatabase struct {
+	Database
+	observe OperationObserver
+}
+
+// instrumentedCountersDatabase keeps the Counters of the wrapped database visible to type assertions
+type instrumentedCountersDatabase struct {
+	*instrumentedDatabase
+	Counters
+}
+
+func (d *instrumentedDatabase) Collection(name string) Collection {
+	return &instrumentedCollection{collection: d.Database.Collection(name), name: name, observe: d.observe}
+}
+
+type instrumentedCollection struct {
+	collection Collection
+	name       string
+	observe    OperationObserver
+}
+
+// track reports the operation started at start with its error
+func (c *instrumentedCollection) track(operation string, start time.Time, err error) {
+	c.observe(c.name, operation, time.Since(start), err)
+}
+
+func (c *instrumentedCollection) Create(ctx context.Context, data interface{}) (string, error) {
+	start := time.Now()
+	id, err := c.collection.Create(ctx, data)
+	c.track(OpCreate, start, err)
+	return id, err
+}
+
+func (c *instrumentedCollection) GetById(ctx context.Context, id string, result interface{}) error {
+	start := time.Now()
+	err := c.collection.GetById(ctx, id, result)
+	c.track(OpGetById, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) GetOne(ctx context.Context, filter map[string]interface{}, result interface{}) error {
+	start := time.Now()
+	err := c.collection.GetOne(ctx, filter, result)
+	c.track(OpGetOne, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) GetAllByCondition(ctx context.Context, filter map[string]interface{}, results interface{}) error {
+	start := time.Now()
+	err := c.collection.GetAllByCondition(ctx, filter, results)
+	c.track(OpGetAllByCondition, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) UpdateById(ctx context.Context, id string, data interface{}) error {
+	start := time.Now()
+	err := c.collection.UpdateById(ctx, id, data)
+	c.track(OpUpdateById, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) UpdateByIdIf(ctx context.Context, id string, conditions []Condition, data interface{}) error {
+	start := time.Now()
+	err := c.collection.UpdateByIdIf(ctx, id, conditions, data)
+	c.track(OpUpdateByIdIf, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) DeleteById(ctx context.Context, id string) error {
+	start := time.Now()
+	err := c.collection.DeleteById(ctx, id)
+	c.track(OpDeleteById, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) Count(ctx context.Context, filter map[string]interface{}) (int64, error) {
+	start := time.Now()
+	count, err := c.collection.Count(ctx, filter)
+	c.track(OpCount, start, err)
+	return count, err
+}
+
+func (c *instrumentedCollection) Find(ctx context.Context, query Query, results interface{}) error {
+	start := time.Now()
+	err := c.collection.Find(ctx, query, results)
+	c.track(OpFind, start, err)
+	return err
+}
+
+func (c *instrumentedCollection) CountByQuery(ctx context.Context, query Query) (int64, error) {
+	start := time.Now()
+	count, err := c.collection.CountByQuery(ctx, query)
+	c.track(OpCountByQuery, start, err)
+	return count, err
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observedOperation struct {
+	collection string
+	operation  string
+	notFound   bool
+}
+
+// TestInstrument tests that collection operations are reported with their errors
+func TestInstrument(t *testing.T) {
+	database, cleanup := setupDatabase(t)
+	defer cleanup()
+
+	var observed []observedOperation
+	instrumented := Instrument(database, func(collection, operation string, duration time.Duration, err error) {
+		assert.Positive(t, duration)
+		if !errors.Is(err, ErrNotFound) {
+			assert.NoError(t, err)
+		}
+		observed = append(observed, observedOperation{collection, operation, errors.Is(err, ErrNotFound)})
+	})
+
+	_, ok := instrumented.(Counters)
+	assert.True(t, ok, "SQLite counters stay available")
+
+	ctx := context.Background()
+	users := instrumented.Collection("users")
+	now := time.Now()
+	id, err := users.Create(ctx, TestUser{ID: uuid.New().String(), Username: "metrics", Email: "metrics@example.com", Password: "password123", Role: "user", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+
+	var user TestUser
+	require.NoError(t, users.GetById(ctx, id, &user))
+	assert.Equal(t, "metrics", user.Username)
+	assert.ErrorIs(t, users.GetById(ctx, "missing", &user), ErrNotFound)
+	count, err := users.Count(ctx, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	assert.Equal(t, []observedOperation{
+		{"users", OpCreate, false},
+		{"users", OpGetById, false},
+		{"users", OpGetById, true},
+		{"users", OpCount, false},
+	}, observed)
+}
@@ -177,6 +177,8 @@ func (s *SQLiteDatabase) Migrate(ctx context.Context) error {
 			email TEXT NOT NULL UNIQUE,
 			password TEXT NOT NULL,
 			role TEXT NOT NULL DEFAULT 'user',
+			status TEXT NOT NULL DEFAULT 'active',
+			token_version INTEGER NOT NULL DEFAULT 0,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)`,
@@ -202,6 +204,117 @@ func (s *SQLiteDatabase) Migrate(ctx context.Context) error {
       FOREIGN KEY (reminder_group_id) REFERENCES reminder_groups(id) ON DELETE SET NULL
 
 		)`,
+		`CREATE TABLE IF NOT EXISTS api_tokens (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			prefix TEXT NOT NULL,
+			scopes TEXT NOT NULL,
+			expires_at TIMESTAMP,
+			last_used_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id)`,
+		`CREATE TABLE IF NOT EXISTS user_identities (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			email TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (provider, subject),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id)`,
+		`CREATE TABLE IF NOT EXISTS email_changes (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			new_email TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS magic_links (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			device_hash TEXT,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_magic_links_email ON magic_links(email)`,
+		`CREATE TABLE IF NOT EXISTS export_jobs (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			error TEXT,
+			file_path TEXT,
+			size INTEGER,
+			completed_at TIMESTAMP,
+			expires_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_export_jobs_user_id ON export_jobs(user_id)`,
+		`CREATE TABLE IF NOT EXISTS export_claims (
+			id TEXT PRIMARY KEY,
+			job_id TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS audit_events (
+			id TEXT PRIMARY KEY,
+			sequence INTEGER NOT NULL DEFAULT 0,
+			actor_id TEXT,
+			action TEXT NOT NULL,
+			target_id TEXT,
+			ip TEXT,
+			user_agent TEXT,
+			outcome TEXT NOT NULL,
+			metadata TEXT,
+			prev_hash TEXT,
+			hash TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events(actor_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_events_target_id ON audit_events(target_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_events_sequence ON audit_events(sequence)`,
+		`CREATE TABLE IF NOT EXISTS audit_chain (
+			id TEXT PRIMARY KEY,
+			sequence INTEGER NOT NULL DEFAULT 0,
+			hash TEXT,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS counters (
+			key TEXT PRIMARY KEY,
+			value INTEGER NOT NULL DEFAULT 0,
+			expires_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_counters_expires_at ON counters(expires_at)`,
+		`CREATE TABLE IF NOT EXISTS login_attempts (
+			id TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			round INTEGER NOT NULL DEFAULT 0,
+			failures INTEGER NOT NULL DEFAULT 0,
+			locked_at TIMESTAMP,
+			locked_until TIMESTAMP,
+			expires_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_attempts_locked_until ON login_attempts(locked_until)`,
+		`CREATE INDEX IF NOT EXISTS idx_login_attempts_expires_at ON login_attempts(expires_at)`,
 	}
 
 	for _, query := range queries {
@@ -211,10 +324,84 @@ func (s *SQLiteDatabase) Migrate(ctx context.Context) error {
 		}
 	}
 
+	// Columns added after a table was first released, CREATE TABLE IF NOT EXISTS skips existing tables
+	columns := []struct {
+		table, column, definition string
+	}{
+		{"users", "status", "TEXT NOT NULL DEFAULT 'active'"},
+		{"users", "token_version", "INTEGER NOT NULL DEFAULT 0"},
+	}
+
+	for _, c := range columns {
+		if err := s.addColumnIfMissing(ctx, c.table, c.column, c.definition); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
 	s.logger.Infof("SQLite migrations completed successfully")
 	return nil
 }
 
+// IncrementCounter upserts the counter in a single statement, so concurrent increments from
+// several instances are never lost. Expiries are stored as Unix milliseconds.
+func (s *SQLiteDatabase) IncrementCounter(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	if s.conn == nil {
+		return 0, errors.New("database connection not established")
+	}
+
+	now := time.Now().UnixMilli()
+	var value int64
+	err := s.conn.QueryRowContext(ctx, `INSERT INTO counters (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN counters.expires_at <= ? THEN excluded.value ELSE counters.value + excluded.value END,
+			expires_at = CASE WHEN counters.expires_at <= ? THEN excluded.expires_at ELSE counters.expires_at END
+		RETURNING value`,
+		key, delta, expiresAt.UnixMilli(), now, now,
+	).Scan(&value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	return value, nil
+}
+
+// DeleteExpiredCounters removes the counters expired at now
+func (s *SQLiteDatabase) DeleteExpiredCounters(ctx context.Context, now time.Time) (int64, error) {
+	if s.conn == nil {
+		return 0, errors.New("database connection not established")
+	}
+
+	result, err := s.conn.ExecContext(ctx, "DELETE FROM counters WHERE expires_at <= ?", now.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+	}
+	return result.RowsAffected()
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already there
+func (s *SQLiteDatabase) addColumnIfMissing(ctx context.Context, table, column, definition string) error {
+	rows, err := s.conn.QueryContext(ctx, fmt.Sprintf("SELECT name FROM pragma_table_info('%s')", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.conn.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 // func (s *SQLiteDatabase) Migrate(ctx context.Context) error {
 // 	s.logger.Infof("Running SQLite migrations")
 // 	driver, err := sqlite3.WithInstance(s.conn, &sqlite3.Config{})
@@ -264,12 +451,18 @@ func (s *SQLiteDatabase) Seed(ctx context.Context) error {
 	return nil
 }
 
+// internalError wraps a failed database call as ErrInternal and logs it with the request fields of ctx
+func (c *SQLiteCollection) internalError(ctx context.Context, op string, err error) error {
+	c.db.logger.WithContext(ctx).Errorf("SQLite %s on %s failed: %v", op, c.tableName, err)
+	return fmt.Errorf("%w: %v", ErrInternal, err)
+}
+
 // Create inserts a new document/record into the collection/table
 func (c *SQLiteCollection) Create(ctx context.Context, data interface{}) (string, error) {
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return "", c.internalError(ctx, "Create", err)
 	}
 
 	// Extract field names, values, and ID
@@ -292,7 +485,7 @@ func (c *SQLiteCollection) Create(ctx context.Context, data interface{}) (string
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
 			return "", fmt.Errorf("%w: %v", ErrDuplicate, err)
 		}
-		return "", fmt.Errorf("%w: %v", ErrInternal, err)
+		return "", c.internalError(ctx, "Create", err)
 	}
 
 	return id, nil
@@ -303,7 +496,7 @@ func (c *SQLiteCollection) GetById(ctx context.Context, id string, result interf
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "GetById", err)
 	}
 
 	// Validate result is a pointer to struct
@@ -317,7 +510,7 @@ func (c *SQLiteCollection) GetById(ctx context.Context, id string, result interf
 	// Execute the query
 	rows, err := conn.QueryContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "GetById", err)
 	}
 	defer rows.Close()
 
@@ -336,10 +529,80 @@ func (c *SQLiteCollection) GetById(ctx context.Context, id string, result interf
 
 // GetAllByCondition fetches all records from the collection based on filter criteria
 func (c *SQLiteCollection) GetAllByCondition(ctx context.Context, filter map[string]interface{}, results interface{}) error {
+	// Build query
+	query := fmt.Sprintf("SELECT * FROM %s", c.tableName)
+	whereClause, values := buildWhereClause(filter)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	return c.selectAll(ctx, "GetAllByCondition", query, values, results)
+}
+
+// Find fetches the records matching the query, sorted and limited in SQL
+func (c *SQLiteCollection) Find(ctx context.Context, q Query, results interface{}) error {
+	// Build query
+	query := fmt.Sprintf("SELECT * FROM %s", c.tableName)
+	whereClause, values, err := buildConditionClause(q.Conditions)
+	if err != nil {
+		return err
+	}
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	if q.OrderBy != "" {
+		query += " ORDER BY " + q.OrderBy
+		if q.Descending {
+			query += " DESC"
+		}
+	}
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	} else if q.Offset > 0 {
+		// SQLite only accepts an offset after a limit, a negative one is no limit
+		query += " LIMIT -1"
+	}
+	if q.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", q.Offset)
+	}
+
+	return c.selectAll(ctx, "Find", query, values, results)
+}
+
+// CountByQuery counts the records matching the conditions of the query in SQL
+func (c *SQLiteCollection) CountByQuery(ctx context.Context, q Query) (int64, error) {
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return 0, c.internalError(ctx, "CountByQuery", err)
+	}
+
+	// Build query
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", c.tableName)
+	whereClause, values, err := buildConditionClause(q.Conditions)
+	if err != nil {
+		return 0, err
+	}
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	// Execute query
+	var count int64
+	err = conn.QueryRowContext(ctx, query, values...).Scan(&count)
+	if err != nil {
+		return 0, c.internalError(ctx, "CountByQuery", err)
+	}
+
+	return count, nil
+}
+
+// selectAll runs query and maps every row into results, a pointer to a slice of structs
+func (c *SQLiteCollection) selectAll(ctx context.Context, op, query string, values []interface{}, results interface{}) error {
+	// Get database connection
+	conn, err := c.db.GetConn(ctx)
+	if err != nil {
+		return c.internalError(ctx, op, err)
 	}
 
 	// Validate results is a pointer to slice of structs
@@ -359,17 +622,10 @@ func (c *SQLiteCollection) GetAllByCondition(ctx context.Context, filter map[str
 		return fmt.Errorf("%w: slice elements must be structs", ErrInvalidInput)
 	}
 
-	// Build query
-	query := fmt.Sprintf("SELECT * FROM %s", c.tableName)
-	whereClause, values := buildWhereClause(filter)
-	if whereClause != "" {
-		query += " WHERE " + whereClause
-	}
-
 	// Execute query
 	rows, err := conn.QueryContext(ctx, query, values...)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, op, err)
 	}
 	defer rows.Close()
 
@@ -391,7 +647,7 @@ func (c *SQLiteCollection) GetAllByCondition(ctx context.Context, filter map[str
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, op, err)
 	}
 
 	return nil
@@ -402,7 +658,7 @@ func (c *SQLiteCollection) GetOne(ctx context.Context, filter map[string]interfa
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "GetOne", err)
 	}
 
 	// Validate result is a pointer to a struct
@@ -421,7 +677,7 @@ func (c *SQLiteCollection) GetOne(ctx context.Context, filter map[string]interfa
 	// Execute query
 	rows, err := conn.QueryContext(ctx, query, values...)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "GetOne", err)
 	}
 	defer rows.Close()
 
@@ -443,7 +699,7 @@ func (c *SQLiteCollection) UpdateById(ctx context.Context, id string, data inter
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "UpdateById", err)
 	}
 
 	// Extract fields to update
@@ -469,13 +725,13 @@ func (c *SQLiteCollection) UpdateById(ctx context.Context, id string, data inter
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
 			return fmt.Errorf("%w: %v", ErrDuplicate, err)
 		}
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "UpdateById", err)
 	}
 
 	// Check if any rows were affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "UpdateById", err)
 	}
 
 	if rowsAffected == 0 {
@@ -485,12 +741,73 @@ func (c *SQLiteCollection) UpdateById(ctx context.Context, id string, data inter
 	return nil
 }
 
+// UpdateByIdIf updates a record by ID in a single statement whose WHERE clause holds the conditions
+func (c *SQLiteCollection) UpdateByIdIf(ctx context.Context, id string, conditions []Condition, data interface{}) error {
+	// Get database connection
+	conn, err := c.db.GetConn(ctx)
+	if err != nil {
+		return c.internalError(ctx, "UpdateByIdIf", err)
+	}
+
+	// Extract fields to update
+	updateFields, values, err := extractFieldsForUpdate(data)
+	if err != nil {
+		return err
+	}
+
+	whereClause, conditionValues, err := buildConditionClause(conditions)
+	if err != nil {
+		return err
+	}
+
+	// Build query
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s = ?",
+		c.tableName,
+		strings.Join(updateFields, ", "),
+		c.primaryKey,
+	)
+	values = append(values, id)
+	if whereClause != "" {
+		query += " AND " + whereClause
+		values = append(values, conditionValues...)
+	}
+
+	// Execute query
+	result, err := conn.ExecContext(ctx, query, values...)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("%w: %v", ErrDuplicate, err)
+		}
+		return c.internalError(ctx, "UpdateByIdIf", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return c.internalError(ctx, "UpdateByIdIf", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	// Nothing was updated, either the record is missing or it doesn't match
+	var exists int
+	err = conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ?", c.tableName, c.primaryKey), id).Scan(&exists)
+	if err != nil {
+		return c.internalError(ctx, "UpdateByIdIf", err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: id %s", ErrNotFound, id)
+	}
+	return fmt.Errorf("%w: id %s", ErrConflict, id)
+}
+
 // Delete removes a document/record by ID
 func (c *SQLiteCollection) DeleteById(ctx context.Context, id string) error {
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "DeleteById", err)
 	}
 
 	// Build query
@@ -499,13 +816,13 @@ func (c *SQLiteCollection) DeleteById(ctx context.Context, id string) error {
 	// Execute query
 	result, err := conn.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "DeleteById", err)
 	}
 
 	// Check if any rows were affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternal, err)
+		return c.internalError(ctx, "DeleteById", err)
 	}
 
 	if rowsAffected == 0 {
@@ -520,7 +837,7 @@ func (c *SQLiteCollection) Count(ctx context.Context, filter map[string]interfac
 	// Get database connection
 	conn, err := c.db.GetConn(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+		return 0, c.internalError(ctx, "Count", err)
 	}
 
 	// Build query
@@ -534,7 +851,7 @@ func (c *SQLiteCollection) Count(ctx context.Context, filter map[string]interfac
 	var count int64
 	err = conn.QueryRowContext(ctx, query, values...).Scan(&count)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", ErrInternal, err)
+		return 0, c.internalError(ctx, "Count", err)
 	}
 
 	return count, nil
@@ -695,6 +1012,39 @@ func buildWhereClause(filter map[string]interface{}) (string, []interface{}) {
 	return strings.Join(whereClauses, " AND "), values
 }
 
+// sqlOperators maps the operators of a Condition to SQL
+var sqlOperators = map[string]string{
+	"==": "=",
+	"<":  "<",
+	"<=": "<=",
+	">":  ">",
+	">=": ">=",
+}
+
+// Build WHERE clause and values for query conditions. Times are compared in UTC, the zone they
+// are stored in.
+func buildConditionClause(conditions []Condition) (string, []interface{}, error) {
+	whereClauses := make([]string, 0, len(conditions))
+	values := make([]interface{}, 0, len(conditions))
+
+	for _, condition := range conditions {
+		operator, ok := sqlOperators[condition.Operator]
+		if !ok {
+			return "", nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidInput, condition.Operator)
+		}
+
+		value := condition.Value
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC()
+		}
+
+		whereClauses = append(whereClauses, fmt.Sprintf("%s %s ?", condition.Field, operator))
+		values = append(values, value)
+	}
+
+	return strings.Join(whereClauses, " AND "), values, nil
+}
+
 // Validate that result is a pointer to a struct
 func validateResultType(result interface{}) error {
 	resultValue := reflect.ValueOf(result)
@@ -799,7 +1149,10 @@ func setFieldValue(fieldValue reflect.Value, val interface{}) {
 	case reflect.Struct:
 		// Handle time.Time specially
 		if fieldValue.Type() == reflect.TypeOf(time.Time{}) {
-			if timeStr, ok := val.(string); ok {
+			// The driver already parses columns declared as TIMESTAMP
+			if t, ok := val.(time.Time); ok {
+				fieldValue.Set(reflect.ValueOf(t))
+			} else if timeStr, ok := val.(string); ok {
 				if t, err := time.Parse(time.RFC3339, timeStr); err == nil {
 					fieldValue.Set(reflect.ValueOf(t))
 				}
@@ -120,7 +120,7 @@ func TestSQLiteDatabaseMigrations(t *testing.T) {
 	require.True(t, ok, "Failed to cast to SQLiteDatabase")
 
 	// Verify tables exist by querying the SQLite master table
-	tables := []string{"users", "reminders", "reminder_groups"}
+	tables := []string{"users", "reminders", "reminder_groups", "api_tokens", "user_identities", "login_attempts", "email_changes", "magic_links", "export_jobs", "audit_events", "audit_chain", "counters"}
 	for _, table := range tables {
 		var name string
 		err := sqliteDB.conn.QueryRowContext(ctx,
@@ -131,6 +131,45 @@ func TestSQLiteDatabaseMigrations(t *testing.T) {
 	}
 }
 
+// TestSQLiteDatabaseMigrationsAddColumns tests that migrating a database created before a column existed adds it
+func TestSQLiteDatabaseMigrationsAddColumns(t *testing.T) {
+	db, cleanup := setupDatabase(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	sqliteDB, ok := db.(*SQLiteDatabase)
+	require.True(t, ok, "Failed to cast to SQLiteDatabase")
+
+	// Recreate the users table as it was before the status column
+	_, err := sqliteDB.conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF")
+	require.NoError(t, err)
+	_, err = sqliteDB.conn.ExecContext(ctx, "DROP TABLE users")
+	require.NoError(t, err)
+	_, err = sqliteDB.conn.ExecContext(ctx, `CREATE TABLE users (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'user',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`)
+	require.NoError(t, err)
+	_, err = sqliteDB.conn.ExecContext(ctx, "INSERT INTO users (id, username, email, password) VALUES ('old', 'old', 'old@example.com', 'hash')")
+	require.NoError(t, err)
+
+	// Migrating twice must not fail on the now existing columns
+	require.NoError(t, db.Migrate(ctx))
+	require.NoError(t, db.Migrate(ctx))
+
+	var status string
+	var tokenVersion int
+	err = sqliteDB.conn.QueryRowContext(ctx, "SELECT status, token_version FROM users WHERE id = 'old'").Scan(&status, &tokenVersion)
+	require.NoError(t, err)
+	assert.Equal(t, "active", status)
+	assert.Equal(t, 0, tokenVersion)
+}
+
 // TestSQLiteDatabaseSeed tests the database seeding process
 func TestSQLiteDatabaseSeed(t *testing.T) {
 	db, cleanup := setupDatabase(t)
@@ -882,3 +921,143 @@ func TestSQLiteCollectionGetOne(t *testing.T) {
 	err = usersCollection.GetOne(ctx, map[string]interface{}{"username": "user1"}, &invalidResult)
 	assert.Error(t, err, "Expected error for invalid result type pointer")
 }
+
+// TestSQLiteCollectionFind tests the Find method of SQLiteCollection
+func TestSQLiteCollectionFind(t *testing.T) {
+	db, cleanup := setupDatabase(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	usersCollection := db.Collection("users")
+
+	// Users created a minute apart
+	start := time.Now().UTC().Truncate(time.Second)
+	for i, name := range []string{"first", "second", "third", "fourth"} {
+		_, err := usersCollection.Create(ctx, TestUser{
+			ID:        uuid.New().String(),
+			Username:  name,
+			Email:     name + "@example.com",
+			Password:  "password123",
+			Role:      "user",
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+			UpdatedAt: start,
+		})
+		require.NoError(t, err)
+	}
+
+	usernames := func(users []TestUser) []string {
+		names := make([]string, 0, len(users))
+		for _, user := range users {
+			names = append(names, user.Username)
+		}
+		return names
+	}
+
+	var users []TestUser
+	err := usersCollection.Find(ctx, Query{}.Where("created_at", ">=", start.Add(time.Minute)).Where("role", "==", "user"), &users)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"second", "third", "fourth"}, usernames(users))
+
+	err = usersCollection.Find(ctx, Query{OrderBy: "created_at", Descending: true, Limit: 2}.Where("created_at", "<", start.Add(3*time.Minute)), &users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"third", "second"}, usernames(users))
+
+	err = usersCollection.Find(ctx, Query{OrderBy: "created_at", Offset: 1, Limit: 2}, &users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"second", "third"}, usernames(users))
+
+	err = usersCollection.Find(ctx, Query{OrderBy: "created_at", Offset: 3}, &users)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fourth"}, usernames(users))
+
+	count, err := usersCollection.CountByQuery(ctx, Query{Limit: 1}.Where("created_at", ">", start))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count, "the limit doesn't apply to counts")
+
+	err = usersCollection.Find(ctx, Query{}.Where("username", "LIKE", "%"), &users)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// TestSQLiteCollectionUpdateByIdIf tests that conditional updates only apply to matching records
+func TestSQLiteCollectionUpdateByIdIf(t *testing.T) {
+	db, cleanup := setupDatabase(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	usersCollection := db.Collection("users")
+
+	userID := uuid.New().String()
+	_, err := usersCollection.Create(ctx, TestUser{
+		ID:        userID,
+		Username:  "conditional",
+		Email:     "conditional@example.com",
+		Password:  "password123",
+		Role:      "user",
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	isUser := []Condition{{Field: "role", Operator: "==", Value: "user"}}
+
+	err = usersCollection.UpdateByIdIf(ctx, userID, isUser, TestUser{Role: "admin"})
+	require.NoError(t, err)
+
+	// The record no longer matches
+	err = usersCollection.UpdateByIdIf(ctx, userID, isUser, TestUser{Username: "demoted", Role: "user"})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	var foundUser TestUser
+	require.NoError(t, usersCollection.GetById(ctx, userID, &foundUser))
+	assert.Equal(t, "admin", foundUser.Role)
+	assert.Equal(t, "conditional", foundUser.Username)
+
+	err = usersCollection.UpdateByIdIf(ctx, "non-existent-id", isUser, TestUser{Role: "admin"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestSQLiteCounters tests atomic counter increments and their expiry
+func TestSQLiteCounters(t *testing.T) {
+	db, cleanup := setupDatabase(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	counters, ok := db.(Counters)
+	require.True(t, ok, "SQLiteDatabase should implement Counters")
+
+	value, err := counters.IncrementCounter(ctx, "live", 1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	value, err = counters.IncrementCounter(ctx, "live", 2, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), value)
+
+	// An expired counter starts over
+	_, err = counters.IncrementCounter(ctx, "expired", 5, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	value, err = counters.IncrementCounter(ctx, "expired", 1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	// Concurrent increments are never lost
+	done := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		go func() {
+			_, err := counters.IncrementCounter(ctx, "concurrent", 1, time.Now().Add(time.Hour))
+			done <- err
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		require.NoError(t, <-done)
+	}
+	value, err = counters.IncrementCounter(ctx, "concurrent", 0, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), value)
+
+	_, err = counters.IncrementCounter(ctx, "old", 1, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	deleted, err := counters.DeleteExpiredCounters(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+)
+
+const (
+	// APITokenPrefix marks a bearer token as a personal access token rather than a JWT
+	APITokenPrefix = "rmd_pat_"
+	// apiTokenScopeSeparator joins scopes into a single column value
+	apiTokenScopeSeparator = " "
+)
+
+// Scopes that can be granted to a personal access token
+const (
+	ScopeUsersRead      = "users:read"
+	ScopeUsersWrite     = "users:write"
+	ScopeRemindersRead  = "reminders:read"
+	ScopeRemindersWrite = "reminders:write"
+	ScopeGroupsRead     = "groups:read"
+	ScopeGroupsWrite    = "groups:write"
+)
+
+// apiTokenResources lists the resources a scope may refer to, used to validate "resource:*" scopes
+var apiTokenResources = map[string][]string{
+	"users":     {ScopeUsersRead, ScopeUsersWrite},
+	"reminders": {ScopeRemindersRead, ScopeRemindersWrite},
+	"groups":    {ScopeGroupsRead, ScopeGroupsWrite},
+}
+
+// APIToken represents a personal access token. Only the hash of the token is stored.
+type APIToken struct {
+	ID         string    `json:"id" db:"id" firestore:"id"`
+	UserID     string    `json:"userId" db:"user_id" firestore:"user_id"`
+	Name       string    `json:"name" db:"name" firestore:"name"`
+	TokenHash  string    `json:"-" db:"token_hash" firestore:"token_hash"`
+	Prefix     string    `json:"prefix" db:"prefix" firestore:"prefix"` // First characters of the token, shown to help users identify it
+	Scopes     string    `json:"scopes" db:"scopes" firestore:"scopes"` // Space separated list of scopes
+	ExpiresAt  time.Time `json:"expiresAt" db:"expires_at" firestore:"expires_at"`
+	LastUsedAt time.Time `json:"lastUsedAt" db:"last_used_at" firestore:"last_used_at"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+// ScopeList returns the scopes granted to the token, wildcards expanded to the scopes they grant
+func (t *APIToken) ScopeList() []string {
+	return ExpandScopes(strings.Fields(t.Scopes))
+}
+
+// IsExpired reports whether the token has an expiry that has passed
+func (t *APIToken) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt)
+}
+
+// JoinScopes joins scopes into the format stored on an APIToken
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, apiTokenScopeSeparator)
+}
+
+// ValidateScopes checks that every scope is known or a wildcard over a known resource
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrInvalidScope
+	}
+
+	for _, scope := range scopes {
+		if !isKnownScope(scope) {
+			return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	return nil
+}
+
+// ExpandScopes replaces the "resource:*" and "*" wildcards with the read and write scopes they
+// grant, so a token scope can never match a permission such as users:manage. Unknown scopes are
+// dropped and duplicates removed.
+func ExpandScopes(scopes []string) []string {
+	expanded := make([]string, 0, len(scopes))
+	add := func(scope string) {
+		if !slices.Contains(expanded, scope) {
+			expanded = append(expanded, scope)
+		}
+	}
+
+	for _, scope := range scopes {
+		resource, action, _ := strings.Cut(scope, ":")
+		switch {
+		case scope == auth.ScopeWildcard:
+			for _, resource := range apiTokenResourceNames() {
+				for _, s := range apiTokenResources[resource] {
+					add(s)
+				}
+			}
+		case action == auth.ScopeWildcard:
+			for _, s := range apiTokenResources[resource] {
+				add(s)
+			}
+		case isKnownScope(scope):
+			add(scope)
+		}
+	}
+	return expanded
+}
+
+// apiTokenResourceNames returns the resources of apiTokenResources, sorted
+func apiTokenResourceNames() []string {
+	names := make([]string, 0, len(apiTokenResources))
+	for resource := range apiTokenResources {
+		names = append(names, resource)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func isKnownScope(scope string) bool {
+	resource, action, ok := strings.Cut(scope, ":")
+	if !ok {
+		return false
+	}
+
+	known, ok := apiTokenResources[resource]
+	if !ok {
+		return false
+	}
+
+	if action == auth.ScopeWildcard {
+		return true
+	}
+
+	for _, s := range known {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+var ErrAPITokenNotFound = errors.New("api token not found")
+var ErrInvalidAPIToken = errors.New("invalid api token")
+var ErrInvalidScope = errors.New("invalid scope")
+var ErrScopeNotHeld = errors.New("a personal access token can only grant scopes it holds")
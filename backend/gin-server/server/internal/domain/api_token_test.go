@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandScopes(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected []string
+	}{
+		{"Plain Scopes", []string{ScopeUsersRead, ScopeGroupsWrite}, []string{ScopeUsersRead, ScopeGroupsWrite}},
+		{"Resource Wildcard", []string{"reminders:*"}, []string{ScopeRemindersRead, ScopeRemindersWrite}},
+		{"Global Wildcard", []string{"*"}, []string{ScopeGroupsRead, ScopeGroupsWrite, ScopeRemindersRead, ScopeRemindersWrite, ScopeUsersRead, ScopeUsersWrite}},
+		{"Duplicates Removed", []string{ScopeUsersRead, "users:*"}, []string{ScopeUsersRead, ScopeUsersWrite}},
+		{"Unknown Dropped", []string{"users:manage", "billing:*", ScopeGroupsRead}, []string{ScopeGroupsRead}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ExpandScopes(tt.scopes))
+		})
+	}
+}
+
+func TestAPITokenScopeListExpandsStoredWildcards(t *testing.T) {
+	token := &APIToken{Scopes: "users:*"}
+
+	assert.Equal(t, []string{ScopeUsersRead, ScopeUsersWrite}, token.ScopeList())
+}
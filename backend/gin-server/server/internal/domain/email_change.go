@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// EmailChange is a pending change of a user's email, applied once the new address is confirmed.
+// Only the hash of the confirmation token is stored.
+type EmailChange struct {
+	ID        string    `json:"id" db:"id" firestore:"id"`
+	UserID    string    `json:"userId" db:"user_id" firestore:"user_id"`
+	NewEmail  string    `json:"newEmail" db:"new_email" firestore:"new_email"`
+	TokenHash string    `json:"-" db:"token_hash" firestore:"token_hash"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at" firestore:"expires_at"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+// IsExpired reports whether the confirmation token can no longer be used
+func (e *EmailChange) IsExpired(now time.Time) bool {
+	return !now.Before(e.ExpiresAt)
+}
+
+var ErrInvalidEmailChangeToken = errors.New("invalid or expired email confirmation token")
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// ExportJobStatusPending is a job waiting for its worker to start
+	ExportJobStatusPending = "pending"
+	// ExportJobStatusRunning is a job whose archive is being assembled
+	ExportJobStatusRunning = "running"
+	// ExportJobStatusDone is a job whose archive can be downloaded until it expires
+	ExportJobStatusDone = "done"
+	// ExportJobStatusFailed is a job that could not produce an archive
+	ExportJobStatusFailed = "failed"
+)
+
+// ExportJob is a background job assembling a ZIP archive of a user's personal data.
+// The archive lives on local disk until ExpiresAt.
+type ExportJob struct {
+	ID          string    `json:"id" db:"id" firestore:"id"`
+	UserID      string    `json:"userId" db:"user_id" firestore:"user_id"`
+	Status      string    `json:"status" db:"status" firestore:"status"`
+	Error       string    `json:"error,omitempty" db:"error" firestore:"error"`
+	FilePath    string    `json:"-" db:"file_path" firestore:"file_path"`
+	Size        int64     `json:"size,omitempty" db:"size" firestore:"size"`
+	CompletedAt time.Time `json:"completedAt,omitempty" db:"completed_at" firestore:"completed_at"`
+	ExpiresAt   time.Time `json:"expiresAt,omitempty" db:"expires_at" firestore:"expires_at"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+// ExportClaim marks the export a user has in progress, there is at most one per user. Starting an
+// export takes over the claim atomically, so concurrent requests can't both start one.
+type ExportClaim struct {
+	ID        string    `json:"id" db:"id" firestore:"id"` // ID of the user
+	JobID     string    `json:"jobId" db:"job_id" firestore:"job_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+}
+
+// IsActive reports whether the job has not finished yet
+func (j *ExportJob) IsActive() bool {
+	return j.Status == ExportJobStatusPending || j.Status == ExportJobStatusRunning
+}
+
+// IsExpired reports whether the archive of a finished job has passed its retention
+func (j *ExportJob) IsExpired(now time.Time) bool {
+	return !j.ExpiresAt.IsZero() && !now.Before(j.ExpiresAt)
+}
+
+var (
+	ErrExportJobNotFound   = errors.New("export job not found")
+	ErrExportInProgress    = errors.New("an export is already in progress")
+	ErrExportNotReady      = errors.New("export is not ready")
+	ErrInvalidDownloadLink = errors.New("invalid or expired download link")
+)
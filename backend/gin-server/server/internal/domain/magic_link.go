@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// MagicLink is a single use passwordless login link sent by email. Only hashes of the link token
+// and of the optional device nonce are stored.
+type MagicLink struct {
+	ID         string    `json:"id" db:"id" firestore:"id"`
+	UserID     string    `json:"userId" db:"user_id" firestore:"user_id"`
+	Email      string    `json:"email" db:"email" firestore:"email"`
+	TokenHash  string    `json:"-" db:"token_hash" firestore:"token_hash"`
+	DeviceHash string    `json:"-" db:"device_hash" firestore:"device_hash"` // Set when the link only works on the requesting device
+	ExpiresAt  time.Time `json:"expiresAt" db:"expires_at" firestore:"expires_at"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+// IsExpired reports whether the link can no longer be used
+func (l *MagicLink) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// IsDeviceBound reports whether the link must be used on the device that requested it
+func (l *MagicLink) IsDeviceBound() bool {
+	return l.DeviceHash != ""
+}
+
+var ErrInvalidMagicLink = errors.New("invalid or expired login link")
+var ErrTooManyMagicLinks = errors.New("too many login links requested")
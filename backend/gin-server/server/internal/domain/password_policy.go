@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//go:embed breached_passwords.txt
+var breachedPasswordsFile string
+
+// breachedPasswords is the lower cased set of the bundled breached passwords
+var breachedPasswords = func() map[string]struct{} {
+	passwords := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(breachedPasswordsFile))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+
+	return passwords
+}()
+
+// Codes of the password policy violations, stable for API clients
+const (
+	PasswordTooShort       = "too_short"
+	PasswordTooLong        = "too_long"
+	PasswordMissingClasses = "missing_character_classes"
+	PasswordBreached       = "breached"
+	PasswordContainsEmail  = "contains_email"
+)
+
+const (
+	// passwordMaxLength bounds the work spent hashing a password
+	passwordMaxLength = 128
+	// passwordEmailMinFragment avoids rejecting passwords for containing very short local parts
+	passwordEmailMinFragment = 3
+)
+
+// PasswordPolicy describes the requirements for new passwords
+type PasswordPolicy struct {
+	MinLength      int  // Minimum number of characters
+	MinCharClasses int  // Minimum number of lower case, upper case, digit and symbol classes used
+	RejectBreached bool // Reject passwords from the bundled breached password list
+}
+
+// DefaultPasswordPolicy returns the policy used when nothing is configured
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:      10,
+		MinCharClasses: 3,
+		RejectBreached: true,
+	}
+}
+
+// PasswordViolation is a single unmet requirement
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError lists every requirement a password does not meet
+type PasswordPolicyError struct {
+	Violations []PasswordViolation `json:"violations"`
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+	return "password does not meet the policy: " + strings.Join(messages, "; ")
+}
+
+// Validate returns a *PasswordPolicyError when the password does not meet the policy.
+// The email of the account is used to reject passwords derived from it.
+func (p PasswordPolicy) Validate(password, email string) error {
+	var violations []PasswordViolation
+	length := len([]rune(password))
+
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{PasswordTooShort, fmt.Sprintf("must be at least %d characters long", p.MinLength)})
+	}
+
+	if length > passwordMaxLength {
+		violations = append(violations, PasswordViolation{PasswordTooLong, fmt.Sprintf("must be at most %d characters long", passwordMaxLength)})
+	}
+
+	if classes := countCharClasses(password); classes < p.MinCharClasses {
+		violations = append(violations, PasswordViolation{PasswordMissingClasses, fmt.Sprintf("must use at least %d of lower case letters, upper case letters, digits and symbols", p.MinCharClasses)})
+	}
+
+	lowered := strings.ToLower(password)
+	if p.RejectBreached {
+		if _, breached := breachedPasswords[lowered]; breached {
+			violations = append(violations, PasswordViolation{PasswordBreached, "is a commonly used password found in data breaches"})
+		}
+	}
+
+	if containsEmail(lowered, strings.ToLower(email)) {
+		violations = append(violations, PasswordViolation{PasswordContainsEmail, "must not contain your email address"})
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func countCharClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// containsEmail reports whether the password contains the email or its local part
+func containsEmail(password, email string) bool {
+	if email == "" {
+		return false
+	}
+
+	if strings.Contains(password, email) {
+		return true
+	}
+
+	local, _, _ := strings.Cut(email, "@")
+	return len(local) >= passwordEmailMinFragment && strings.Contains(password, local)
+}
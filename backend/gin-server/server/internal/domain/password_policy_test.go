@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violationCodes(t *testing.T, err error) []string {
+	var policyErr *PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr), "expected a policy error, got %v", err)
+
+	codes := make([]string, 0, len(policyErr.Violations))
+	for _, v := range policyErr.Violations {
+		codes = append(codes, v.Code)
+	}
+	return codes
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+
+	// Test cases
+	tests := []struct {
+		name     string
+		password string
+		expected []string
+	}{
+		{"Too Short", "Ab1!", []string{PasswordTooShort}},
+		{"Too Few Classes", "onlylowercaseletters", []string{PasswordMissingClasses}},
+		{"Breached", "Password123!", []string{PasswordBreached}},
+		{"Contains Email Local Part", "Jane.Doe-2025!", []string{PasswordContainsEmail}},
+		{"Multiple Violations", "jane.doe", []string{PasswordTooShort, PasswordMissingClasses, PasswordContainsEmail}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, "jane.doe@example.com")
+			assert.Equal(t, tt.expected, violationCodes(t, err))
+		})
+	}
+
+	assert.NoError(t, policy.Validate("Tr0ub4dor&Horse", "jane.doe@example.com"))
+}
+
+func TestPasswordPolicyIsConfigurable(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 4, MinCharClasses: 1, RejectBreached: false}
+
+	assert.NoError(t, policy.Validate("password", "someone@example.com"))
+	assert.Equal(t, []string{PasswordTooLong}, violationCodes(t, policy.Validate(string(make([]rune, 129)), "")))
+}
@@ -3,10 +3,10 @@ package domain
 import "time"
 
 type ReminderGroup struct {
-	ID          string    `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	UserID      string    `json:"userId" db:"user_id"` // Creator of the group
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	ID          string    `json:"id" db:"id" firestore:"id"`
+	Name        string    `json:"name" db:"name" firestore:"name"`
+	Description string    `json:"description" db:"description" firestore:"description"`
+	UserID      string    `json:"userId" db:"user_id" firestore:"user_id"` // Creator of the group
+	CreatedAt   time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
 }
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+)
+
+// Permissions checked by RequirePermission. They share the "resource:action" format of
+// token scopes, so the user facing scopes double as permissions.
+const (
+	PermissionUsersRead      = ScopeUsersRead
+	PermissionUsersWrite     = ScopeUsersWrite
+	PermissionUsersManage    = "users:manage"
+	PermissionRemindersRead  = ScopeRemindersRead
+	PermissionRemindersWrite = ScopeRemindersWrite
+	PermissionGroupsRead     = ScopeGroupsRead
+	PermissionGroupsWrite    = ScopeGroupsWrite
+)
+
+// NewRoleRegistry returns the permissions granted to each user role
+func NewRoleRegistry() *auth.RoleRegistry {
+	return auth.NewRoleRegistry().
+		Register(UserRoleAdmin, auth.ScopeWildcard).
+		Register(UserRoleUser,
+			PermissionUsersRead,
+			PermissionUsersWrite,
+			PermissionRemindersRead,
+			PermissionRemindersWrite,
+			PermissionGroupsRead,
+			PermissionGroupsWrite,
+		)
+}
+
+var ErrInvalidRole = errors.New("invalid role")
+var ErrCannotChangeOwnRole = errors.New("cannot change your own role")
@@ -1,9 +1,9 @@
 package domain
 
 import (
-	"time"
-
 	"errors"
+	"regexp"
+	"time"
 )
 
 const (
@@ -13,17 +13,49 @@ const (
 	UserRoleUser = "user"
 )
 
+const (
+	// UserStatusActive is a user allowed to sign in, users stored without a status are active
+	UserStatusActive = "active"
+	// UserStatusDisabled is a user blocked by an admin from signing in and using existing sessions
+	UserStatusDisabled = "disabled"
+)
+
 // User represents a user in the system
 type User struct {
-	ID        string    `json:"id" db:"id" firestore:"id"`
-	Username  string    `json:"username" db:"username" firestore:"username"`
-	Password  string    `json:"password" db:"password" firestore:"password"`
-	Role      string    `json:"role" db:"role" firestore:"role"`
-	Email     string    `json:"email" db:"email" firestore:"email"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+	ID           string    `json:"id" db:"id" firestore:"id"`
+	Username     string    `json:"username" db:"username" firestore:"username"`
+	Password     string    `json:"-" db:"password" firestore:"password"` // Never serialized, responses use response.UserPublic
+	Role         string    `json:"role" db:"role" firestore:"role"`
+	Email        string    `json:"email" db:"email" firestore:"email"`
+	Status       string    `json:"status" db:"status" firestore:"status"`
+	TokenVersion int       `json:"-" db:"token_version" firestore:"token_version"` // Embedded in issued tokens, incrementing it revokes every session
+	CreatedAt    time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+// IsDisabled reports whether the user was disabled by an admin
+func (u *User) IsDisabled() bool {
+	return u.Status == UserStatusDisabled
 }
 
 var ErrUserAlreadyExist = errors.New("user already exists")
 var ErrInvalidCredentials = errors.New("invalid credentials")
 var ErrUserNotFound = errors.New("user not found")
+var ErrUsernameTaken = errors.New("username is already taken")
+var ErrInvalidUsername = errors.New("username must be 3 to 50 characters of letters, digits, '.', '_' or '-' and start with a letter or digit")
+var ErrEmailAlreadyInUse = errors.New("email is already in use")
+var ErrAccountDisabled = errors.New("account is disabled")
+var ErrSessionRevoked = errors.New("session has been revoked")
+var ErrCannotManageSelf = errors.New("admins cannot disable or delete their own account")
+var ErrSessionRequired = errors.New("this action requires signing in, personal access tokens are not accepted")
+
+// usernamePattern matches generated usernames like "swift-sassy-panther-1712938493" as well as chosen ones
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{2,49}$`)
+
+// ValidateUsername checks the format of a username chosen by a user
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrInvalidUsername
+	}
+	return nil
+}
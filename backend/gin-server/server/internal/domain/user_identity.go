@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// UserIdentity links a user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID        string    `json:"id" db:"id" firestore:"id"`
+	UserID    string    `json:"userId" db:"user_id" firestore:"user_id"`
+	Provider  string    `json:"provider" db:"provider" firestore:"provider"`
+	Subject   string    `json:"subject" db:"subject" firestore:"subject"`
+	Email     string    `json:"email" db:"email" firestore:"email"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at" firestore:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at" firestore:"updated_at"`
+}
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+var ErrInvalidOIDCState = errors.New("invalid or expired login state")
+var ErrUnverifiedEmail = errors.New("identity provider did not verify the email address")
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// dbBuckets are finer than DefaultBuckets, most database operations take a few milliseconds
+var dbBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Metrics are the metrics of the server, registered in Registry
+type Metrics struct {
+	Registry *Registry
+
+	// HTTP
+	HTTPRequestDuration  *HistogramVec // method, route, status
+	HTTPRequestsInFlight Gauge
+
+	// Database
+	DBOperationDuration *HistogramVec // backend, collection, operation
+	DBOperationErrors   *CounterVec   // backend, collection, operation
+
+	// Rate limiting
+	RateLimitRejections *CounterVec // tier
+
+	// Business events
+	Registrations    Counter
+	Logins           *CounterVec // outcome
+	RemindersCreated Counter
+}
+
+// New creates the metrics of the server in a new registry
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry: r,
+
+		HTTPRequestDuration:  r.Histogram("http_request_duration_seconds", "Duration of HTTP requests by route template and status.", nil, "method", "route", "status"),
+		HTTPRequestsInFlight: r.Gauge("http_requests_in_flight", "HTTP requests being served.").With(),
+
+		DBOperationDuration: r.Histogram("db_operation_duration_seconds", "Duration of database collection operations.", dbBuckets, "backend", "collection", "operation"),
+		DBOperationErrors:   r.Counter("db_operation_errors_total", "Failed database collection operations, not found results excluded.", "backend", "collection", "operation"),
+
+		RateLimitRejections: r.Counter("ratelimit_rejections_total", "Requests rejected for exceeding their rate limit tier.", "tier"),
+
+		Registrations:    r.Counter("user_registrations_total", "Users registered with an email and password.").With(),
+		Logins:           r.Counter("user_logins_total", "Logins with a password or magic link by outcome.", "outcome"),
+		RemindersCreated: r.Counter("reminders_created_total", "Reminders created.").With(),
+	}
+}
+
+// DBObserver returns the observer recording the collection operations of an instrumented database
+// of the given backend, see db.Instrument
+func (m *Metrics) DBObserver(backend string) db.OperationObserver {
+	return func(collection, operation string, duration time.Duration, err error) {
+		m.DBOperationDuration.With(backend, collection, operation).Observe(duration.Seconds())
+		if err != nil && !errors.Is(err, db.ErrNotFound) {
+			m.DBOperationErrors.With(backend, collection, operation).Inc()
+		}
+	}
+}
+
+// RateLimitRejected counts a request rejected by the rate limiter, see ratelimit.WithOnReject
+func (m *Metrics) RateLimitRejected(tier string) {
+	m.RateLimitRejections.With(tier).Inc()
+}
+
+// ObserveCaches exposes the hits, misses and hit ratio of the cached repositories, read from their
+// statistics on every scrape
+func (m *Metrics) ObserveCaches(repositories []repository.CachedRepository) {
+	cacheStats := func(observe func(value float64, labelValues ...string), value func(repository.CacheStats) float64) {
+		for _, repo := range repositories {
+			observe(value(repo.CacheStats()), repo.CacheName())
+		}
+	}
+
+	m.Registry.Collect("cache_hits_total", "Repository cache lookups served from the cache, cached not found results included.", KindCounter, []string{"cache"}, func(observe func(float64, ...string)) {
+		cacheStats(observe, func(stats repository.CacheStats) float64 { return float64(stats.Hits + stats.NegativeHits) })
+	})
+	m.Registry.Collect("cache_misses_total", "Repository cache lookups that missed the cache.", KindCounter, []string{"cache"}, func(observe func(float64, ...string)) {
+		cacheStats(observe, func(stats repository.CacheStats) float64 { return float64(stats.Misses) })
+	})
+	m.Registry.Collect("cache_hit_ratio", "Share of repository cache lookups served from the cache since the start.", KindGauge, []string{"cache"}, func(observe func(float64, ...string)) {
+		cacheStats(observe, func(stats repository.CacheStats) float64 { return stats.HitRatio })
+	})
+}
+
+// CountAuditEvents wraps trail so the business events recorded in it, such as registrations and
+// logins, are counted as well
+func (m *Metrics) CountAuditEvents(trail audit.Trail) audit.Trail {
+	return &countingTrail{Trail: trail, metrics: m}
+}
+
+// countingTrail counts business events as they are recorded. The action has already happened when
+// it is recorded, so events are counted even if recording them fails.
+type countingTrail struct {
+	audit.Trail
+	metrics *Metrics
+}
+
+func (t *countingTrail) Record(ctx context.Context, event *audit.Event) error {
+	err := t.Trail.Record(ctx, event)
+
+	switch event.Action {
+	case audit.ActionRegister:
+		t.metrics.Registrations.Inc()
+	case audit.ActionLogin:
+		outcome := event.Outcome
+		if outcome == "" {
+			outcome = audit.OutcomeSuccess
+		}
+		t.metrics.Logins.With(outcome).Inc()
+	case audit.ActionReminderCreate:
+		t.metrics.RemindersCreated.Inc()
+	}
+	return err
+}
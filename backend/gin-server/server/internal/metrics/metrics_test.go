@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTrail fails to record every event
+type failingTrail struct {
+	audit.Trail
+}
+
+func (failingTrail) Record(ctx context.Context, event *audit.Event) error {
+	return errors.New("database is locked")
+}
+
+// staticCache is a cached repository with fixed statistics
+type staticCache struct {
+	name  string
+	stats repository.CacheStats
+}
+
+func (c staticCache) CacheName() string                 { return c.name }
+func (c staticCache) CacheStats() repository.CacheStats { return c.stats }
+
+func assertValue(t *testing.T, m *Metrics, expected float64, name string, labelValues ...string) {
+	t.Helper()
+	value, ok := m.Registry.Value(name, labelValues...)
+	require.True(t, ok, "%s%v is missing", name, labelValues)
+	assert.Equal(t, expected, value, "%s%v", name, labelValues)
+}
+
+func TestCountAuditEvents(t *testing.T) {
+	m := New()
+	trail := m.CountAuditEvents(failingTrail{})
+	ctx := context.Background()
+
+	for _, event := range []*audit.Event{
+		{Action: audit.ActionRegister},
+		{Action: audit.ActionLogin},
+		{Action: audit.ActionLogin, Outcome: audit.OutcomeSuccess},
+		{Action: audit.ActionLogin, Outcome: audit.OutcomeFailure},
+		{Action: audit.ActionReminderCreate},
+		{Action: audit.ActionReminderDelete},
+	} {
+		assert.Error(t, trail.Record(ctx, event), "recording errors are returned")
+	}
+
+	// Events are counted even when recording them fails
+	assert.Equal(t, 1.0, m.Registrations.Value())
+	assert.Equal(t, 1.0, m.RemindersCreated.Value())
+	assertValue(t, m, 2, "user_logins_total", audit.OutcomeSuccess)
+	assertValue(t, m, 1, "user_logins_total", audit.OutcomeFailure)
+}
+
+func TestDBObserver(t *testing.T) {
+	m := New()
+	observe := m.DBObserver("sqlite")
+
+	observe("users", db.OpGetById, 2*time.Millisecond, nil)
+	observe("users", db.OpGetById, time.Millisecond, fmt.Errorf("user 1: %w", db.ErrNotFound))
+	observe("users", db.OpUpdateById, time.Millisecond, db.ErrInternal)
+
+	assertValue(t, m, 2, "db_operation_duration_seconds", "sqlite", "users", db.OpGetById)
+	assertValue(t, m, 1, "db_operation_duration_seconds", "sqlite", "users", db.OpUpdateById)
+	assertValue(t, m, 1, "db_operation_errors_total", "sqlite", "users", db.OpUpdateById)
+	_, ok := m.Registry.Value("db_operation_errors_total", "sqlite", "users", db.OpGetById)
+	assert.False(t, ok, "not found results aren't errors")
+}
+
+func TestObserveCaches(t *testing.T) {
+	m := New()
+	users := &staticCache{name: "users", stats: repository.CacheStats{Hits: 6, NegativeHits: 2, Misses: 2, HitRatio: 0.8}}
+	m.ObserveCaches([]repository.CachedRepository{users})
+
+	assertValue(t, m, 8, "cache_hits_total", "users")
+	assertValue(t, m, 2, "cache_misses_total", "users")
+	assertValue(t, m, 0.8, "cache_hit_ratio", "users")
+
+	// Statistics are read on every scrape
+	users.stats.Misses = 3
+	var sb strings.Builder
+	require.NoError(t, m.Registry.Write(&sb))
+	assert.Contains(t, sb.String(), `cache_misses_total{cache="users"} 3`)
+}
+
+func TestRateLimitRejected(t *testing.T) {
+	m := New()
+	m.RateLimitRejected("auth")
+	m.RateLimitRejected("auth")
+	assertValue(t, m, 2, "ratelimit_rejections_total", "auth")
+}
@@ -0,0 +1,423 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContentType is the content type of the Prometheus text exposition format written by Registry.Write
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of histogram buckets suited to request latencies, in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Kind is the type of a metric family
+type Kind string
+
+const (
+	KindCounter   Kind = "counter"
+	KindGauge     Kind = "gauge"
+	KindHistogram Kind = "histogram"
+)
+
+// labelSeparator joins label values into series keys, it can't appear in valid UTF-8
+const labelSeparator = "\xff"
+
+// Registry holds metric families and writes them in the Prometheus text exposition format.
+// Families are registered once, registering a name twice or with an invalid name panics.
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// family is a metric with its series, one per combination of label values. Collected families
+// compute their samples when written instead.
+type family struct {
+	name       string
+	help       string
+	kind       Kind
+	labelNames []string
+	buckets    []float64
+
+	mu      sync.RWMutex
+	series  map[string]*series
+	collect func(observe func(value float64, labelValues ...string))
+}
+
+// series holds the value of a counter or gauge, or the observations of a histogram
+type series struct {
+	labelValues []string
+	value       atomicFloat
+	counts      []atomic.Uint64 // Per bucket, not cumulative, the last one is +Inf
+	count       atomic.Uint64
+}
+
+// atomicFloat is a float64 updated atomically
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
+
+func (f *atomicFloat) Store(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		if f.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// register adds a family, panicking on invalid or duplicate names since they are programming errors
+func (r *Registry) register(f *family) *family {
+	if !validName(f.name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", f.name))
+	}
+	for _, label := range f.labelNames {
+		if !validName(label) || strings.Contains(label, ":") || (f.kind == KindHistogram && label == "le") {
+			panic(fmt.Sprintf("metrics: invalid label name %q of %s", label, f.name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[f.name]; ok {
+		panic(fmt.Sprintf("metrics: %s is already registered", f.name))
+	}
+	f.series = make(map[string]*series)
+	r.families[f.name] = f
+	return f
+}
+
+// Counter registers a counter, a value that only goes up
+func (r *Registry) Counter(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{r.register(&family{name: name, help: help, kind: KindCounter, labelNames: labelNames})}
+}
+
+// Gauge registers a gauge, a value that goes up and down
+func (r *Registry) Gauge(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{r.register(&family{name: name, help: help, kind: KindGauge, labelNames: labelNames})}
+}
+
+// Histogram registers a histogram counting observations in buckets with the given upper bounds,
+// DefaultBuckets when none are given
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	if math.IsInf(buckets[len(buckets)-1], 1) {
+		buckets = buckets[:len(buckets)-1]
+	}
+	return &HistogramVec{r.register(&family{name: name, help: help, kind: KindHistogram, labelNames: labelNames, buckets: buckets})}
+}
+
+// Collect registers a counter or gauge whose samples are computed by collect every time the
+// registry is written, for values kept elsewhere such as cache statistics
+func (r *Registry) Collect(name, help string, kind Kind, labelNames []string, collect func(observe func(value float64, labelValues ...string))) {
+	if kind == KindHistogram {
+		panic("metrics: histograms can't be collected")
+	}
+	r.register(&family{name: name, help: help, kind: kind, labelNames: labelNames, collect: collect})
+}
+
+// with returns the series of the label values, creating it on first use
+func (f *family) with(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, labelSeparator)
+
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok = f.series[key]; !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == KindHistogram {
+			s.counts = make([]atomic.Uint64, len(f.buckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// CounterVec is a counter family, partitioned by label values
+type CounterVec struct {
+	f *family
+}
+
+// With returns the counter of the label values, in the order of the label names
+func (v *CounterVec) With(labelValues ...string) Counter {
+	return Counter{v.f.with(labelValues)}
+}
+
+// Counter is a value that only goes up
+type Counter struct {
+	s *series
+}
+
+// Inc adds one to the counter
+func (c Counter) Inc() {
+	c.s.value.Add(1)
+}
+
+// Add adds delta to the counter, negative deltas are ignored
+func (c Counter) Add(delta float64) {
+	if delta > 0 {
+		c.s.value.Add(delta)
+	}
+}
+
+// Value returns the current value of the counter
+func (c Counter) Value() float64 {
+	return c.s.value.Load()
+}
+
+// GaugeVec is a gauge family, partitioned by label values
+type GaugeVec struct {
+	f *family
+}
+
+// With returns the gauge of the label values, in the order of the label names
+func (v *GaugeVec) With(labelValues ...string) Gauge {
+	return Gauge{v.f.with(labelValues)}
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	s *series
+}
+
+// Set sets the gauge to value
+func (g Gauge) Set(value float64) {
+	g.s.value.Store(value)
+}
+
+// Inc adds one to the gauge
+func (g Gauge) Inc() {
+	g.s.value.Add(1)
+}
+
+// Dec subtracts one from the gauge
+func (g Gauge) Dec() {
+	g.s.value.Add(-1)
+}
+
+// Add adds delta to the gauge
+func (g Gauge) Add(delta float64) {
+	g.s.value.Add(delta)
+}
+
+// Value returns the current value of the gauge
+func (g Gauge) Value() float64 {
+	return g.s.value.Load()
+}
+
+// HistogramVec is a histogram family, partitioned by label values
+type HistogramVec struct {
+	f *family
+}
+
+// With returns the histogram of the label values, in the order of the label names
+func (v *HistogramVec) With(labelValues ...string) Histogram {
+	return Histogram{v.f.with(labelValues), v.f.buckets}
+}
+
+// Histogram counts observations in buckets, and keeps their count and sum
+type Histogram struct {
+	s       *series
+	buckets []float64
+}
+
+// Observe records a value, such as a duration in seconds
+func (h Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.buckets, value)
+	h.s.counts[i].Add(1)
+	h.s.value.Add(value)
+	h.s.count.Add(1)
+}
+
+// Count returns the number of observations
+func (h Histogram) Count() uint64 {
+	return h.s.count.Load()
+}
+
+// Sum returns the sum of the observations
+func (h Histogram) Sum() float64 {
+	return h.s.value.Load()
+}
+
+// Value returns the value of a counter or gauge series, or the number of observations of a
+// histogram series. It reports false when the family or series doesn't exist, and is meant for
+// tests asserting on metrics.
+func (r *Registry) Value(name string, labelValues ...string) (float64, bool) {
+	r.mu.RLock()
+	f, ok := r.families[name]
+	r.mu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+
+	for _, s := range f.snapshot() {
+		if slices.Equal(s.labelValues, labelValues) {
+			if f.kind == KindHistogram {
+				return float64(s.count.Load()), true
+			}
+			return s.value.Load(), true
+		}
+	}
+	return 0, false
+}
+
+// snapshot returns the series of the family sorted by label values, collecting them first for
+// collected families
+func (f *family) snapshot() []*series {
+	var all []*series
+	if f.collect != nil {
+		f.collect(func(value float64, labelValues ...string) {
+			if len(labelValues) != len(f.labelNames) {
+				return
+			}
+			s := &series{labelValues: labelValues}
+			s.value.Store(value)
+			all = append(all, s)
+		})
+	} else {
+		f.mu.RLock()
+		all = make([]*series, 0, len(f.series))
+		for _, s := range f.series {
+			all = append(all, s)
+		}
+		f.mu.RUnlock()
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return strings.Join(all[i].labelValues, labelSeparator) < strings.Join(all[j].labelValues, labelSeparator)
+	})
+	return all
+}
+
+// Write writes the families in the Prometheus text exposition format, sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.snapshot() {
+			if f.kind != KindHistogram {
+				writeSample(bw, f.name, f.labelNames, s.labelValues, "", s.value.Load())
+				continue
+			}
+
+			// Buckets are cumulative in the exposition format. The count is read before the
+			// buckets, so a concurrent observation can't make a bucket exceed it.
+			count := s.count.Load()
+			var cumulative uint64
+			for i, bound := range f.buckets {
+				cumulative += s.counts[i].Load()
+				writeSample(bw, f.name+"_bucket", f.labelNames, s.labelValues, formatFloat(bound), float64(min(cumulative, count)))
+			}
+			writeSample(bw, f.name+"_bucket", f.labelNames, s.labelValues, "+Inf", float64(count))
+			writeSample(bw, f.name+"_sum", f.labelNames, s.labelValues, "", s.value.Load())
+			writeSample(bw, f.name+"_count", f.labelNames, s.labelValues, "", float64(count))
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSample writes a sample line, with the le label of a histogram bucket when le isn't empty
+func writeSample(w *bufio.Writer, name string, labelNames, labelValues []string, le string, value float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || le != "" {
+		w.WriteByte('{')
+		for i, label := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escapeLabelValue(labelValues[i]))
+		}
+		if le != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `le="%s"`, le)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// validName reports whether s is a valid metric name, [a-zA-Z_:][a-zA-Z0-9_:]*
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		letter := c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Requests served.", "method", "path")
+	inFlight := r.Gauge("in_flight", "Requests being served.").With()
+	durations := r.Histogram("duration_seconds", "Request durations.", []float64{0.1, 1}, "method")
+	r.Collect("cache_hit_ratio", "Hit ratio\nof the caches.", KindGauge, []string{"cache"}, func(observe func(float64, ...string)) {
+		observe(0.75, "users")
+		observe(1, "invalid", "label count")
+	})
+
+	requests.With("GET", `/a"b\c`).Inc()
+	requests.With("GET", `/a"b\c`).Add(2)
+	requests.With("GET", `/a"b\c`).Add(-1)
+	requests.With("POST", "/a").Inc()
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+	for _, d := range []float64{0.05, 0.1, 0.5, 3} {
+		durations.With("GET").Observe(d)
+	}
+
+	var sb strings.Builder
+	require.NoError(t, r.Write(&sb))
+	assert.Equal(t, `# HELP cache_hit_ratio Hit ratio\nof the caches.
+# TYPE cache_hit_ratio gauge
+cache_hit_ratio{cache="users"} 0.75
+# HELP duration_seconds Request durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{method="GET",le="0.1"} 2
+duration_seconds_bucket{method="GET",le="1"} 3
+duration_seconds_bucket{method="GET",le="+Inf"} 4
+duration_seconds_sum{method="GET"} 3.65
+duration_seconds_count{method="GET"} 4
+# HELP in_flight Requests being served.
+# TYPE in_flight gauge
+in_flight 1
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{method="GET",path="/a\"b\\c"} 3
+requests_total{method="POST",path="/a"} 1
+`, sb.String())
+}
+
+func TestRegistryValue(t *testing.T) {
+	r := NewRegistry()
+	logins := r.Counter("logins_total", "Logins.", "outcome")
+	durations := r.Histogram("duration_seconds", "Durations.", nil)
+
+	logins.With("success").Inc()
+	durations.With().Observe(0.2)
+	durations.With().Observe(0.3)
+
+	value, ok := r.Value("logins_total", "success")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, value)
+	_, ok = r.Value("logins_total", "failure")
+	assert.False(t, ok, "series are created on first use")
+	_, ok = r.Value("unknown_total")
+	assert.False(t, ok)
+
+	value, ok = r.Value("duration_seconds")
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, value, "histograms report their number of observations")
+	assert.InDelta(t, 0.5, durations.With().Sum(), 1e-9)
+}
+
+func TestRegistryConcurrentUpdates(t *testing.T) {
+	r := NewRegistry()
+	counter := r.Counter("events_total", "Events.", "kind")
+	histogram := r.Histogram("latency_seconds", "Latencies.", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counter.With("a").Inc()
+				histogram.With().Observe(0.01)
+			}
+		}()
+		go func() {
+			var sb strings.Builder
+			_ = r.Write(&sb)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 8000.0, counter.With("a").Value())
+	assert.Equal(t, uint64(8000), histogram.With().Count())
+}
+
+func TestRegistryInvalidRegistrations(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("requests_total", "Requests.")
+
+	assert.Panics(t, func() { r.Counter("requests_total", "Again.") })
+	assert.Panics(t, func() { r.Gauge("1st", "Invalid name.") })
+	assert.Panics(t, func() { r.Gauge("temperature", "Invalid label.", "room-name") })
+	assert.Panics(t, func() { r.Histogram("size_bytes", "Reserved label.", nil, "le") })
+	assert.Panics(t, func() { r.Counter("events_total", "Events.", "kind").With() }, "label values must match the names")
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const memoryShards = 32
+
+// MemoryStore keeps buckets in memory, limits are per instance. Keys are spread over shards
+// so concurrent requests for different keys rarely wait on the same lock.
+type MemoryStore struct {
+	shards [memoryShards]memoryShard
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryShard struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+// NewMemoryStore creates a MemoryStore evicting idle keys every cleanupInterval.
+// A non-positive interval disables the cleanup goroutine.
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	for i := range s.shards {
+		s.shards[i].tats = make(map[string]time.Time)
+	}
+
+	if cleanupInterval > 0 {
+		go s.janitor(cleanupInterval)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &s.shards[h.Sum32()%memoryShards]
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, tier Tier, now time.Time) (Result, error) {
+	shard := s.shard(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	tat, result := take(shard.tats[key], now, tier)
+	shard.tats[key] = tat
+	return result, nil
+}
+
+// Len returns the number of keys held
+func (s *MemoryStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		n += len(shard.tats)
+		shard.mu.Unlock()
+	}
+	return n
+}
+
+// evictIdle removes the keys whose bucket is full again, they behave like unknown keys
+func (s *MemoryStore) evictIdle(now time.Time) {
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for key, tat := range shard.tats {
+			if !tat.After(now) {
+				delete(shard.tats, key)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+func (s *MemoryStore) janitor(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.evictIdle(now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the cleanup goroutine, it is safe to call more than once
+func (s *MemoryStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+	})
+	<-s.done
+	return nil
+}
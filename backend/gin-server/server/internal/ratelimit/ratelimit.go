@@ -0,0 +1,179 @@
+// Package ratelimit limits how often clients may call the API. Limits of a single instance are
+// enforced with the generic cell rate algorithm (GCRA), a token bucket that only stores one
+// timestamp per key: the theoretical arrival time (TAT) at which the bucket is full again.
+// Limits shared between instances count requests in fixed windows in the database or cache.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+)
+
+// Tier names, route groups are limited by one of these
+const (
+	TierDefault     = "default"
+	TierAuth        = "auth"
+	TierLowPriority = "low_priority"
+)
+
+var ErrUnknownTier = errors.New("unknown rate limit tier")
+
+// Tier allows Limit requests per Period to every key
+type Tier struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+	Burst  int // Requests allowed at once, defaults to Limit
+}
+
+// DefaultTiers returns the tiers with the limits from the constants
+func DefaultTiers() []Tier {
+	return []Tier{
+		{Name: TierDefault, Limit: constants.DefaultRateLimit, Period: time.Minute},
+		{Name: TierAuth, Limit: constants.AuthRateLimit, Period: time.Minute},
+		{Name: TierLowPriority, Limit: constants.LowPriorityRateLimit, Period: time.Minute},
+	}
+}
+
+// interval is the time it takes to regain one request
+func (t Tier) interval() time.Duration {
+	return t.Period / time.Duration(t.Limit)
+}
+
+// capacity is the time it takes to refill an empty bucket
+func (t Tier) capacity() time.Duration {
+	burst := t.Burst
+	if burst <= 0 {
+		burst = t.Limit
+	}
+	return t.interval() * time.Duration(burst)
+}
+
+// Result is the outcome of a request against a tier
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // Until the next request is allowed, only set when denied
+	ResetAfter time.Duration // Until the bucket is full again
+}
+
+// Store keeps the state of every key and takes requests from it atomically. MemoryStore keeps
+// token buckets per instance, NewCacheStore and NewDatabaseStore count requests in fixed windows
+// shared between instances.
+type Store interface {
+	Take(ctx context.Context, key string, tier Tier, now time.Time) (Result, error)
+}
+
+// take applies a request arriving at now to a bucket full again at tat. It returns the new
+// arrival time, which is tat unchanged when the request is denied.
+func take(tat, now time.Time, tier Tier) (time.Time, Result) {
+	interval, capacity := tier.interval(), tier.capacity()
+
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(interval)
+
+	result := Result{Limit: tier.Limit}
+	if wait := next.Sub(now); wait > capacity {
+		result.RetryAfter = wait - capacity
+		result.ResetAfter = tat.Sub(now)
+		return tat, result
+	}
+
+	result.Allowed = true
+	result.Remaining = int((capacity - next.Sub(now)) / interval)
+	result.ResetAfter = next.Sub(now)
+	return next, result
+}
+
+// Option configures a Limiter
+type Option func(*Limiter)
+
+// WithFailOpen sets whether requests are allowed when the store fails, they are by default
+func WithFailOpen(failOpen bool) Option {
+	return func(l *Limiter) {
+		l.failOpen = failOpen
+	}
+}
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// WithOnReject sets a function called with the tier of every request over its limit, e.g. to count them
+func WithOnReject(onReject func(tier string)) Option {
+	return func(l *Limiter) {
+		l.onReject = onReject
+	}
+}
+
+// Limiter applies named tiers to keys such as a user id or client IP
+type Limiter struct {
+	store    Store
+	tiers    map[string]Tier
+	failOpen bool
+	now      func() time.Time
+	onReject func(tier string)
+}
+
+// NewLimiter creates a Limiter keeping its state in store. Tiers without a positive limit
+// or period are left out, so requests against them are not limited.
+func NewLimiter(store Store, tiers []Tier, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:    store,
+		tiers:    make(map[string]Tier, len(tiers)),
+		failOpen: true,
+		now:      time.Now,
+	}
+	for _, tier := range tiers {
+		if tier.Limit > 0 && tier.Period > 0 {
+			l.tiers[tier.Name] = tier
+		}
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Tier returns the tier with the given name
+func (l *Limiter) Tier(name string) (Tier, bool) {
+	tier, ok := l.tiers[name]
+	return tier, ok
+}
+
+// Allow takes one request from the bucket of key in the named tier. When the store fails the
+// error is returned with a result allowing the request or not, depending on the fail policy.
+func (l *Limiter) Allow(ctx context.Context, tierName, key string) (Result, error) {
+	tier, ok := l.tiers[tierName]
+	if !ok {
+		return Result{}, ErrUnknownTier
+	}
+
+	// Every tier has its own buckets
+	result, err := l.store.Take(ctx, tier.Name+":"+key, tier, l.now())
+	if err != nil {
+		return Result{Allowed: l.failOpen, Limit: tier.Limit}, err
+	}
+	if !result.Allowed && l.onReject != nil {
+		l.onReject(tier.Name)
+	}
+	return result, nil
+}
+
+// Close releases the store
+func (l *Limiter) Close() error {
+	if closer, ok := l.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStart = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTake(t *testing.T) {
+	tier := Tier{Name: TierDefault, Limit: 3, Period: 3 * time.Second}
+
+	var tat time.Time
+	var result Result
+	for i := 0; i < 3; i++ {
+		tat, result = take(tat, testStart, tier)
+		require.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+		assert.Equal(t, time.Duration(i+1)*time.Second, result.ResetAfter)
+	}
+
+	denied, result := take(tat, testStart, tier)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, tat, denied, "denied requests don't use the bucket")
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+	// A token is regained every second
+	_, result = take(tat, testStart.Add(time.Second), tier)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestTakeBurst(t *testing.T) {
+	// One request per second on average, never more than two at once
+	tier := Tier{Name: TierDefault, Limit: 60, Period: time.Minute, Burst: 2}
+
+	var tat time.Time
+	var result Result
+	for i := 0; i < 2; i++ {
+		tat, result = take(tat, testStart, tier)
+		require.True(t, result.Allowed)
+	}
+	_, result = take(tat, testStart, tier)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 60, result.Limit)
+
+	// An idle key never accumulates more than the burst
+	tat, _ = take(tat, testStart.Add(time.Hour), tier)
+	tat, _ = take(tat, testStart.Add(time.Hour), tier)
+	_, result = take(tat, testStart.Add(time.Hour), tier)
+	assert.False(t, result.Allowed)
+}
+
+func TestLimiter(t *testing.T) {
+	now := testStart
+	var rejected []string
+	store := NewMemoryStore(0)
+	limiter := NewLimiter(store, []Tier{
+		{Name: TierDefault, Limit: 2, Period: time.Minute},
+		{Name: TierAuth, Limit: 1, Period: time.Minute},
+		{Name: TierLowPriority, Limit: 0, Period: time.Minute},
+	}, WithClock(func() time.Time { return now }), WithOnReject(func(tier string) { rejected = append(rejected, tier) }))
+	defer limiter.Close()
+	ctx := context.Background()
+
+	result, err := limiter.Allow(ctx, TierAuth, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.Allow(ctx, TierAuth, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.RetryAfter)
+	assert.Equal(t, []string{TierAuth}, rejected)
+
+	// Tiers have separate buckets for the same key
+	result, err = limiter.Allow(ctx, TierDefault, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(time.Minute)
+	result, err = limiter.Allow(ctx, TierAuth, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// Tiers without a limit aren't configured
+	_, ok := limiter.Tier(TierLowPriority)
+	assert.False(t, ok)
+	_, err = limiter.Allow(ctx, TierLowPriority, "ip:127.0.0.1")
+	assert.ErrorIs(t, err, ErrUnknownTier)
+}
+
+func TestMemoryStoreEvictsIdleKeys(t *testing.T) {
+	store := NewMemoryStore(0)
+	defer store.Close()
+	ctx := context.Background()
+	tier := Tier{Name: TierDefault, Limit: 10, Period: time.Minute}
+
+	_, err := store.Take(ctx, "idle", tier, testStart)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = store.Take(ctx, "busy", tier, testStart.Add(50*time.Second))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, store.Len())
+
+	// The idle key's bucket is full again, the busy key is still limited
+	store.evictIdle(testStart.Add(time.Minute))
+	assert.Equal(t, 1, store.Len())
+
+	result, err := store.Take(ctx, "busy", tier, testStart.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Remaining, "eviction keeps the state of busy keys")
+}
+
+func TestMemoryStoreCloseStopsJanitor(t *testing.T) {
+	store := NewMemoryStore(time.Millisecond)
+
+	_, err := store.Take(context.Background(), "key", Tier{Name: TierDefault, Limit: 1, Period: time.Millisecond}, time.Now())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return store.Len() == 0 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, store.Close())
+	assert.NoError(t, store.Close())
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+)
+
+const (
+	// counterKeyPrefix namespaces the counters in a shared cache
+	counterKeyPrefix = "ratelimit:"
+	// maxBlockedKeys bounds the keys denied locally until their window ends
+	maxBlockedKeys = 100000
+)
+
+var ErrCountersUnsupported = errors.New("store can't increment counters")
+
+// incrementFunc adds one to the counter at key, expiring with the window, and returns the new count
+type incrementFunc func(ctx context.Context, key string, expiresAt time.Time, ttl time.Duration) (int64, error)
+
+// windowStore counts requests in fixed windows of the tier period, in a store shared between
+// instances. It only needs one atomic increment per request, at the price of letting a client
+// through up to twice the limit across a window boundary.
+type windowStore struct {
+	increment incrementFunc
+	cleanup   func(ctx context.Context, now time.Time) error
+
+	// blocked holds the windows known to be over their limit. Further requests in them are
+	// denied without a round-trip to the store, which spares it most of the load of abusive clients.
+	blocked *memcache.InMemoryCache
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCacheStore creates a Store counting requests in cache, which must implement memcache.Counter.
+// With a Redis cache the limits are shared by every instance.
+func NewCacheStore(cache memcache.Cache) (Store, error) {
+	counter, ok := cache.(memcache.Counter)
+	if !ok {
+		return nil, ErrCountersUnsupported
+	}
+
+	return newWindowStore(func(ctx context.Context, key string, expiresAt time.Time, ttl time.Duration) (int64, error) {
+		return counter.Increment(counterKeyPrefix+key, 1, ttl)
+	}, nil, 0), nil
+}
+
+// NewDatabaseStore creates a Store counting requests in database, which must implement db.Counters.
+// Expired counters are deleted every cleanupInterval.
+func NewDatabaseStore(database db.Database, cleanupInterval time.Duration) (Store, error) {
+	counters, ok := database.(db.Counters)
+	if !ok {
+		return nil, ErrCountersUnsupported
+	}
+
+	return newWindowStore(func(ctx context.Context, key string, expiresAt time.Time, ttl time.Duration) (int64, error) {
+		return counters.IncrementCounter(ctx, key, 1, expiresAt)
+	}, func(ctx context.Context, now time.Time) error {
+		_, err := counters.DeleteExpiredCounters(ctx, now)
+		return err
+	}, cleanupInterval), nil
+}
+
+func newWindowStore(increment incrementFunc, cleanup func(ctx context.Context, now time.Time) error, cleanupInterval time.Duration) *windowStore {
+	s := &windowStore{
+		increment: increment,
+		cleanup:   cleanup,
+		blocked:   memcache.New(memcache.Config{MaxEntries: maxBlockedKeys, CleanupInterval: time.Minute}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if cleanup != nil && cleanupInterval > 0 {
+		go s.janitor(cleanupInterval)
+	} else {
+		close(s.done)
+	}
+	return s
+}
+
+func (s *windowStore) Take(ctx context.Context, key string, tier Tier, now time.Time) (Result, error) {
+	start := now.Truncate(tier.Period)
+	end := start.Add(tier.Period)
+	windowKey := key + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+
+	result := Result{Limit: tier.Limit, ResetAfter: end.Sub(now)}
+
+	if _, blocked := s.blocked.Get(windowKey); blocked {
+		result.RetryAfter = result.ResetAfter
+		return result, nil
+	}
+
+	count, err := s.increment(ctx, windowKey, end, end.Sub(now))
+	if err != nil {
+		return Result{}, err
+	}
+
+	if count > int64(tier.Limit) {
+		_ = s.blocked.Set(windowKey, true, end.Sub(now))
+		result.RetryAfter = result.ResetAfter
+		return result, nil
+	}
+
+	result.Allowed = true
+	result.Remaining = tier.Limit - int(count)
+	return result, nil
+}
+
+func (s *windowStore) janitor(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			// Failures are retried on the next tick, expired counters are only taking space
+			_ = s.cleanup(context.Background(), now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the cleanup goroutines, it is safe to call more than once
+func (s *windowStore) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		s.blocked.Close()
+	})
+	<-s.done
+	return nil
+}
@@ -0,0 +1,136 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+)
+
+// countingCache counts the increments reaching the cache
+type countingCache struct {
+	*memcache.InMemoryCache
+	increments atomic.Int64
+}
+
+func (c *countingCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	c.increments.Add(1)
+	return c.InMemoryCache.Increment(key, delta, expiration)
+}
+
+// plainCache can't increment counters
+type plainCache struct {
+	memcache.Cache
+}
+
+// failingStore is an unavailable store
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, tier Tier, now time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestCacheStore(t *testing.T) {
+	cache := &countingCache{InMemoryCache: memcache.New(memcache.Config{CleanupInterval: -1})}
+	defer cache.Close()
+
+	store, err := NewCacheStore(cache)
+	require.NoError(t, err)
+	defer store.(*windowStore).Close()
+
+	ctx := context.Background()
+	tier := Tier{Name: TierDefault, Limit: 2, Period: time.Minute}
+	now := testStart.Add(15 * time.Second)
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(ctx, "ip:127.0.0.1", tier, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+		assert.Equal(t, 45*time.Second, result.ResetAfter)
+	}
+
+	result, err := store.Take(ctx, "ip:127.0.0.1", tier, now)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 45*time.Second, result.RetryAfter)
+
+	// Once over the limit, the window is denied without reaching the cache
+	for i := 0; i < 5; i++ {
+		result, err = store.Take(ctx, "ip:127.0.0.1", tier, now)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	}
+	assert.Equal(t, int64(3), cache.increments.Load())
+
+	// The next window starts over
+	result, err = store.Take(ctx, "ip:127.0.0.1", tier, testStart.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestCacheStoreRequiresCounter(t *testing.T) {
+	_, err := NewCacheStore(plainCache{})
+	assert.ErrorIs(t, err, ErrCountersUnsupported)
+}
+
+func TestDatabaseStoreIsShared(t *testing.T) {
+	ctx := context.Background()
+	database, err := db.NewSQLiteDatabase(&config.Config{
+		DBType:     constants.SQLite,
+		SQLiteFile: filepath.Join(t.TempDir(), "test.db"),
+	}, logger.New())
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx))
+	require.NoError(t, database.Migrate(ctx))
+	t.Cleanup(func() { database.Close(ctx) })
+
+	// Two instances sharing the database
+	first, err := NewDatabaseStore(database, time.Minute)
+	require.NoError(t, err)
+	defer first.(*windowStore).Close()
+	second, err := NewDatabaseStore(database, time.Minute)
+	require.NoError(t, err)
+	defer second.(*windowStore).Close()
+
+	tier := Tier{Name: TierDefault, Limit: 3, Period: time.Hour}
+	now := time.Now()
+
+	allowed := 0
+	for i := 0; i < 3; i++ {
+		for _, store := range []Store{first, second} {
+			result, err := store.Take(ctx, "user:alice", tier, now)
+			require.NoError(t, err)
+			if result.Allowed {
+				allowed++
+			}
+		}
+	}
+	assert.Equal(t, 3, allowed, "the limit is shared by both instances")
+}
+
+func TestLimiterFailPolicy(t *testing.T) {
+	tiers := []Tier{{Name: TierDefault, Limit: 1, Period: time.Minute}}
+	ctx := context.Background()
+
+	result, err := NewLimiter(failingStore{}, tiers).Allow(ctx, TierDefault, "ip:127.0.0.1")
+	assert.Error(t, err)
+	assert.True(t, result.Allowed, "requests are allowed by default")
+
+	result, err = NewLimiter(failingStore{}, tiers, WithFailOpen(false)).Allow(ctx, TierDefault, "ip:127.0.0.1")
+	assert.Error(t, err)
+	assert.False(t, result.Allowed)
+}
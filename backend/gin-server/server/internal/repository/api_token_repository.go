@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *domain.APIToken) error
+	GetById(ctx context.Context, id string) (*domain.APIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.APIToken, error)
+	Update(ctx context.Context, token *domain.APIToken) error
+	Delete(ctx context.Context, id string) error
+}
+
+type apiTokenRepository struct {
+	collection db.Collection
+}
+
+// NewAPITokenRepository creates a new instance of APITokenRepository
+func NewAPITokenRepository(db *db.DBManager) APITokenRepository {
+	return &apiTokenRepository{
+		collection: db.DB.Collection(constants.APITokensCollection),
+	}
+}
+
+// Implementation of APITokenRepository interface
+func (r *apiTokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	_, err := r.collection.Create(ctx, token)
+	return err
+}
+
+func (r *apiTokenRepository) GetById(ctx context.Context, id string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	err := r.collection.GetById(ctx, id, &token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	var token domain.APIToken
+	err := r.collection.GetOne(ctx, map[string]interface{}{"token_hash": tokenHash}, &token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *apiTokenRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.APIToken, error) {
+	var tokens []domain.APIToken
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *apiTokenRepository) Update(ctx context.Context, token *domain.APIToken) error {
+	return r.collection.UpdateById(ctx, token.ID, token)
+}
+
+func (r *apiTokenRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+)
+
+var (
+	// shortCacheTTL is used for records checked on every request, so changes such as disabling
+	// a user apply quickly on instances that didn't make the change
+	shortCacheTTL = time.Duration(constants.ShortCacheDuration) * time.Second
+	// mediumCacheTTL is used for records only their owner changes
+	mediumCacheTTL = time.Duration(constants.MediumCacheDuration) * time.Second
+)
+
+// CacheStats holds the counters of a cached repository
+type CacheStats struct {
+	Hits          uint64  `json:"hits"`
+	NegativeHits  uint64  `json:"negativeHits"` // Hits on a cached not found result
+	Misses        uint64  `json:"misses"`
+	Loads         uint64  `json:"loads"` // Misses that reached the database, concurrent misses share a load
+	Invalidations uint64  `json:"invalidations"`
+	HitRatio      float64 `json:"hitRatio"`
+}
+
+// CachedRepository is implemented by repositories reading through a cache
+type CachedRepository interface {
+	CacheName() string
+	CacheStats() CacheStats
+}
+
+// cachedValue is what the cache holds, a not found result is cached as well so lookups of
+// missing records don't all reach the database
+type cachedValue[T any] struct {
+	Value    T
+	NotFound bool
+}
+
+// readThrough loads values through a cache. Values are copied in and out, so callers can't
+// change what other callers read. Cached values are encoded with gob by shared caches, which
+// keeps fields hidden from JSON such as password hashes.
+type readThrough[T any] struct {
+	cache       memcache.Cache
+	prefix      string
+	ttl         time.Duration
+	negativeTTL time.Duration
+	clone       func(T) T // Deep copies values holding slices or pointers, nil for plain structs
+	group       singleflight.Group
+
+	// loading tracks the keys with a load in flight. Invalidating a key bumps its generation,
+	// so a load that read the record before the write doesn't cache it afterwards.
+	mu      sync.Mutex
+	loading map[string]*loadState
+
+	hits          atomic.Uint64
+	negativeHits  atomic.Uint64
+	misses        atomic.Uint64
+	loads         atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// loadState is the generation of a key and the number of loads of it in flight
+type loadState struct {
+	generation uint64
+	loads      int
+}
+
+func newReadThrough[T any](cache memcache.Cache, prefix string, ttl time.Duration, clone func(T) T) *readThrough[T] {
+	gob.Register(cachedValue[T]{})
+
+	return &readThrough[T]{
+		cache:       cache,
+		prefix:      prefix,
+		ttl:         ttl,
+		negativeTTL: shortCacheTTL,
+		clone:       clone,
+		loading:     make(map[string]*loadState),
+	}
+}
+
+// get returns the cached value of the key, or loads and caches it. Concurrent misses of the
+// same key share one load, which runs without the caller's cancellation so one caller
+// giving up doesn't fail the others. A load raced by an invalidation isn't cached.
+func (r *readThrough[T]) get(ctx context.Context, key string, load func(ctx context.Context) (T, error)) (T, error) {
+	key = r.prefix + key
+
+	if cached, ok := r.cache.Get(key); ok {
+		if value, ok := cached.(cachedValue[T]); ok {
+			if value.NotFound {
+				r.negativeHits.Add(1)
+				var zero T
+				return zero, db.ErrNotFound
+			}
+			r.hits.Add(1)
+			return r.copy(value.Value), nil
+		}
+	}
+	r.misses.Add(1)
+
+	result, err, _ := r.group.Do(key, func() (interface{}, error) {
+		r.loads.Add(1)
+		generation := r.startLoad(key)
+
+		value, err := load(context.WithoutCancel(ctx))
+		if errors.Is(err, db.ErrNotFound) {
+			r.finishLoad(key, generation, cachedValue[T]{NotFound: true}, r.negativeTTL)
+			return nil, err
+		}
+		if err != nil {
+			r.finishLoad(key, generation, nil, 0)
+			return nil, err
+		}
+
+		r.finishLoad(key, generation, cachedValue[T]{Value: r.copy(value)}, r.ttl)
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return r.copy(result.(T)), nil
+}
+
+// invalidate removes keys after a write, later reads load the new state. Loads in flight are
+// marked stale before the entry is deleted, so they either skip caching or get deleted.
+func (r *readThrough[T]) invalidate(keys ...string) {
+	for _, key := range keys {
+		key = r.prefix + key
+
+		r.mu.Lock()
+		if state, ok := r.loading[key]; ok {
+			state.generation++
+		}
+		r.mu.Unlock()
+		// Later reads start a new load instead of waiting for the stale one
+		r.group.Forget(key)
+
+		_ = r.cache.Delete(key)
+		r.invalidations.Add(1)
+	}
+}
+
+// startLoad registers a load of the key and returns the generation it reads
+func (r *readThrough[T]) startLoad(key string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.loading[key]
+	if !ok {
+		state = &loadState{}
+		r.loading[key] = state
+	}
+	state.loads++
+	return state.generation
+}
+
+// finishLoad caches the loaded value unless the key was invalidated during the load.
+// A nil value caches nothing.
+func (r *readThrough[T]) finishLoad(key string, generation uint64, value interface{}, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.loading[key]
+	if value != nil && state.generation == generation {
+		_ = r.cache.Set(key, value, ttl)
+	}
+
+	state.loads--
+	if state.loads == 0 {
+		delete(r.loading, key)
+	}
+}
+
+func (r *readThrough[T]) copy(value T) T {
+	if r.clone == nil {
+		return value
+	}
+	return r.clone(value)
+}
+
+func (r *readThrough[T]) stats() CacheStats {
+	stats := CacheStats{
+		Hits:          r.hits.Load(),
+		NegativeHits:  r.negativeHits.Load(),
+		Misses:        r.misses.Load(),
+		Loads:         r.loads.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	return stats
+}
+
+// mergeStats adds up the counters of several caches of one repository
+func mergeStats(all ...CacheStats) CacheStats {
+	var merged CacheStats
+	for _, stats := range all {
+		merged.Hits += stats.Hits
+		merged.NegativeHits += stats.NegativeHits
+		merged.Misses += stats.Misses
+		merged.Loads += stats.Loads
+		merged.Invalidations += stats.Invalidations
+	}
+	if total := merged.Hits + merged.NegativeHits + merged.Misses; total > 0 {
+		merged.HitRatio = float64(merged.Hits+merged.NegativeHits) / float64(total)
+	}
+	return merged
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache/redistest"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+func setupTestDB(t *testing.T) *db.DBManager {
+	ctx := context.Background()
+
+	database, err := db.NewSQLiteDatabase(&config.Config{
+		DBType:     constants.SQLite,
+		SQLiteFile: filepath.Join(t.TempDir(), "test.db"),
+	}, logger.New())
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx))
+	require.NoError(t, database.Migrate(ctx))
+	t.Cleanup(func() { database.Close(ctx) })
+
+	return &db.DBManager{DB: database}
+}
+
+func newTestCache(t *testing.T) *memcache.InMemoryCache {
+	cache := memcache.New(memcache.Config{})
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+// countingUserRepository counts GetById calls, which wait for release when it is set
+type countingUserRepository struct {
+	UserRepository
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingUserRepository) GetById(ctx context.Context, id string) (*domain.User, error) {
+	r.calls.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.UserRepository.GetById(ctx, id)
+}
+
+// stalledUserRepository holds the first GetByEmail after it read the user, until release is closed
+type stalledUserRepository struct {
+	UserRepository
+	loaded  chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (r *stalledUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	user, err := r.UserRepository.GetByEmail(ctx, email)
+	r.once.Do(func() {
+		close(r.loaded)
+		<-r.release
+	})
+	return user, err
+}
+
+func TestCachedUserRepository(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingUserRepository{UserRepository: NewUserRepository(setupTestDB(t))}
+	repo := NewCachedUserRepository(inner, newTestCache(t))
+
+	// Not found results are cached until the user is created
+	_, err := repo.GetByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+
+	alice := &domain.User{ID: "alice", Email: "alice@example.com", Username: "alice", Password: "hash", Role: domain.UserRoleUser}
+	require.NoError(t, repo.Create(ctx, alice))
+
+	found, err := repo.GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", found.ID)
+
+	// Reads are served from the cache, and changing a returned user doesn't change the cache
+	for i := 0; i < 3; i++ {
+		user, err := repo.GetById(ctx, "alice")
+		require.NoError(t, err)
+		user.Role = domain.UserRoleAdmin
+	}
+	assert.Equal(t, int32(1), inner.calls.Load())
+	user, err := repo.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserRoleUser, user.Role)
+
+	// Updating drops the keys of the previous email as well as the new one
+	user.Email = "alice@new.example.com"
+	require.NoError(t, repo.Update(ctx, user))
+
+	_, err = repo.GetByEmail(ctx, "alice@example.com")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+	found, err = repo.GetByEmail(ctx, "alice@new.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", found.ID)
+	found, err = repo.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@new.example.com", found.Email)
+
+	require.NoError(t, repo.Delete(ctx, "alice"))
+	_, err = repo.GetById(ctx, "alice")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+	_, err = repo.GetByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+
+	stats := repo.(CachedRepository).CacheStats()
+	assert.Equal(t, uint64(3), stats.Hits)
+	assert.Greater(t, stats.HitRatio, 0.0)
+	assert.Less(t, stats.HitRatio, 1.0)
+}
+
+func TestCachedUserRepositoryCollapsesConcurrentMisses(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingUserRepository{UserRepository: NewUserRepository(setupTestDB(t)), release: make(chan struct{})}
+	repo := NewCachedUserRepository(inner, newTestCache(t))
+
+	require.NoError(t, inner.UserRepository.Create(ctx, &domain.User{ID: "alice", Email: "alice@example.com", Username: "alice", Password: "hash", Role: domain.UserRoleUser}))
+
+	const readers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := repo.GetById(ctx, "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, "alice", user.ID)
+		}()
+	}
+
+	// Let the readers pile up behind the first load
+	require.Eventually(t, func() bool {
+		return repo.(CachedRepository).CacheStats().Misses == readers
+	}, time.Second, time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), inner.calls.Load())
+	assert.Equal(t, uint64(1), repo.(CachedRepository).CacheStats().Loads)
+}
+
+func TestCachedUserRepositoryDropsLoadRacingAnInvalidation(t *testing.T) {
+	ctx := context.Background()
+	inner := &stalledUserRepository{UserRepository: NewUserRepository(setupTestDB(t)), loaded: make(chan struct{}), release: make(chan struct{})}
+	repo := NewCachedUserRepository(inner, newTestCache(t))
+
+	user := &domain.User{ID: "alice", Email: "alice@example.com", Username: "alice", Password: "hash", Role: domain.UserRoleUser}
+	require.NoError(t, inner.UserRepository.Create(ctx, user))
+
+	// A load reads the active user, then the user is disabled before the load caches it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := repo.GetByEmail(ctx, "alice@example.com")
+		assert.NoError(t, err)
+	}()
+	<-inner.loaded
+
+	user.Status = domain.UserStatusDisabled
+	require.NoError(t, repo.Update(ctx, user))
+	close(inner.release)
+	<-done
+
+	found, err := repo.GetByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, found.IsDisabled(), "stale user cached after the invalidation")
+}
+
+func TestCachedUserRepositoryWithSharedCache(t *testing.T) {
+	server, err := redistest.NewServer("")
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	dbManager := setupTestDB(t)
+
+	// Two instances sharing a cache, a write on one is seen by the other
+	first := NewCachedUserRepository(NewUserRepository(dbManager), memcache.NewRedisCache(memcache.RedisConfig{Addr: server.Addr()}))
+	second := NewCachedUserRepository(NewUserRepository(dbManager), memcache.NewRedisCache(memcache.RedisConfig{Addr: server.Addr()}))
+
+	user := &domain.User{ID: "alice", Email: "alice@example.com", Username: "alice", Password: "hash", Role: domain.UserRoleUser, TokenVersion: 3}
+	require.NoError(t, first.Create(ctx, user))
+
+	cached, err := second.GetById(ctx, "alice")
+	require.NoError(t, err)
+	cached, err = second.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), second.(CachedRepository).CacheStats().Hits)
+
+	// Fields hidden from JSON survive the cache
+	assert.Equal(t, "hash", cached.Password)
+	assert.Equal(t, 3, cached.TokenVersion)
+
+	user.Status = domain.UserStatusDisabled
+	require.NoError(t, first.Update(ctx, user))
+	cached, err = second.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, cached.IsDisabled())
+}
+
+func TestCachedReminderRepository(t *testing.T) {
+	ctx := context.Background()
+	dbManager := setupTestDB(t)
+	repo := NewCachedReminderRepository(NewReminderRepository(dbManager), newTestCache(t))
+	require.NoError(t, NewUserRepository(dbManager).Create(ctx, &domain.User{ID: "alice", Email: "alice@example.com", Username: "alice", Password: "hash", Role: domain.UserRoleUser}))
+
+	reminders, err := repo.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+
+	require.NoError(t, repo.Create(ctx, &domain.Reminder{ID: "r1", Title: "First", UserID: "alice"}))
+	require.NoError(t, repo.Create(ctx, &domain.Reminder{ID: "r2", Title: "Second", UserID: "alice"}))
+
+	reminders, err = repo.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+
+	// Changing the returned list doesn't change the cache
+	reminders[0].Title = "Changed"
+	reminders, err = repo.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.NotEqual(t, "Changed", reminders[0].Title)
+
+	require.NoError(t, repo.Delete(ctx, "r1"))
+	reminders, err = repo.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, "r2", reminders[0].ID)
+
+	_, err = repo.GetById(ctx, "r1")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+}
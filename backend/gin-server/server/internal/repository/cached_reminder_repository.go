@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"slices"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+// cachedReminderRepository reads reminders through a cache, by id and by owner
+type cachedReminderRepository struct {
+	ReminderRepository
+	reminders *readThrough[domain.Reminder]
+	lists     *readThrough[[]domain.Reminder]
+}
+
+// NewCachedReminderRepository wraps a ReminderRepository with a read-through cache
+func NewCachedReminderRepository(repo ReminderRepository, cache memcache.Cache) ReminderRepository {
+	return &cachedReminderRepository{
+		ReminderRepository: repo,
+		reminders:          newReadThrough[domain.Reminder](cache, "reminders:id:", mediumCacheTTL, nil),
+		lists:              newReadThrough(cache, "reminders:user:", mediumCacheTTL, slices.Clone[[]domain.Reminder]),
+	}
+}
+
+func (r *cachedReminderRepository) CacheName() string {
+	return "reminders"
+}
+
+func (r *cachedReminderRepository) CacheStats() CacheStats {
+	return mergeStats(r.reminders.stats(), r.lists.stats())
+}
+
+func (r *cachedReminderRepository) Create(ctx context.Context, reminder *domain.Reminder) error {
+	if err := r.ReminderRepository.Create(ctx, reminder); err != nil {
+		return err
+	}
+
+	r.reminders.invalidate(reminder.ID)
+	r.lists.invalidate(reminder.UserID)
+	return nil
+}
+
+func (r *cachedReminderRepository) GetById(ctx context.Context, id string) (*domain.Reminder, error) {
+	reminder, err := r.reminders.get(ctx, id, func(ctx context.Context) (domain.Reminder, error) {
+		reminder, err := r.ReminderRepository.GetById(ctx, id)
+		if err != nil {
+			return domain.Reminder{}, err
+		}
+		return *reminder, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+func (r *cachedReminderRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.Reminder, error) {
+	return r.lists.get(ctx, userId, func(ctx context.Context) ([]domain.Reminder, error) {
+		return r.ReminderRepository.GetAllByUserId(ctx, userId)
+	})
+}
+
+func (r *cachedReminderRepository) Delete(ctx context.Context, id string) error {
+	// The owner's list holds the reminder too
+	previous, _ := r.ReminderRepository.GetById(ctx, id)
+
+	err := r.ReminderRepository.Delete(ctx, id)
+	r.reminders.invalidate(id)
+	if previous != nil {
+		r.lists.invalidate(previous.UserID)
+	}
+	return err
+}
+
+// cachedReminderGroupRepository reads reminder groups through a cache, by id and by owner
+type cachedReminderGroupRepository struct {
+	ReminderGroupRepository
+	groups *readThrough[domain.ReminderGroup]
+	lists  *readThrough[[]domain.ReminderGroup]
+}
+
+// NewCachedReminderGroupRepository wraps a ReminderGroupRepository with a read-through cache
+func NewCachedReminderGroupRepository(repo ReminderGroupRepository, cache memcache.Cache) ReminderGroupRepository {
+	return &cachedReminderGroupRepository{
+		ReminderGroupRepository: repo,
+		groups:                  newReadThrough[domain.ReminderGroup](cache, "reminder_groups:id:", mediumCacheTTL, nil),
+		lists:                   newReadThrough(cache, "reminder_groups:user:", mediumCacheTTL, slices.Clone[[]domain.ReminderGroup]),
+	}
+}
+
+func (r *cachedReminderGroupRepository) CacheName() string {
+	return "reminder_groups"
+}
+
+func (r *cachedReminderGroupRepository) CacheStats() CacheStats {
+	return mergeStats(r.groups.stats(), r.lists.stats())
+}
+
+func (r *cachedReminderGroupRepository) Create(ctx context.Context, group *domain.ReminderGroup) error {
+	if err := r.ReminderGroupRepository.Create(ctx, group); err != nil {
+		return err
+	}
+
+	r.groups.invalidate(group.ID)
+	r.lists.invalidate(group.UserID)
+	return nil
+}
+
+func (r *cachedReminderGroupRepository) GetById(ctx context.Context, id string) (*domain.ReminderGroup, error) {
+	group, err := r.groups.get(ctx, id, func(ctx context.Context) (domain.ReminderGroup, error) {
+		group, err := r.ReminderGroupRepository.GetById(ctx, id)
+		if err != nil {
+			return domain.ReminderGroup{}, err
+		}
+		return *group, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *cachedReminderGroupRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.ReminderGroup, error) {
+	return r.lists.get(ctx, userId, func(ctx context.Context) ([]domain.ReminderGroup, error) {
+		return r.ReminderGroupRepository.GetAllByUserId(ctx, userId)
+	})
+}
+
+func (r *cachedReminderGroupRepository) Delete(ctx context.Context, id string) error {
+	previous, _ := r.ReminderGroupRepository.GetById(ctx, id)
+
+	err := r.ReminderGroupRepository.Delete(ctx, id)
+	r.groups.invalidate(id)
+	if previous != nil {
+		r.lists.invalidate(previous.UserID)
+	}
+	return err
+}
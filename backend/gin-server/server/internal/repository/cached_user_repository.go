@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+// cachedUserRepository reads users through a cache by id, email and username. Writes go to the
+// repository first and then invalidate every key of the user, before and after the change.
+type cachedUserRepository struct {
+	UserRepository
+	users *readThrough[domain.User]
+}
+
+// NewCachedUserRepository wraps a UserRepository with a read-through cache. With a cache local
+// to the instance, other instances may read a changed user until its entry expires.
+func NewCachedUserRepository(repo UserRepository, cache memcache.Cache) UserRepository {
+	return &cachedUserRepository{
+		UserRepository: repo,
+		users:          newReadThrough[domain.User](cache, "users:", shortCacheTTL, nil),
+	}
+}
+
+func (r *cachedUserRepository) CacheName() string {
+	return "users"
+}
+
+func (r *cachedUserRepository) CacheStats() CacheStats {
+	return r.users.stats()
+}
+
+func (r *cachedUserRepository) Create(ctx context.Context, user *domain.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+
+	// Drops cached not found results
+	r.users.invalidate(userKeys(user)...)
+	return nil
+}
+
+func (r *cachedUserRepository) GetById(ctx context.Context, id string) (*domain.User, error) {
+	return r.get(ctx, "id:"+id, func(ctx context.Context) (*domain.User, error) {
+		return r.UserRepository.GetById(ctx, id)
+	})
+}
+
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.get(ctx, "email:"+email, func(ctx context.Context) (*domain.User, error) {
+		return r.UserRepository.GetByEmail(ctx, email)
+	})
+}
+
+func (r *cachedUserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.get(ctx, "username:"+username, func(ctx context.Context) (*domain.User, error) {
+		return r.UserRepository.GetByUsername(ctx, username)
+	})
+}
+
+func (r *cachedUserRepository) Update(ctx context.Context, user *domain.User) error {
+	// The stored user holds the email and username being replaced
+	previous, _ := r.UserRepository.GetById(ctx, user.ID)
+
+	err := r.UserRepository.Update(ctx, user)
+	r.invalidate(previous, user)
+	return err
+}
+
+func (r *cachedUserRepository) Delete(ctx context.Context, id string) error {
+	previous, _ := r.UserRepository.GetById(ctx, id)
+
+	err := r.UserRepository.Delete(ctx, id)
+	r.invalidate(previous, &domain.User{ID: id})
+	return err
+}
+
+func (r *cachedUserRepository) get(ctx context.Context, key string, load func(ctx context.Context) (*domain.User, error)) (*domain.User, error) {
+	user, err := r.users.get(ctx, key, func(ctx context.Context) (domain.User, error) {
+		user, err := load(ctx)
+		if err != nil {
+			return domain.User{}, err
+		}
+		return *user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// invalidate drops the keys of the users, even after a failed write, which may have been applied
+func (r *cachedUserRepository) invalidate(users ...*domain.User) {
+	var keys []string
+	for _, user := range users {
+		if user != nil {
+			keys = append(keys, userKeys(user)...)
+		}
+	}
+	r.users.invalidate(keys...)
+}
+
+func userKeys(user *domain.User) []string {
+	keys := []string{"id:" + user.ID}
+	if user.Email != "" {
+		keys = append(keys, "email:"+user.Email)
+	}
+	if user.Username != "" {
+		keys = append(keys, "username:"+user.Username)
+	}
+	return keys
+}
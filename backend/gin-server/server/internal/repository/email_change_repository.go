@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type EmailChangeRepository interface {
+	Create(ctx context.Context, change *domain.EmailChange) error
+	GetByHash(ctx context.Context, tokenHash string) (*domain.EmailChange, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.EmailChange, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type emailChangeRepository struct {
+	collection db.Collection
+}
+
+// NewEmailChangeRepository creates a new instance of EmailChangeRepository
+func NewEmailChangeRepository(db *db.DBManager) EmailChangeRepository {
+	return &emailChangeRepository{
+		collection: db.DB.Collection(constants.EmailChangesCollection),
+	}
+}
+
+// Implementation of EmailChangeRepository interface
+func (r *emailChangeRepository) Create(ctx context.Context, change *domain.EmailChange) error {
+	_, err := r.collection.Create(ctx, change)
+	return err
+}
+
+func (r *emailChangeRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.EmailChange, error) {
+	var change domain.EmailChange
+	err := r.collection.GetOne(ctx, map[string]interface{}{"token_hash": tokenHash}, &change)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *emailChangeRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.EmailChange, error) {
+	var changes []domain.EmailChange
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &changes)
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *emailChangeRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
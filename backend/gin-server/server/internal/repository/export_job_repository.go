@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *domain.ExportJob) error
+	GetById(ctx context.Context, id string) (*domain.ExportJob, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.ExportJob, error)
+	GetAllByStatus(ctx context.Context, status string) ([]domain.ExportJob, error)
+	Update(ctx context.Context, job *domain.ExportJob) error
+	Delete(ctx context.Context, id string) error
+	GetClaim(ctx context.Context, userId string) (*domain.ExportClaim, error)
+	// CreateClaim fails with db.ErrDuplicate when the user already holds a claim
+	CreateClaim(ctx context.Context, claim *domain.ExportClaim) error
+	// ReplaceClaim fails with db.ErrConflict when the user's claim no longer points at previousJobId
+	ReplaceClaim(ctx context.Context, claim *domain.ExportClaim, previousJobId string) error
+	DeleteClaim(ctx context.Context, userId string) error
+}
+
+type exportJobRepository struct {
+	collection db.Collection
+	claims     db.Collection
+}
+
+// NewExportJobRepository creates a new instance of ExportJobRepository
+func NewExportJobRepository(db *db.DBManager) ExportJobRepository {
+	return &exportJobRepository{
+		collection: db.DB.Collection(constants.ExportJobsCollection),
+		claims:     db.DB.Collection(constants.ExportClaimsCollection),
+	}
+}
+
+// Implementation of ExportJobRepository interface
+func (r *exportJobRepository) Create(ctx context.Context, job *domain.ExportJob) error {
+	_, err := r.collection.Create(ctx, job)
+	return err
+}
+
+func (r *exportJobRepository) GetById(ctx context.Context, id string) (*domain.ExportJob, error) {
+	var job domain.ExportJob
+	err := r.collection.GetById(ctx, id, &job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *exportJobRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.ExportJob, error) {
+	var jobs []domain.ExportJob
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *exportJobRepository) GetAllByStatus(ctx context.Context, status string) ([]domain.ExportJob, error) {
+	var jobs []domain.ExportJob
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"status": status}, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *exportJobRepository) Update(ctx context.Context, job *domain.ExportJob) error {
+	return r.collection.UpdateById(ctx, job.ID, job)
+}
+
+func (r *exportJobRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
+
+func (r *exportJobRepository) GetClaim(ctx context.Context, userId string) (*domain.ExportClaim, error) {
+	var claim domain.ExportClaim
+	err := r.claims.GetById(ctx, userId, &claim)
+	if err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (r *exportJobRepository) CreateClaim(ctx context.Context, claim *domain.ExportClaim) error {
+	_, err := r.claims.Create(ctx, claim)
+	return err
+}
+
+func (r *exportJobRepository) ReplaceClaim(ctx context.Context, claim *domain.ExportClaim, previousJobId string) error {
+	return r.claims.UpdateByIdIf(ctx, claim.ID, []db.Condition{{Field: "job_id", Operator: "==", Value: previousJobId}}, claim)
+}
+
+func (r *exportJobRepository) DeleteClaim(ctx context.Context, userId string) error {
+	return r.claims.DeleteById(ctx, userId)
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+const (
+	// loginCounterKeyPrefix namespaces the login attempt counters in the shared counters table
+	loginCounterKeyPrefix = "login:"
+	// maxInMemoryLoginAttempts bounds the lockouts and counters held by the in-memory repository
+	maxInMemoryLoginAttempts = 100000
+	// expiredLoginAttemptsBatch is the number of expired lockouts deleted per query
+	expiredLoginAttemptsBatch = 500
+)
+
+var (
+	ErrCountersUnsupported = errors.New("database can't increment counters")
+	// ErrLoginAttemptsFull is returned by the in-memory repository when it can't track another subject
+	ErrLoginAttemptsFull = errors.New("too many login attempts tracked")
+)
+
+// LoginAttemptRepository counts login attempts and stores lockouts. The database implementation
+// is shared by every server instance, the in-memory one is meant for single instance deployments
+// and tests.
+type LoginAttemptRepository interface {
+	// Increment atomically adds delta to the attempts counted at key and returns the new count. A
+	// missing count starts from zero and is deleted once expiresAt is past.
+	Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error)
+	GetById(ctx context.Context, id string) (*domain.LoginAttempt, error)
+	// Save creates the attempt or replaces the stored one
+	Save(ctx context.Context, attempt *domain.LoginAttempt) error
+	ListLocked(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error)
+	Delete(ctx context.Context, id string) error
+	// DeleteExpired removes the attempts and counts expired at now
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type loginAttemptRepository struct {
+	collection db.Collection
+	counters   db.Counters
+}
+
+// NewLoginAttemptRepository creates a database backed LoginAttemptRepository, the database must
+// implement db.Counters
+func NewLoginAttemptRepository(database *db.DBManager) (LoginAttemptRepository, error) {
+	counters, ok := database.DB.(db.Counters)
+	if !ok {
+		return nil, ErrCountersUnsupported
+	}
+
+	return &loginAttemptRepository{
+		collection: database.DB.Collection(constants.LoginAttemptsCollection),
+		counters:   counters,
+	}, nil
+}
+
+// Implementation of LoginAttemptRepository interface
+func (r *loginAttemptRepository) Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	return r.counters.IncrementCounter(ctx, loginCounterKeyPrefix+key, delta, expiresAt)
+}
+
+func (r *loginAttemptRepository) GetById(ctx context.Context, id string) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	err := r.collection.GetById(ctx, id, &attempt)
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) Save(ctx context.Context, attempt *domain.LoginAttempt) error {
+	err := r.collection.UpdateById(ctx, attempt.ID, attempt)
+	if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	_, err = r.collection.Create(ctx, attempt)
+	if errors.Is(err, db.ErrDuplicate) {
+		// Created concurrently, replace it like any other stored attempt
+		return r.collection.UpdateById(ctx, attempt.ID, attempt)
+	}
+	return err
+}
+
+func (r *loginAttemptRepository) ListLocked(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	var attempts []domain.LoginAttempt
+	err := r.collection.Find(ctx, db.Query{OrderBy: "locked_until"}.Where("locked_until", ">", now), &attempts)
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *loginAttemptRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
+
+func (r *loginAttemptRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := r.counters.DeleteExpiredCounters(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expiredQuery := db.Query{Limit: expiredLoginAttemptsBatch}.Where("expires_at", "<=", now)
+	for {
+		var attempts []domain.LoginAttempt
+		if err := r.collection.Find(ctx, expiredQuery, &attempts); err != nil {
+			return deleted, err
+		}
+
+		for _, attempt := range attempts {
+			if err := r.collection.DeleteById(ctx, attempt.ID); err != nil && !errors.Is(err, db.ErrNotFound) {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(attempts) < expiredLoginAttemptsBatch {
+			return deleted, nil
+		}
+	}
+}
+
+type inMemoryLoginCount struct {
+	value     int64
+	expiresAt time.Time
+}
+
+type inMemoryLoginAttemptRepository struct {
+	mu         sync.Mutex
+	attempts   map[string]domain.LoginAttempt
+	counts     map[string]inMemoryLoginCount
+	maxEntries int
+}
+
+// NewInMemoryLoginAttemptRepository creates a LoginAttemptRepository local to this process
+func NewInMemoryLoginAttemptRepository() LoginAttemptRepository {
+	return newInMemoryLoginAttemptRepository(maxInMemoryLoginAttempts)
+}
+
+func newInMemoryLoginAttemptRepository(maxEntries int) *inMemoryLoginAttemptRepository {
+	return &inMemoryLoginAttemptRepository{
+		attempts:   make(map[string]domain.LoginAttempt),
+		counts:     make(map[string]inMemoryLoginCount),
+		maxEntries: maxEntries,
+	}
+}
+
+// reserve makes room for one more entry, dropping the expired ones first. Live entries are never
+// evicted, otherwise flooding the repository would lift the lockouts it holds.
+func (r *inMemoryLoginAttemptRepository) reserve() error {
+	if len(r.attempts)+len(r.counts) < r.maxEntries {
+		return nil
+	}
+
+	r.deleteExpired(time.Now())
+	if len(r.attempts)+len(r.counts) < r.maxEntries {
+		return nil
+	}
+	return ErrLoginAttemptsFull
+}
+
+func (r *inMemoryLoginAttemptRepository) Increment(ctx context.Context, key string, delta int64, expiresAt time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count, exists := r.counts[key]
+	if !exists {
+		if err := r.reserve(); err != nil {
+			return 0, err
+		}
+		count.expiresAt = expiresAt
+	}
+
+	count.value += delta
+	r.counts[key] = count
+	return count.value, nil
+}
+
+func (r *inMemoryLoginAttemptRepository) GetById(ctx context.Context, id string) (*domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, exists := r.attempts[id]
+	if !exists {
+		return nil, db.ErrNotFound
+	}
+	return &attempt, nil
+}
+
+func (r *inMemoryLoginAttemptRepository) Save(ctx context.Context, attempt *domain.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.attempts[attempt.ID]; !exists {
+		if err := r.reserve(); err != nil {
+			return err
+		}
+	}
+	r.attempts[attempt.ID] = *attempt
+	return nil
+}
+
+func (r *inMemoryLoginAttemptRepository) ListLocked(ctx context.Context, now time.Time) ([]domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := make([]domain.LoginAttempt, 0)
+	for _, attempt := range r.attempts {
+		if attempt.IsLocked(now) {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (r *inMemoryLoginAttemptRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.attempts[id]; !exists {
+		return db.ErrNotFound
+	}
+	delete(r.attempts, id)
+	return nil
+}
+
+func (r *inMemoryLoginAttemptRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteExpired(now), nil
+}
+
+func (r *inMemoryLoginAttemptRepository) deleteExpired(now time.Time) int64 {
+	var deleted int64
+	for key, count := range r.counts {
+		if !now.Before(count.expiresAt) {
+			delete(r.counts, key)
+			deleted++
+		}
+	}
+	for id, attempt := range r.attempts {
+		if attempt.IsExpired(now) {
+			delete(r.attempts, id)
+			deleted++
+		}
+	}
+	return deleted
+}
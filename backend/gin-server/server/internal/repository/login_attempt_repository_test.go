@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+func TestInMemoryLoginAttemptRepositoryIsBounded(t *testing.T) {
+	repo := newInMemoryLoginAttemptRepository(2)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := repo.Increment(ctx, "expired", 1, now.Add(-time.Second))
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, &domain.LoginAttempt{ID: "locked", LockedUntil: now.Add(time.Hour), ExpiresAt: now.Add(time.Hour)}))
+
+	// Expired entries make room, live ones are never evicted
+	_, err = repo.Increment(ctx, "live", 1, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = repo.Increment(ctx, "another", 1, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrLoginAttemptsFull)
+	assert.ErrorIs(t, repo.Save(ctx, &domain.LoginAttempt{ID: "another"}), ErrLoginAttemptsFull)
+
+	count, err := repo.Increment(ctx, "live", 1, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	locked, err := repo.ListLocked(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+	assert.Equal(t, "locked", locked[0].ID)
+}
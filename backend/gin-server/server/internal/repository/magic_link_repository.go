@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type MagicLinkRepository interface {
+	Create(ctx context.Context, link *domain.MagicLink) error
+	GetByHash(ctx context.Context, tokenHash string) (*domain.MagicLink, error)
+	GetAllByEmail(ctx context.Context, email string) ([]domain.MagicLink, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.MagicLink, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type magicLinkRepository struct {
+	collection db.Collection
+}
+
+// NewMagicLinkRepository creates a new instance of MagicLinkRepository
+func NewMagicLinkRepository(db *db.DBManager) MagicLinkRepository {
+	return &magicLinkRepository{
+		collection: db.DB.Collection(constants.MagicLinksCollection),
+	}
+}
+
+// Implementation of MagicLinkRepository interface
+func (r *magicLinkRepository) Create(ctx context.Context, link *domain.MagicLink) error {
+	_, err := r.collection.Create(ctx, link)
+	return err
+}
+
+func (r *magicLinkRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.MagicLink, error) {
+	var link domain.MagicLink
+	err := r.collection.GetOne(ctx, map[string]interface{}{"token_hash": tokenHash}, &link)
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *magicLinkRepository) GetAllByEmail(ctx context.Context, email string) ([]domain.MagicLink, error) {
+	var links []domain.MagicLink
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"email": email}, &links)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *magicLinkRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.MagicLink, error) {
+	var links []domain.MagicLink
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &links)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (r *magicLinkRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
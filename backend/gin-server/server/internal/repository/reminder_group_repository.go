@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type ReminderGroupRepository interface {
+	Create(ctx context.Context, group *domain.ReminderGroup) error
+	GetById(ctx context.Context, id string) (*domain.ReminderGroup, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.ReminderGroup, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type reminderGroupRepository struct {
+	collection db.Collection
+}
+
+// NewReminderGroupRepository creates a new instance of ReminderGroupRepository
+func NewReminderGroupRepository(db *db.DBManager) ReminderGroupRepository {
+	return &reminderGroupRepository{
+		collection: db.DB.Collection(constants.ReminderGroupsCollection),
+	}
+}
+
+// Implementation of ReminderGroupRepository interface
+func (r *reminderGroupRepository) Create(ctx context.Context, group *domain.ReminderGroup) error {
+	_, err := r.collection.Create(ctx, group)
+	return err
+}
+
+func (r *reminderGroupRepository) GetById(ctx context.Context, id string) (*domain.ReminderGroup, error) {
+	var group domain.ReminderGroup
+	err := r.collection.GetById(ctx, id, &group)
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *reminderGroupRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.ReminderGroup, error) {
+	var groups []domain.ReminderGroup
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &groups)
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *reminderGroupRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
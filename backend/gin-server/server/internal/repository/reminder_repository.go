@@ -11,6 +11,7 @@ type ReminderRepository interface {
 	Create(ctx context.Context, user *domain.Reminder) error
 	GetById(ctx context.Context, id string) (*domain.Reminder, error)
 	GetAllByUserId(ctx context.Context, userId string) ([]domain.Reminder, error)
+	Delete(ctx context.Context, id string) error
 }
 
 type reminderRepository struct {
@@ -49,3 +50,7 @@ func (r *reminderRepository) GetAllByUserId(ctx context.Context, userId string)
 	}
 	return reminders, nil
 }
+
+func (r *reminderRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+)
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *domain.UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error)
+	GetAllByUserId(ctx context.Context, userId string) ([]domain.UserIdentity, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type userIdentityRepository struct {
+	collection db.Collection
+}
+
+// NewUserIdentityRepository creates a new instance of UserIdentityRepository
+func NewUserIdentityRepository(db *db.DBManager) UserIdentityRepository {
+	return &userIdentityRepository{
+		collection: db.DB.Collection(constants.UserIdentitiesCollection),
+	}
+}
+
+// Implementation of UserIdentityRepository interface
+func (r *userIdentityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	_, err := r.collection.Create(ctx, identity)
+	return err
+}
+
+func (r *userIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.collection.GetOne(ctx, map[string]interface{}{"provider": provider, "subject": subject}, &identity)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *userIdentityRepository) GetAllByUserId(ctx context.Context, userId string) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{"user_id": userId}, &identities)
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *userIdentityRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
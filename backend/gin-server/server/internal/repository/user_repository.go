@@ -11,6 +11,10 @@ type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	GetById(ctx context.Context, id string) (*domain.User, error)
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetAll(ctx context.Context) ([]domain.User, error)
+	Update(ctx context.Context, user *domain.User) error
+	Delete(ctx context.Context, id string) error
 }
 
 type userRepository struct {
@@ -49,3 +53,29 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 	}
 	return &user, nil
 }
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var user domain.User
+	err := r.collection.GetOne(ctx, map[string]interface{}{"username": username}, &user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+	err := r.collection.GetAllByCondition(ctx, map[string]interface{}{}, &users)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.collection.UpdateById(ctx, user.ID, user)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id string) error {
+	return r.collection.DeleteById(ctx, id)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+const (
+	// apiTokenBytes is the amount of randomness in a personal access token
+	apiTokenBytes = 32
+	// apiTokenDisplayPrefixLen is how much of the token is kept to help users identify it
+	apiTokenDisplayPrefixLen = len(domain.APITokenPrefix) + 6
+	// apiTokenLastUsedResolution limits how often last-used tracking writes to the database
+	apiTokenLastUsedResolution = time.Minute
+	// maxAPITokensPerUser caps the number of tokens a single user can hold
+	maxAPITokensPerUser = 50
+)
+
+var ErrTooManyAPITokens = errors.New("too many api tokens")
+
+type APITokenService interface {
+	Create(ctx context.Context, caller *auth.CustomClaims, name string, scopes []string, expiresIn time.Duration) (token string, apiToken *domain.APIToken, err error)
+	List(ctx context.Context, userID string) ([]domain.APIToken, error)
+	Revoke(ctx context.Context, userID, tokenID string) error
+	Authenticate(ctx context.Context, token string) (*auth.CustomClaims, error)
+}
+
+type apiTokenService struct {
+	apiTokenRepo repository.APITokenRepository
+	userRepo     repository.UserRepository
+	log          *logger.Logger
+}
+
+func NewAPITokenService(apiTokenRepo repository.APITokenRepository, userRepo repository.UserRepository, log *logger.Logger) APITokenService {
+	return &apiTokenService{
+		apiTokenRepo: apiTokenRepo,
+		userRepo:     userRepo,
+		log:          log,
+	}
+}
+
+// Create issues a new personal access token for the caller. The plain token is only returned here, the
+// database keeps its hash. Wildcards are stored expanded, and a caller authenticated with a personal access
+// token can only grant the scopes it holds.
+func (s *apiTokenService) Create(ctx context.Context, caller *auth.CustomClaims, name string, scopes []string, expiresIn time.Duration) (string, *domain.APIToken, error) {
+	if err := domain.ValidateScopes(scopes); err != nil {
+		return "", nil, err
+	}
+	scopes = domain.ExpandScopes(scopes)
+	for _, scope := range scopes {
+		if !caller.HasScope(scope) {
+			return "", nil, fmt.Errorf("%w: %s", domain.ErrScopeNotHeld, scope)
+		}
+	}
+	userID := caller.EntityID
+
+	existing, err := s.apiTokenRepo.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(existing) >= maxAPITokensPerUser {
+		return "", nil, ErrTooManyAPITokens
+	}
+
+	secret, err := utils.GenerateRandomToken(apiTokenBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	token := domain.APITokenPrefix + secret
+
+	now := time.Now().UTC()
+	apiToken := &domain.APIToken{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(token),
+		Prefix:    token[:apiTokenDisplayPrefixLen],
+		Scopes:    domain.JoinScopes(scopes),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if expiresIn > 0 {
+		apiToken.ExpiresAt = now.Add(expiresIn)
+	}
+
+	if err := s.apiTokenRepo.Create(ctx, apiToken); err != nil {
+		return "", nil, err
+	}
+
+	return token, apiToken, nil
+}
+
+// List returns the tokens owned by the user
+func (s *apiTokenService) List(ctx context.Context, userID string) ([]domain.APIToken, error) {
+	return s.apiTokenRepo.GetAllByUserId(ctx, userID)
+}
+
+// Revoke deletes a token owned by the user
+func (s *apiTokenService) Revoke(ctx context.Context, userID, tokenID string) error {
+	apiToken, err := s.apiTokenRepo.GetById(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return domain.ErrAPITokenNotFound
+		}
+		return err
+	}
+
+	// Do not reveal tokens belonging to other users
+	if apiToken.UserID != userID {
+		return domain.ErrAPITokenNotFound
+	}
+
+	return s.apiTokenRepo.Delete(ctx, tokenID)
+}
+
+// Authenticate resolves a personal access token into claims carrying the owner's identity and the token scopes
+func (s *apiTokenService) Authenticate(ctx context.Context, token string) (*auth.CustomClaims, error) {
+	apiToken, err := s.apiTokenRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if apiToken.IsExpired(now) {
+		return nil, auth.ErrExpiredToken
+	}
+
+	user, err := s.userRepo.GetById(ctx, apiToken.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	if now.Sub(apiToken.LastUsedAt) > apiTokenLastUsedResolution {
+		apiToken.LastUsedAt = now
+		if err := s.apiTokenRepo.Update(ctx, apiToken); err != nil {
+			// Tracking is best effort, a failed write must not reject a valid token
+			s.log.WithContext(ctx).Warnf("Failed to update last used time for api token: %s, error: %v", apiToken.ID, err)
+		}
+	}
+
+	claims := &auth.CustomClaims{
+		TokenType: auth.PersonalAccessToken,
+		EntityID:  user.ID,
+		TokenID:   apiToken.ID,
+		Scopes:    apiToken.ScopeList(),
+		Custom:    userClaims(user),
+	}
+	claims.Subject = user.ID
+
+	return claims, nil
+}
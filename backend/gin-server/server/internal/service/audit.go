@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+)
+
+// recordAudit appends an event to the audit trail. The audited action has already happened,
+// so a failed record is logged instead of failing the action.
+func recordAudit(ctx context.Context, recorder audit.Recorder, log *logger.Logger, event *audit.Event, metadata map[string]interface{}) {
+	if err := event.SetMetadata(metadata); err != nil {
+		log.WithContext(ctx).Warnf("Failed to encode audit metadata, action: %s, error: %v", event.Action, err)
+	}
+
+	if err := recorder.Record(ctx, event); err != nil {
+		log.WithContext(ctx).Errorf("Recording audit event failed, action: %s, actorID: %s, targetID: %s, error: %v", event.Action, event.ActorID, event.TargetID, err)
+	}
+}
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
-	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/usernamegen"
 
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
@@ -16,25 +19,58 @@ import (
 
 type AuthService interface {
 	Register(ctx context.Context, email, password string) (*domain.User, error)
-	Login(ctx context.Context, email, password string) (newAccessToken, newRefreshToken string, error error)
+	Login(ctx context.Context, email, password, clientIP string) (newAccessToken, newRefreshToken string, error error)
 	Refresh(ctx context.Context, refreshToken string) (newAccessToken, newRefreshToken string, error error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
 	GetMe(ctx context.Context, userId string) (*domain.User, error)
+	SessionValidator
 }
 
+// SessionValidator checks that the user behind an authenticated token may still use the API
+// and returns the stored user
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, claims *auth.CustomClaims) (*domain.User, error)
+}
+
+// tokenVersionClaim carries domain.User.TokenVersion in issued tokens
+const tokenVersionClaim = "tokenVersion"
+
 type authService struct {
-	userRepo    repository.UserRepository
-	log         *logger.Logger
-	authManager *auth.AuthManager
+	userRepo       repository.UserRepository
+	usernames      UsernameService
+	loginAttempts  LoginAttemptService
+	passwordPolicy domain.PasswordPolicy
+	auditor        audit.Recorder
+	log            *logger.Logger
+	authManager    *auth.AuthManager
 }
 
-func NewAuthService(userRepo repository.UserRepository, log *logger.Logger, authManager *auth.AuthManager) AuthService {
+func NewAuthService(userRepo repository.UserRepository, usernames UsernameService, loginAttempts LoginAttemptService, passwordPolicy domain.PasswordPolicy, auditor audit.Recorder, log *logger.Logger, authManager *auth.AuthManager) AuthService {
 	return &authService{
-		userRepo:    userRepo,
-		log:         log,
-		authManager: authManager,
+		userRepo:       userRepo,
+		usernames:      usernames,
+		loginAttempts:  loginAttempts,
+		passwordPolicy: passwordPolicy,
+		auditor:        auditor,
+		log:            log,
+		authManager:    authManager,
 	}
 }
 
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// verifyDummyPassword costs as much as a real password check, so unknown emails
+// cannot be told apart from wrong passwords by the response time
+func verifyDummyPassword(password string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = utils.HashPassword("dummy-password-for-timing")
+	})
+	utils.VerifyPassword(dummyPasswordHash, password)
+}
+
 func (s *authService) Register(ctx context.Context, email, password string) (*domain.User, error) {
 
 	// Check if user already exists
@@ -43,7 +79,11 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 		return nil, domain.ErrUserAlreadyExist
 	}
 
-	if err != nil && err != db.ErrNotFound {
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+
+	if err := s.passwordPolicy.Validate(password, email); err != nil {
 		return nil, err
 	}
 
@@ -54,17 +94,20 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 	}
 
 	// Create new user
-	err = s.userRepo.Create(ctx, &domain.User{
+	userID := uuid.New().String()
+	err = createUserWithUsername(ctx, s.userRepo, s.usernames, &domain.User{
 		Email:    email,
 		Password: hashedPassword,
-		Username: usernamegen.Generate(),
-		ID:       uuid.New().String(),
+		ID:       userID,
 		Role:     domain.UserRoleUser,
+		Status:   domain.UserStatusActive,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionRegister, TargetID: userID}, nil)
+
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, err
@@ -72,35 +115,151 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 	return user, nil
 }
 
-func (s *authService) Login(ctx context.Context, email, password string) (string, string, error) {
+func (s *authService) Login(ctx context.Context, email, password, clientIP string) (string, string, error) {
+	// Count the attempt before checking the password, locked out accounts and clients are rejected
+	attempt, err := s.loginAttempts.Attempt(ctx, email, clientIP)
+	if err != nil {
+		if errors.Is(err, domain.ErrTooManyLoginAttempts) {
+			s.recordLoginFailure(ctx, "", email, clientIP, "locked_out")
+		}
+		return "", "", err
+	}
+
 	// Validate user credentials
 	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
 		return "", "", err
 	}
 
-	isPasswordValid := utils.VerifyPassword(user.Password, password)
+	if user == nil {
+		verifyDummyPassword(password)
+	}
+
+	if user == nil || !utils.VerifyPassword(user.Password, password) {
+		if err := s.loginAttempts.RecordFailure(ctx, attempt); err != nil {
+			s.log.WithContext(ctx).Warnf("Failed to record failed login for email: %s, error: %v", email, err)
+		}
 
-	if !isPasswordValid {
+		actorID := ""
+		if user != nil {
+			actorID = user.ID
+		}
+		s.recordLoginFailure(ctx, actorID, email, clientIP, "invalid_credentials")
 		return "", "", domain.ErrInvalidCredentials
 	}
 
+	if err := s.loginAttempts.RecordSuccess(ctx, attempt); err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to reset failed logins for email: %s, error: %v", email, err)
+	}
+
+	// Checked after the password so the status of an account is only revealed to its owner
+	if user.IsDisabled() {
+		s.recordLoginFailure(ctx, user.ID, email, clientIP, "account_disabled")
+		return "", "", domain.ErrAccountDisabled
+	}
+
+	s.upgradePasswordHash(ctx, user, password)
+
 	// Generate tokens
-	accessToken, refreshToken, err := s.authManager.GenerateTokenPair(user.ID, map[string]interface{}{
-		"email":    user.Email,
-		"role":     user.Role,
-		"username": user.Username,
-	})
+	accessToken, refreshToken, err := s.authManager.GenerateTokenPair(user.ID, userClaims(user))
 	if err != nil {
 		return "", "", err
 	}
 
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: user.ID, Action: audit.ActionLogin, TargetID: user.ID, IP: clientIP}, nil)
+
 	return accessToken, refreshToken, nil
 }
 
+// recordLoginFailure audits a rejected login, the actor is only known when the email belongs to a user
+func (s *authService) recordLoginFailure(ctx context.Context, userID, email, clientIP, reason string) {
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{
+		ActorID:  userID,
+		Action:   audit.ActionLogin,
+		TargetID: userID,
+		IP:       clientIP,
+		Outcome:  audit.OutcomeFailure,
+	}, map[string]interface{}{
+		"email":  email,
+		"reason": reason,
+	})
+}
+
+// upgradePasswordHash re-hashes the password when the stored hash uses an outdated algorithm or
+// parameters. The plain password is only known during login, so this is the only chance to do it.
+func (s *authService) upgradePasswordHash(ctx context.Context, user *domain.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to re-hash password for userID: %s, error: %v", user.ID, err)
+		return
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now().UTC()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		// The old hash still works, the upgrade is retried on the next login
+		s.log.WithContext(ctx).Warnf("Failed to store re-hashed password for userID: %s, error: %v", user.ID, err)
+		return
+	}
+
+	s.log.WithContext(ctx).Infof("Upgraded password hash for userID: %s", user.ID)
+}
+
+// userClaims returns the custom claims identifying a user in issued tokens
+func userClaims(user *domain.User) map[string]interface{} {
+	return map[string]interface{}{
+		"email":    user.Email,
+		"role":     user.Role,
+		"username": user.Username,
+
+		tokenVersionClaim: user.TokenVersion,
+	}
+}
+
+// claimsTokenVersion returns the token version a token was issued with, tokens issued before
+// versions were introduced have none and count as version 0
+func claimsTokenVersion(claims *auth.CustomClaims) int {
+	switch v := claims.Custom[tokenVersionClaim].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// checkSession rejects disabled users and sessions issued before the user's tokens were revoked
+func checkSession(user *domain.User, claims *auth.CustomClaims) error {
+	if user.IsDisabled() {
+		return domain.ErrAccountDisabled
+	}
+
+	// Personal access tokens are revoked individually and don't carry a version
+	if claims.TokenType != auth.PersonalAccessToken && claimsTokenVersion(claims) != user.TokenVersion {
+		return domain.ErrSessionRevoked
+	}
+
+	return nil
+}
+
 func (s *authService) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
-	// Validate refresh token
-	newAccessToken, newRefreshToken, err := s.authManager.RefreshTokens(refreshToken)
+	// Validate refresh token, the claims are rebuilt from the stored user so role changes
+	// take effect on the next refresh instead of being copied from the old token
+	newAccessToken, newRefreshToken, err := s.authManager.RefreshTokensWith(refreshToken, func(claims *auth.CustomClaims) (map[string]interface{}, error) {
+		user, err := s.userRepo.GetById(ctx, claims.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSession(user, claims); err != nil {
+			return nil, err
+		}
+		return userClaims(user), nil
+	})
 	if err != nil {
 		return "", "", err
 	}
@@ -108,6 +267,38 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) (string,
 	return newAccessToken, newRefreshToken, nil
 }
 
+// Logout revokes the tokens of a session until they expire. Either token may be missing or
+// already invalid, logging out must always succeed for the client to clear its state.
+func (s *authService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	userID := ""
+	for _, token := range []struct {
+		value     string
+		tokenType auth.TokenType
+	}{
+		{accessToken, auth.AccessToken},
+		{refreshToken, auth.RefreshToken},
+	} {
+		if token.value == "" {
+			continue
+		}
+
+		claims, err := s.authManager.ParseToken(token.value, token.tokenType)
+		if err != nil || claims.TokenID == "" {
+			continue
+		}
+
+		if err := s.authManager.BlacklistToken(claims.TokenID, time.Until(claims.ExpiresAt.Time)); err != nil {
+			return err
+		}
+		userID = claims.EntityID
+	}
+
+	if userID != "" {
+		recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionLogout, TargetID: userID}, nil)
+	}
+	return nil
+}
+
 // GetMe retrieves the user details for the given user ID
 func (s *authService) GetMe(ctx context.Context, userId string) (*domain.User, error) {
 	// Fetch user details
@@ -118,3 +309,19 @@ func (s *authService) GetMe(ctx context.Context, userId string) (*domain.User, e
 
 	return user, nil
 }
+
+// ValidateSession rejects tokens of disabled or deleted users and of revoked sessions
+func (s *authService) ValidateSession(ctx context.Context, claims *auth.CustomClaims) (*domain.User, error) {
+	user, err := s.userRepo.GetById(ctx, claims.EntityID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if err := checkSession(user, claims); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
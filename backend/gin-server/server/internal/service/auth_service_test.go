@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+func setupAuthService(t *testing.T) (AuthService, repository.UserRepository) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	loginAttempts := NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New())
+	authService := NewAuthService(userRepo, newTestUsernameService(t, userRepo), loginAttempts, domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), auth.NewAuthManager(auth.DefaultConfig()))
+	return authService, userRepo
+}
+
+func TestRegisterEnforcesPasswordPolicy(t *testing.T) {
+	authService, _ := setupAuthService(t)
+	ctx := context.Background()
+
+	_, err := authService.Register(ctx, "jane@example.com", "password123")
+	var policyErr *domain.PasswordPolicyError
+	require.True(t, errors.As(err, &policyErr))
+	assert.NotEmpty(t, policyErr.Violations)
+
+	user, err := authService.Register(ctx, "jane@example.com", "Tr0ub4dor&Horse")
+	require.NoError(t, err)
+	assert.False(t, utils.PasswordNeedsRehash(user.Password))
+}
+
+func TestLoginUpgradesLegacyPasswordHash(t *testing.T) {
+	authService, userRepo := setupAuthService(t)
+	ctx := context.Background()
+
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("old-password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, &domain.User{
+		ID:       "legacy-user",
+		Email:    "legacy@example.com",
+		Username: "legacy",
+		Password: string(legacyHash),
+		Role:     domain.UserRoleUser,
+	}))
+
+	_, _, err = authService.Login(ctx, "legacy@example.com", "old-password", "")
+	require.NoError(t, err)
+
+	user, err := userRepo.GetById(ctx, "legacy-user")
+	require.NoError(t, err)
+	assert.False(t, utils.PasswordNeedsRehash(user.Password), "hash should have been upgraded to argon2id")
+	assert.True(t, utils.VerifyPassword(user.Password, "old-password"))
+
+	// The upgraded hash keeps working
+	_, _, err = authService.Login(ctx, "legacy@example.com", "old-password", "")
+	assert.NoError(t, err)
+}
+
+func TestLoginIsAudited(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	loginAttempts := NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New())
+	auditor := &memoryRecorder{}
+	authService := NewAuthService(userRepo, newTestUsernameService(t, userRepo), loginAttempts, domain.DefaultPasswordPolicy(), auditor, logger.New(), auth.NewAuthManager(auth.DefaultConfig()))
+	ctx := context.Background()
+
+	user, err := authService.Register(ctx, "jane@example.com", "Tr0ub4dor&Horse")
+	require.NoError(t, err)
+	assert.Equal(t, audit.Event{ActorID: user.ID, Action: audit.ActionRegister, TargetID: user.ID}, auditor.last(t))
+
+	_, _, err = authService.Login(ctx, "jane@example.com", "wrong-password", "203.0.113.7")
+	require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	failure := auditor.last(t)
+	assert.Equal(t, user.ID, failure.ActorID)
+	assert.Equal(t, audit.OutcomeFailure, failure.Outcome)
+	assert.Equal(t, "203.0.113.7", failure.IP)
+	assert.JSONEq(t, `{"email":"jane@example.com","reason":"invalid_credentials"}`, failure.Metadata)
+
+	// Unknown emails are recorded without an actor
+	_, _, err = authService.Login(ctx, "nobody@example.com", "wrong-password", "203.0.113.7")
+	require.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.Empty(t, auditor.last(t).ActorID)
+
+	_, _, err = authService.Login(ctx, "jane@example.com", "Tr0ub4dor&Horse", "203.0.113.7")
+	require.NoError(t, err)
+	success := auditor.last(t)
+	assert.Equal(t, audit.ActionLogin, success.Action)
+	assert.Equal(t, user.ID, success.ActorID)
+	assert.Empty(t, success.Outcome, "defaulted to success by the trail")
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	loginAttempts := NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New())
+	authManager := auth.NewAuthManager(auth.DefaultConfig())
+	auditor := &memoryRecorder{}
+	authService := NewAuthService(userRepo, newTestUsernameService(t, userRepo), loginAttempts, domain.DefaultPasswordPolicy(), auditor, logger.New(), authManager)
+	ctx := context.Background()
+
+	user := createTestUser(t, userRepo, "alice", domain.UserRoleUser)
+	accessToken, refreshToken, err := authManager.GenerateTokenPair(user.ID, userClaims(user))
+	require.NoError(t, err)
+
+	require.NoError(t, authService.Logout(ctx, accessToken, refreshToken))
+	assert.Equal(t, audit.Event{ActorID: "alice", Action: audit.ActionLogout, TargetID: "alice"}, auditor.last(t))
+
+	_, err = authManager.ParseToken(accessToken, auth.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	_, _, err = authService.Refresh(ctx, refreshToken)
+	assert.Error(t, err)
+
+	// Logging out again or without tokens still succeeds
+	require.NoError(t, authService.Logout(ctx, accessToken, refreshToken))
+	require.NoError(t, authService.Logout(ctx, "", ""))
+	assert.Len(t, auditor.actions(), 1)
+}
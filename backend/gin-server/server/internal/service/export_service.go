@@ -0,0 +1,443 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+const (
+	// exportJobTimeout bounds how long assembling an archive may take. Unfinished jobs older
+	// than this, e.g. interrupted by a restart, are marked as failed so the user can retry.
+	exportJobTimeout = 15 * time.Minute
+	// exportManifestFile describes the archive contents
+	exportManifestFile = "manifest.json"
+)
+
+// ExportSection is one JSON file of a personal data export. Its records are read from a
+// collection through db.Collection, so sections work on every database backend.
+type ExportSection struct {
+	File       string             // Name of the file in the archive
+	Collection string             // Collection holding the records
+	OwnerField string             // Field referencing the user the records belong to
+	Single     bool               // Write the first record as an object instead of a list
+	NewRecords func() interface{} // Returns a pointer to an empty slice of the record type
+}
+
+// DefaultExportSections lists the personal data included in an export.
+// Secrets such as password and token hashes are excluded by the models' json tags.
+// There is no focus session section, the app does not store focus sessions yet.
+func DefaultExportSections() []ExportSection {
+	return []ExportSection{
+		{File: "profile.json", Collection: constants.UsersCollection, OwnerField: "id", Single: true, NewRecords: func() interface{} { return &[]domain.User{} }},
+		{File: "reminder_groups.json", Collection: constants.ReminderGroupsCollection, OwnerField: "user_id", NewRecords: func() interface{} { return &[]domain.ReminderGroup{} }},
+		{File: "reminders.json", Collection: constants.RemindersCollection, OwnerField: "user_id", NewRecords: func() interface{} { return &[]domain.Reminder{} }},
+		{File: "api_tokens.json", Collection: constants.APITokensCollection, OwnerField: "user_id", NewRecords: func() interface{} { return &[]domain.APIToken{} }},
+		{File: "identities.json", Collection: constants.UserIdentitiesCollection, OwnerField: "user_id", NewRecords: func() interface{} { return &[]domain.UserIdentity{} }},
+		{File: "activity.json", Collection: constants.AuditEventsCollection, OwnerField: "actor_id", NewRecords: func() interface{} { return &[]audit.Event{} }},
+	}
+}
+
+// ExportConfig controls where archives are stored and how download links are built
+type ExportConfig struct {
+	Dir        string        // Directory holding the archives
+	TTL        time.Duration // How long an archive and its download link stay valid
+	SigningKey []byte        // Key signing download links
+	BaseURL    string        // Public URL of the app, prefixed to download links
+}
+
+type ExportService interface {
+	Start(ctx context.Context, userID string) (*domain.ExportJob, error)
+	Get(ctx context.Context, userID, jobID string) (*domain.ExportJob, error)
+	DownloadURL(job *domain.ExportJob) string
+	Open(ctx context.Context, jobID, expires, signature string) (*domain.ExportJob, error)
+	PurgeExpired(ctx context.Context) error
+	RunPurge(ctx context.Context, interval time.Duration)
+}
+
+type exportService struct {
+	dbManager *db.DBManager
+	jobRepo   repository.ExportJobRepository
+	sections  []ExportSection
+	cfg       ExportConfig
+	log       *logger.Logger
+	now       func() time.Time
+}
+
+func NewExportService(dbManager *db.DBManager, jobRepo repository.ExportJobRepository, sections []ExportSection, cfg ExportConfig, log *logger.Logger) ExportService {
+	return &exportService{
+		dbManager: dbManager,
+		jobRepo:   jobRepo,
+		sections:  sections,
+		cfg:       cfg,
+		log:       log,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Start queues a new export of the user's data and assembles it in the background
+func (s *exportService) Start(ctx context.Context, userID string) (*domain.ExportJob, error) {
+	jobs, err := s.jobRepo.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quick check, the claim taken below is what guarantees a single export in progress
+	now := s.now()
+	for i := range jobs {
+		if jobs[i].IsActive() && now.Sub(jobs[i].CreatedAt) < exportJobTimeout {
+			return nil, domain.ErrExportInProgress
+		}
+	}
+
+	job := &domain.ExportJob{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    domain.ExportJobStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := s.claim(ctx, job); err != nil {
+		if err := ignoreNotFound(s.jobRepo.Delete(ctx, job.ID)); err != nil {
+			s.log.WithContext(ctx).Errorf("Deleting unclaimed export job failed for jobID: %s, error: %v", job.ID, err)
+		}
+		return nil, err
+	}
+
+	go s.run(context.WithoutCancel(ctx), *job)
+
+	return job, nil
+}
+
+// claim atomically makes the created job the user's export in progress. Checking the user's jobs
+// alone would let concurrent requests, possibly served by several instances, each start an export.
+func (s *exportService) claim(ctx context.Context, job *domain.ExportJob) error {
+	claim := &domain.ExportClaim{ID: job.UserID, JobID: job.ID, CreatedAt: job.CreatedAt}
+
+	err := s.jobRepo.CreateClaim(ctx, claim)
+	if !errors.Is(err, db.ErrDuplicate) {
+		return err
+	}
+
+	previous, err := s.jobRepo.GetClaim(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+	// The claim is only taken over once its job has finished, was abandoned or deleted
+	previousJob, err := s.jobRepo.GetById(ctx, previous.JobID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	if previousJob != nil && previousJob.IsActive() && job.CreatedAt.Sub(previousJob.CreatedAt) < exportJobTimeout {
+		return domain.ErrExportInProgress
+	}
+
+	err = s.jobRepo.ReplaceClaim(ctx, claim, previous.JobID)
+	if errors.Is(err, db.ErrConflict) {
+		// Taken over concurrently
+		return domain.ErrExportInProgress
+	}
+	return err
+}
+
+// Get returns an export job of the user
+func (s *exportService) Get(ctx context.Context, userID, jobID string) (*domain.ExportJob, error) {
+	job, err := s.jobRepo.GetById(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrExportJobNotFound
+		}
+		return nil, err
+	}
+
+	// Jobs of other users are reported as missing so their ids can't be probed
+	if job.UserID != userID {
+		return nil, domain.ErrExportJobNotFound
+	}
+
+	return job, nil
+}
+
+// DownloadURL returns the signed link to the archive of a finished job, it expires with the archive
+func (s *exportService) DownloadURL(job *domain.ExportJob) string {
+	if job.Status != domain.ExportJobStatusDone {
+		return ""
+	}
+
+	expires := strconv.FormatInt(job.ExpiresAt.Unix(), 10)
+	return fmt.Sprintf("%s/api/exports/%s/download?expires=%s&signature=%s",
+		s.cfg.BaseURL, url.PathEscape(job.ID), expires, s.sign(job.ID, expires))
+}
+
+// Open validates a download link and returns the job whose archive it points to
+func (s *exportService) Open(ctx context.Context, jobID, expires, signature string) (*domain.ExportJob, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(jobID, expires))) {
+		return nil, domain.ErrInvalidDownloadLink
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.now().Unix() >= expiresAt {
+		return nil, domain.ErrInvalidDownloadLink
+	}
+
+	job, err := s.jobRepo.GetById(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrInvalidDownloadLink
+		}
+		return nil, err
+	}
+
+	if job.Status != domain.ExportJobStatusDone || job.IsExpired(s.now()) {
+		return nil, domain.ErrInvalidDownloadLink
+	}
+
+	return job, nil
+}
+
+// PurgeExpired removes archives past their retention and fails jobs that never finished
+func (s *exportService) PurgeExpired(ctx context.Context) error {
+	now := s.now()
+
+	for _, status := range []string{domain.ExportJobStatusDone, domain.ExportJobStatusFailed} {
+		jobs, err := s.jobRepo.GetAllByStatus(ctx, status)
+		if err != nil {
+			return err
+		}
+
+		for i := range jobs {
+			job := &jobs[i]
+			// Failed jobs have no archive, they are kept as long as a finished one would be
+			if status == domain.ExportJobStatusFailed && now.Sub(job.UpdatedAt) < s.cfg.TTL {
+				continue
+			}
+			if status == domain.ExportJobStatusDone && !job.IsExpired(now) {
+				continue
+			}
+
+			if err := s.removeArchive(job); err != nil {
+				return err
+			}
+			if err := ignoreNotFound(s.jobRepo.Delete(ctx, job.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, status := range []string{domain.ExportJobStatusPending, domain.ExportJobStatusRunning} {
+		jobs, err := s.jobRepo.GetAllByStatus(ctx, status)
+		if err != nil {
+			return err
+		}
+
+		for i := range jobs {
+			job := &jobs[i]
+			if now.Sub(job.CreatedAt) < exportJobTimeout {
+				continue
+			}
+			job.Status = domain.ExportJobStatusFailed
+			job.Error = "export timed out"
+			job.UpdatedAt = now
+			if err := s.jobRepo.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunPurge purges expired exports every interval until the context is done, so archives are
+// removed on schedule even when no new export is started
+func (s *exportService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+			s.log.Errorf("Purging expired exports failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run assembles the archive of a job, recording the outcome on the job. The job outlives the
+// request starting it, base keeps the request values so the job logs link back to it.
+func (s *exportService) run(base context.Context, job domain.ExportJob) {
+	ctx, cancel := context.WithTimeout(base, exportJobTimeout)
+	defer cancel()
+
+	if err := s.PurgeExpired(ctx); err != nil {
+		s.log.WithContext(ctx).Warnf("Purging expired exports failed: %v", err)
+	}
+
+	job.Status = domain.ExportJobStatusRunning
+	job.UpdatedAt = s.now()
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		s.log.WithContext(ctx).Errorf("Starting export failed for jobID: %s, error: %v", job.ID, err)
+		return
+	}
+
+	path, size, err := s.writeArchive(ctx, &job)
+	now := s.now()
+	if err != nil {
+		s.log.WithContext(ctx).Errorf("Export failed for userID: %s, jobID: %s, error: %v", job.UserID, job.ID, err)
+		job.Status = domain.ExportJobStatusFailed
+		job.Error = "failed to assemble the export"
+	} else {
+		s.log.WithContext(ctx).Infof("Export finished for userID: %s, jobID: %s", job.UserID, job.ID)
+		job.Status = domain.ExportJobStatusDone
+		job.FilePath = path
+		job.Size = size
+		job.CompletedAt = now
+		job.ExpiresAt = now.Add(s.cfg.TTL)
+	}
+	job.UpdatedAt = now
+
+	if err := s.jobRepo.Update(ctx, &job); err != nil {
+		s.log.WithContext(ctx).Errorf("Recording export result failed for jobID: %s, error: %v", job.ID, err)
+		// The job was deleted with its account while running, its archive must not outlive it
+		if errors.Is(err, db.ErrNotFound) {
+			if err := s.removeArchive(&job); err != nil {
+				s.log.WithContext(ctx).Errorf("Removing export archive failed for jobID: %s, error: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+// writeArchive writes every section to a ZIP file and returns its path and size
+func (s *exportService) writeArchive(ctx context.Context, job *domain.ExportJob) (string, int64, error) {
+	if err := os.MkdirAll(s.cfg.Dir, 0o700); err != nil {
+		return "", 0, err
+	}
+
+	// Write to a temporary file so a half-written archive is never served
+	tmp, err := os.CreateTemp(s.cfg.Dir, job.ID+"-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	files := make([]string, 0, len(s.sections))
+
+	for _, section := range s.sections {
+		payload, err := s.readSection(ctx, section, job.UserID)
+		if err != nil {
+			return "", 0, fmt.Errorf("section %s: %w", section.File, err)
+		}
+		if err := writeZipJSON(zw, section.File, payload); err != nil {
+			return "", 0, err
+		}
+		files = append(files, section.File)
+	}
+
+	manifest := map[string]interface{}{
+		"userId":     job.UserID,
+		"jobId":      job.ID,
+		"exportedAt": s.now(),
+		"files":      files,
+	}
+	if err := writeZipJSON(zw, exportManifestFile, manifest); err != nil {
+		return "", 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", 0, err
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	path := filepath.Join(s.cfg.Dir, job.ID+".zip")
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+
+	return path, info.Size(), nil
+}
+
+// readSection loads the records of a section owned by the user
+func (s *exportService) readSection(ctx context.Context, section ExportSection, userID string) (interface{}, error) {
+	records := section.NewRecords()
+	collection := s.dbManager.DB.Collection(section.Collection)
+	if err := collection.GetAllByCondition(ctx, map[string]interface{}{section.OwnerField: userID}, records); err != nil {
+		return nil, err
+	}
+
+	list := reflect.ValueOf(records).Elem()
+	if section.Single {
+		if list.Len() == 0 {
+			return nil, db.ErrNotFound
+		}
+		return list.Index(0).Interface(), nil
+	}
+
+	// Empty sections are written as [] rather than null
+	if list.IsNil() {
+		list.Set(reflect.MakeSlice(list.Type(), 0, 0))
+	}
+	return records, nil
+}
+
+func (s *exportService) removeArchive(job *domain.ExportJob) error {
+	if job.FilePath == "" {
+		return nil
+	}
+	if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// sign returns the signature of a download link for a job and expiry
+func (s *exportService) sign(jobID, expires string) string {
+	mac := hmac.New(sha256.New, s.cfg.SigningKey)
+	mac.Write([]byte(jobID + "." + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// exportTestEnv holds an ExportService backed by a migrated SQLite database
+type exportTestEnv struct {
+	service  *exportService
+	userRepo repository.UserRepository
+	jobRepo  repository.ExportJobRepository
+	clock    *testClock
+}
+
+func setupExportService(t *testing.T) *exportTestEnv {
+	dbManager := setupTestDB(t)
+
+	env := &exportTestEnv{
+		userRepo: repository.NewUserRepository(dbManager),
+		jobRepo:  repository.NewExportJobRepository(dbManager),
+		clock:    newTestClock(),
+	}
+	env.service = NewExportService(dbManager, env.jobRepo, DefaultExportSections(), ExportConfig{
+		Dir:        t.TempDir(),
+		TTL:        time.Hour,
+		SigningKey: []byte("test-signing-key"),
+		BaseURL:    "http://app.test",
+	}, logger.New()).(*exportService)
+	env.service.now = env.clock.Now
+
+	return env
+}
+
+// waitForExport polls a job until it has finished
+func waitForExport(t *testing.T, env *exportTestEnv, userID, jobID string) *domain.ExportJob {
+	var job *domain.ExportJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = env.service.Get(context.Background(), userID, jobID)
+		require.NoError(t, err)
+		return !job.IsActive()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// readArchive returns the files of a ZIP archive by name
+func readArchive(t *testing.T, path string) map[string][]byte {
+	reader, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	files := make(map[string][]byte)
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = content
+	}
+	return files
+}
+
+// downloadParams splits a download link into the values passed to Open
+func downloadParams(t *testing.T, link string) (jobID, expires, signature string) {
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	require.Len(t, parts, 4)
+	return parts[2], u.Query().Get("expires"), u.Query().Get("signature")
+}
+
+func TestExportAssemblesUserData(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+	dbManager := env.service.dbManager
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	createTestUser(t, env.userRepo, "bob", domain.UserRoleUser)
+	groups := repository.NewReminderGroupRepository(dbManager)
+	reminders := repository.NewReminderRepository(dbManager)
+	require.NoError(t, groups.Create(ctx, &domain.ReminderGroup{ID: "alice-group", Name: "Errands", UserID: "alice", CreatedAt: time.Now()}))
+	require.NoError(t, reminders.Create(ctx, &domain.Reminder{ID: "alice-reminder", Title: "Buy milk", UserID: "alice", ReminderGroupID: "alice-group", CreatedAt: time.Now()}))
+	require.NoError(t, reminders.Create(ctx, &domain.Reminder{ID: "bob-reminder", Title: "Call mom", UserID: "bob", CreatedAt: time.Now()}))
+
+	job, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExportJobStatusPending, job.Status)
+
+	job = waitForExport(t, env, "alice", job.ID)
+	require.Equal(t, domain.ExportJobStatusDone, job.Status, job.Error)
+	assert.Equal(t, env.clock.Now().Add(time.Hour), job.ExpiresAt)
+
+	files := readArchive(t, job.FilePath)
+	assert.Contains(t, files, "manifest.json")
+	assert.Contains(t, files, "identities.json")
+
+	var profile map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "alice@example.com", profile["email"])
+	assert.NotContains(t, profile, "password")
+
+	var exportedReminders []domain.Reminder
+	require.NoError(t, json.Unmarshal(files["reminders.json"], &exportedReminders))
+	require.Len(t, exportedReminders, 1)
+	assert.Equal(t, "alice-reminder", exportedReminders[0].ID)
+
+	// Empty sections are lists, not null
+	assert.JSONEq(t, "[]", string(files["api_tokens.json"]))
+
+	// Other users can't see the job
+	_, err = env.service.Get(ctx, "bob", job.ID)
+	assert.ErrorIs(t, err, domain.ErrExportJobNotFound)
+}
+
+func TestExportRejectsConcurrentJobs(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	now := env.clock.Now()
+	require.NoError(t, env.jobRepo.Create(ctx, &domain.ExportJob{ID: "running", UserID: "alice", Status: domain.ExportJobStatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+	_, err := env.service.Start(ctx, "alice")
+	assert.ErrorIs(t, err, domain.ErrExportInProgress)
+
+	// An abandoned job doesn't block new exports
+	env.clock.Advance(exportJobTimeout)
+	job, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	waitForExport(t, env, "alice", job.ID)
+
+	abandoned, err := env.jobRepo.GetById(ctx, "running")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExportJobStatusFailed, abandoned.Status)
+}
+
+func TestExportClaimsOneJobAtATime(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+
+	// Keep the first job running until every request was answered
+	release := make(chan struct{})
+	env.service.sections = append(env.service.sections, ExportSection{
+		File:       "blocked.json",
+		Collection: constants.UsersCollection,
+		OwnerField: "id",
+		NewRecords: func() interface{} {
+			<-release
+			return &[]domain.User{}
+		},
+	})
+
+	const requests = 10
+	var wg sync.WaitGroup
+	started := make(chan *domain.ExportJob, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := env.service.Start(ctx, "alice")
+			if err == nil {
+				started <- job
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrExportInProgress)
+		}()
+	}
+	wg.Wait()
+	close(started)
+
+	close(release)
+	require.Len(t, started, 1)
+	job := <-started
+	waitForExport(t, env, "alice", job.ID)
+
+	// Rejected requests leave no job behind
+	jobs, err := env.jobRepo.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	// The claim of a finished job is taken over
+	next, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	waitForExport(t, env, "alice", next.ID)
+
+	claim, err := env.jobRepo.GetClaim(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, next.ID, claim.JobID)
+}
+
+func TestExportDownloadLink(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	job, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	job = waitForExport(t, env, "alice", job.ID)
+
+	link := env.service.DownloadURL(job)
+	assert.True(t, strings.HasPrefix(link, "http://app.test/api/exports/"+job.ID+"/download?"))
+	jobID, expires, signature := downloadParams(t, link)
+
+	opened, err := env.service.Open(ctx, jobID, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, job.FilePath, opened.FilePath)
+
+	// Tampering with any part of the link invalidates it
+	_, err = env.service.Open(ctx, jobID, expires, strings.Repeat("0", len(signature)))
+	assert.ErrorIs(t, err, domain.ErrInvalidDownloadLink)
+	_, err = env.service.Open(ctx, jobID, expires+"0", signature)
+	assert.ErrorIs(t, err, domain.ErrInvalidDownloadLink)
+	_, err = env.service.Open(ctx, "other-job", expires, signature)
+	assert.ErrorIs(t, err, domain.ErrInvalidDownloadLink)
+
+	// Links expire with the archive
+	env.clock.Advance(time.Hour)
+	_, err = env.service.Open(ctx, jobID, expires, signature)
+	assert.ErrorIs(t, err, domain.ErrInvalidDownloadLink)
+}
+
+func TestExportPurgeExpired(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	job, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	job = waitForExport(t, env, "alice", job.ID)
+
+	require.NoError(t, env.service.PurgeExpired(ctx))
+	_, err = os.Stat(job.FilePath)
+	require.NoError(t, err, "archive removed before it expired")
+
+	env.clock.Advance(time.Hour)
+	require.NoError(t, env.service.PurgeExpired(ctx))
+
+	_, err = os.Stat(job.FilePath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = env.service.Get(ctx, "alice", job.ID)
+	assert.ErrorIs(t, err, domain.ErrExportJobNotFound)
+}
+
+func TestExportRunPurgeRemovesExpiredArchives(t *testing.T) {
+	env := setupExportService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	job, err := env.service.Start(ctx, "alice")
+	require.NoError(t, err)
+	job = waitForExport(t, env, "alice", job.ID)
+	env.clock.Advance(time.Hour)
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		env.service.RunPurge(runCtx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(job.FilePath)
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	stop()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunPurge did not stop with its context")
+	}
+}
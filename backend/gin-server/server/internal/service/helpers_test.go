@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/config"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/constants"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/usernamegen"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// setupTestDB returns a migrated SQLite database that is removed when the test ends
+func setupTestDB(t *testing.T) *db.DBManager {
+	ctx := context.Background()
+
+	database, err := db.NewSQLiteDatabase(&config.Config{
+		DBType:     constants.SQLite,
+		SQLiteFile: filepath.Join(t.TempDir(), "test.db"),
+	}, logger.New())
+	require.NoError(t, err)
+	require.NoError(t, database.Connect(ctx))
+	require.NoError(t, database.Migrate(ctx))
+	t.Cleanup(func() { database.Close(ctx) })
+
+	return &db.DBManager{DB: database}
+}
+
+// newTestUsernameService returns a UsernameService with the default word lists and a fixed seed
+func newTestUsernameService(t *testing.T, userRepo repository.UserRepository) UsernameService {
+	generator, err := usernamegen.New(usernamegen.WithSeed(1))
+	require.NoError(t, err)
+	return NewUsernameService(userRepo, generator)
+}
+
+// testClock is a manually advanced clock, safe to read from background jobs
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// memoryRecorder keeps recorded audit events in memory
+type memoryRecorder struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (r *memoryRecorder) Record(ctx context.Context, event *audit.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// actions returns the actions recorded so far, oldest first
+func (r *memoryRecorder) actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actions := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		actions = append(actions, event.Action)
+	}
+	return actions
+}
+
+// last returns the most recently recorded event
+func (r *memoryRecorder) last(t *testing.T) audit.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.NotEmpty(t, r.events)
+	return r.events[len(r.events)-1]
+}
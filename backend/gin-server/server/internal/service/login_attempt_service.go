@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// LoginAttemptPolicy configures when accounts and client IPs are locked out
+type LoginAttemptPolicy struct {
+	MaxFailures   int           // Failed attempts per account before it is locked
+	IPMaxFailures int           // Failed attempts per client IP before it is locked
+	LockoutBase   time.Duration // First lockout duration, doubled on every further failure
+	LockoutMax    time.Duration // Upper bound of a lockout
+	FailureWindow time.Duration // Failures older than this are forgotten
+}
+
+// DefaultLoginAttemptPolicy returns the policy used when nothing is configured
+func DefaultLoginAttemptPolicy() LoginAttemptPolicy {
+	return LoginAttemptPolicy{
+		MaxFailures:   5,
+		IPMaxFailures: 20,
+		LockoutBase:   time.Minute,
+		LockoutMax:    time.Hour,
+		FailureWindow: 15 * time.Minute,
+	}
+}
+
+type LoginAttemptService interface {
+	// Attempt counts a login attempt of the email from the client IP before its password is
+	// checked. It returns a *domain.LoginLockedError when either is locked out or has no attempt left.
+	Attempt(ctx context.Context, email, clientIP string) (*CountedAttempt, error)
+	// RecordFailure locks out the account and client IP whose last allowed attempt failed
+	RecordFailure(ctx context.Context, attempt *CountedAttempt) error
+	RecordSuccess(ctx context.Context, attempt *CountedAttempt) error
+	ListLocked(ctx context.Context) ([]domain.LoginAttempt, error)
+	Clear(ctx context.Context, id string) error
+	// RunCleanup deletes the expired attempts every interval until ctx is done
+	RunCleanup(ctx context.Context, interval time.Duration)
+}
+
+// CountedAttempt is a login attempt counted by Attempt, whose password is yet to be checked
+type CountedAttempt struct {
+	subjects []countedSubject
+}
+
+// countedSubject is the account or client IP side of a counted attempt
+type countedSubject struct {
+	id      string
+	kind    string
+	subject string
+	// previous is the lockout or reset the current round started from, nil for a first round
+	previous *domain.LoginAttempt
+	key      string
+	// allowed is the number of attempts of the round, zero when the subject is not limited
+	allowed   int64
+	count     int64
+	lockout   time.Duration
+	expiresAt time.Time
+}
+
+type loginAttemptService struct {
+	attemptRepo repository.LoginAttemptRepository
+	policy      LoginAttemptPolicy
+	log         *logger.Logger
+	now         func() time.Time
+}
+
+// NewLoginAttemptService creates a new LoginAttemptService instance
+func NewLoginAttemptService(attemptRepo repository.LoginAttemptRepository, policy LoginAttemptPolicy, log *logger.Logger) LoginAttemptService {
+	return &loginAttemptService{
+		attemptRepo: attemptRepo,
+		policy:      policy,
+		log:         log,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Attempt counts the attempt against the account and the client IP with one atomic increment
+// each, so concurrent guesses can't all slip in before a lockout is stored. Unknown emails are
+// counted too, so lockouts do not reveal which exist.
+func (s *loginAttemptService) Attempt(ctx context.Context, email, clientIP string) (*CountedAttempt, error) {
+	now := s.now()
+
+	attempt := &CountedAttempt{}
+	if err := s.addSubject(ctx, attempt, domain.LoginAttemptKindAccount, normalizeEmail(email), s.policy.MaxFailures, now); err != nil {
+		return nil, err
+	}
+	if clientIP != "" {
+		if err := s.addSubject(ctx, attempt, domain.LoginAttemptKindIP, clientIP, s.policy.IPMaxFailures, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Both lockouts are looked up before counting, so a locked client IP doesn't use up the
+	// attempts of the account
+	var retryAfter time.Duration
+	for _, subject := range attempt.subjects {
+		if subject.previous != nil && subject.previous.IsLocked(now) {
+			retryAfter = max(retryAfter, subject.previous.LockedUntil.Sub(now))
+		}
+	}
+	if retryAfter > 0 {
+		return nil, &domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	for i := range attempt.subjects {
+		subject := &attempt.subjects[i]
+		if subject.allowed == 0 {
+			continue
+		}
+
+		count, err := s.attemptRepo.Increment(ctx, subject.key, 1, subject.expiresAt)
+		if err != nil {
+			s.takeBack(ctx, attempt.subjects[:i])
+			if errors.Is(err, repository.ErrLoginAttemptsFull) {
+				// Turning the attempt away is safer than letting it through uncounted
+				s.log.WithContext(ctx).Warnf("Login attempt of %s %s turned away: %v", subject.kind, subject.subject, err)
+				return nil, &domain.LoginLockedError{RetryAfter: s.policy.FailureWindow}
+			}
+			return nil, err
+		}
+		subject.count = count
+
+		// Concurrent attempts used up the round, the last allowed one locks the subject if it fails
+		if count > subject.allowed {
+			retryAfter = max(retryAfter, subject.lockout)
+		}
+	}
+
+	if retryAfter > 0 {
+		s.takeBack(ctx, attempt.subjects)
+		return nil, &domain.LoginLockedError{RetryAfter: retryAfter}
+	}
+	return attempt, nil
+}
+
+// addSubject adds the account or client IP to the attempt, with the round it is counted in
+func (s *loginAttemptService) addSubject(ctx context.Context, attempt *CountedAttempt, kind, subject string, threshold int, now time.Time) error {
+	id := domain.LoginAttemptID(kind, subject)
+
+	previous, err := s.attemptRepo.GetById(ctx, id)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	if previous != nil && previous.IsExpired(now) {
+		previous = nil
+	}
+
+	counted := countedSubject{
+		id:       id,
+		kind:     kind,
+		subject:  subject,
+		previous: previous,
+		lockout:  min(s.policy.LockoutBase, s.policy.LockoutMax),
+	}
+	if threshold > 0 {
+		counted.allowed = int64(threshold)
+	}
+
+	var round int64
+	if previous != nil {
+		round = previous.Round
+		if last := previous.Lockout(); last > 0 && threshold > 0 {
+			// Once locked out, every further failure locks again for twice as long
+			counted.allowed = 1
+			counted.lockout = min(2*last, s.policy.LockoutMax)
+		}
+	}
+
+	// Attempts are counted in fixed windows, so the count expires on the clock of the service
+	window := now.Truncate(s.policy.FailureWindow)
+	counted.key = fmt.Sprintf("%s:%d:%d", id, round, window.Unix())
+	counted.expiresAt = window.Add(s.policy.FailureWindow)
+
+	attempt.subjects = append(attempt.subjects, counted)
+	return nil
+}
+
+// takeBack uncounts the attempts of the subjects that were still within their round
+func (s *loginAttemptService) takeBack(ctx context.Context, subjects []countedSubject) {
+	for _, subject := range subjects {
+		if subject.count == 0 || subject.count > subject.allowed {
+			continue
+		}
+		if _, err := s.attemptRepo.Increment(ctx, subject.key, -1, subject.expiresAt); err != nil {
+			s.log.WithContext(ctx).Warnf("Failed to uncount login attempt of %s %s, error: %v", subject.kind, subject.subject, err)
+		}
+	}
+}
+
+// RecordFailure locks the subjects this attempt used the last allowed attempt of. Exactly one
+// attempt per round gets that count, the concurrent ones past it are already turned away.
+func (s *loginAttemptService) RecordFailure(ctx context.Context, attempt *CountedAttempt) error {
+	now := s.now()
+
+	for _, subject := range attempt.subjects {
+		if subject.allowed == 0 || subject.count != subject.allowed {
+			continue
+		}
+
+		failures := int(subject.count)
+		createdAt := now
+		if subject.previous != nil {
+			createdAt = subject.previous.CreatedAt
+			if subject.previous.Lockout() > 0 {
+				failures += subject.previous.Failures
+			}
+		}
+
+		lockedUntil := now.Add(subject.lockout)
+		err := s.attemptRepo.Save(ctx, &domain.LoginAttempt{
+			ID:          subject.id,
+			Kind:        subject.kind,
+			Subject:     subject.subject,
+			Round:       now.UnixNano(),
+			Failures:    failures,
+			LockedAt:    now,
+			LockedUntil: lockedUntil,
+			ExpiresAt:   lockedUntil.Add(s.policy.FailureWindow),
+			CreatedAt:   createdAt,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+
+		s.log.WithContext(ctx).Warnf("Login locked for %s: %s until %s after %d failures", subject.kind, subject.subject, lockedUntil.Format(time.RFC3339), failures)
+	}
+
+	return nil
+}
+
+// RecordSuccess forgets the failures of the account. The client IP only gets this attempt back,
+// otherwise an attacker could clear its count by logging into an account of their own between guesses.
+func (s *loginAttemptService) RecordSuccess(ctx context.Context, attempt *CountedAttempt) error {
+	for _, subject := range attempt.subjects {
+		if subject.allowed == 0 {
+			continue
+		}
+
+		delta := int64(-1)
+		if subject.kind == domain.LoginAttemptKindAccount {
+			if subject.previous != nil && subject.previous.Lockout() > 0 {
+				// Start a new round, which also forgets the lockouts the account escalated through
+				if err := s.reset(ctx, subject.previous); err != nil {
+					return err
+				}
+				continue
+			}
+			delta = -subject.count
+		}
+
+		// Attempts counted concurrently after this one still count
+		if _, err := s.attemptRepo.Increment(ctx, subject.key, delta, subject.expiresAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reset lifts the lockout and starts a new round of attempts, with a fresh count
+func (s *loginAttemptService) reset(ctx context.Context, attempt *domain.LoginAttempt) error {
+	now := s.now()
+
+	reset := *attempt
+	reset.Round = now.UnixNano()
+	reset.LockedAt = now
+	reset.LockedUntil = now
+	reset.ExpiresAt = now.Add(s.policy.FailureWindow)
+	reset.UpdatedAt = now
+	return s.attemptRepo.Save(ctx, &reset)
+}
+
+// ListLocked returns the accounts and client IPs that are currently locked out
+func (s *loginAttemptService) ListLocked(ctx context.Context) ([]domain.LoginAttempt, error) {
+	return s.attemptRepo.ListLocked(ctx, s.now())
+}
+
+// Clear lifts a lockout and forgets its failures
+func (s *loginAttemptService) Clear(ctx context.Context, id string) error {
+	attempt, err := s.attemptRepo.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return domain.ErrLoginAttemptNotFound
+		}
+		return err
+	}
+	if !attempt.IsLocked(s.now()) {
+		return domain.ErrLoginAttemptNotFound
+	}
+
+	if err := s.reset(ctx, attempt); err != nil {
+		return err
+	}
+
+	s.log.WithContext(ctx).Infof("Login lockout cleared id: %s", id)
+	return nil
+}
+
+func (s *loginAttemptService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.attemptRepo.DeleteExpired(ctx, s.now()); err != nil && ctx.Err() == nil {
+			s.log.Errorf("Deleting expired login attempts failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
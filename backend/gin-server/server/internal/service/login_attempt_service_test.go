@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+// retryAfter asserts that err is a lockout and returns its remaining duration
+func retryAfter(t *testing.T, err error) time.Duration {
+	var lockedErr *domain.LoginLockedError
+	require.True(t, errors.As(err, &lockedErr), "expected a lockout, got %v", err)
+	return lockedErr.RetryAfter
+}
+
+func testPolicy() LoginAttemptPolicy {
+	return LoginAttemptPolicy{
+		MaxFailures:   3,
+		IPMaxFailures: 5,
+		LockoutBase:   time.Minute,
+		LockoutMax:    10 * time.Minute,
+		FailureWindow: 15 * time.Minute,
+	}
+}
+
+func newTestLoginAttemptService(repo repository.LoginAttemptRepository, clock *testClock) LoginAttemptService {
+	s := NewLoginAttemptService(repo, testPolicy(), logger.New()).(*loginAttemptService)
+	s.now = clock.Now
+	return s
+}
+
+// newLoginTestClock starts at the next hour, the database expires counters on the real clock
+func newLoginTestClock() *testClock {
+	return &testClock{now: time.Now().UTC().Truncate(time.Hour).Add(time.Hour)}
+}
+
+// fail makes a login attempt with a wrong password
+func fail(t *testing.T, s LoginAttemptService, email, clientIP string) error {
+	ctx := context.Background()
+	attempt, err := s.Attempt(ctx, email, clientIP)
+	if err != nil {
+		return err
+	}
+	require.NoError(t, s.RecordFailure(ctx, attempt))
+	return nil
+}
+
+func TestLoginAttemptService(t *testing.T) {
+	repos := map[string]func(t *testing.T) repository.LoginAttemptRepository{
+		"Memory": func(t *testing.T) repository.LoginAttemptRepository {
+			return repository.NewInMemoryLoginAttemptRepository()
+		},
+		"Database": func(t *testing.T) repository.LoginAttemptRepository {
+			repo, err := repository.NewLoginAttemptRepository(setupTestDB(t))
+			require.NoError(t, err)
+			return repo
+		},
+	}
+
+	for name, newRepo := range repos {
+		t.Run(name, func(t *testing.T) {
+			t.Run("Exponential Lockout", func(t *testing.T) {
+				clock := newLoginTestClock()
+				s := newTestLoginAttemptService(newRepo(t), clock)
+
+				for i := 0; i < 3; i++ {
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+				}
+				assert.Equal(t, time.Minute, retryAfter(t, fail(t, s, "USER@example.com", "")))
+
+				// Every further failure doubles the lockout up to the maximum
+				lockout := time.Minute
+				for _, expected := range []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+					clock.Advance(lockout)
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+					assert.Equal(t, expected, retryAfter(t, fail(t, s, "user@example.com", "")))
+					lockout = expected
+				}
+			})
+
+			t.Run("Concurrent Attempts", func(t *testing.T) {
+				clock := newLoginTestClock()
+				s := newTestLoginAttemptService(newRepo(t), clock)
+
+				// Every attempt is counted before any password is checked
+				var allowed atomic.Int32
+				var wg sync.WaitGroup
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						if _, err := s.Attempt(context.Background(), "user@example.com", ""); err == nil {
+							allowed.Add(1)
+						}
+					}()
+				}
+				wg.Wait()
+
+				assert.Equal(t, int32(3), allowed.Load())
+			})
+
+			t.Run("Old Failures Are Forgotten", func(t *testing.T) {
+				clock := newLoginTestClock()
+				s := newTestLoginAttemptService(newRepo(t), clock)
+
+				require.NoError(t, fail(t, s, "user@example.com", ""))
+				require.NoError(t, fail(t, s, "user@example.com", ""))
+				clock.Advance(time.Hour)
+				require.NoError(t, fail(t, s, "user@example.com", ""))
+
+				assert.NoError(t, fail(t, s, "user@example.com", ""))
+			})
+
+			t.Run("Client IP Lockout", func(t *testing.T) {
+				clock := newLoginTestClock()
+				s := newTestLoginAttemptService(newRepo(t), clock)
+
+				// Spraying one guess over many accounts still locks the client
+				for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+					require.NoError(t, fail(t, s, email, "10.0.0.1"))
+				}
+
+				assert.Error(t, fail(t, s, "f@example.com", "10.0.0.1"))
+				assert.NoError(t, fail(t, s, "f@example.com", "10.0.0.2"))
+
+				// The attempts turned away by the client IP don't count against the account
+				assert.NoError(t, fail(t, s, "f@example.com", "10.0.0.3"))
+				assert.NoError(t, fail(t, s, "f@example.com", "10.0.0.4"))
+				assert.Error(t, fail(t, s, "f@example.com", "10.0.0.5"))
+			})
+
+			t.Run("Success And Clear", func(t *testing.T) {
+				clock := newLoginTestClock()
+				s := newTestLoginAttemptService(newRepo(t), clock)
+				ctx := context.Background()
+
+				// A success forgets the failures before it
+				for i := 0; i < 2; i++ {
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+				}
+				attempt, err := s.Attempt(ctx, "user@example.com", "")
+				require.NoError(t, err)
+				require.NoError(t, s.RecordSuccess(ctx, attempt))
+
+				for i := 0; i < 3; i++ {
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+				}
+
+				locked, err := s.ListLocked(ctx)
+				require.NoError(t, err)
+				require.Len(t, locked, 1)
+				assert.Equal(t, domain.LoginAttemptKindAccount, locked[0].Kind)
+				assert.Equal(t, "user@example.com", locked[0].Subject)
+				assert.Equal(t, 3, locked[0].Failures)
+
+				require.NoError(t, s.Clear(ctx, locked[0].ID))
+				assert.ErrorIs(t, s.Clear(ctx, locked[0].ID), domain.ErrLoginAttemptNotFound)
+
+				locked, err = s.ListLocked(ctx)
+				require.NoError(t, err)
+				assert.Empty(t, locked)
+
+				// Cleared with a fresh round of attempts
+				for i := 0; i < 3; i++ {
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+				}
+				assert.Error(t, fail(t, s, "user@example.com", ""))
+			})
+
+			t.Run("Expired Attempts Are Deleted", func(t *testing.T) {
+				clock := newLoginTestClock()
+				repo := newRepo(t)
+				s := newTestLoginAttemptService(repo, clock)
+				ctx := context.Background()
+
+				for i := 0; i < 3; i++ {
+					require.NoError(t, fail(t, s, "user@example.com", ""))
+				}
+				id := domain.LoginAttemptID(domain.LoginAttemptKindAccount, "user@example.com")
+
+				// Kept while the lockout can still escalate
+				_, err := repo.DeleteExpired(ctx, clock.Now().Add(10*time.Minute))
+				require.NoError(t, err)
+				_, err = repo.GetById(ctx, id)
+				require.NoError(t, err)
+
+				_, err = repo.DeleteExpired(ctx, clock.Now().Add(time.Minute+15*time.Minute))
+				require.NoError(t, err)
+				_, err = repo.GetById(ctx, id)
+				assert.ErrorIs(t, err, db.ErrNotFound)
+			})
+		})
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	loginAttempts := NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), testPolicy(), logger.New())
+	authService := NewAuthService(userRepo, newTestUsernameService(t, userRepo), loginAttempts, domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), auth.NewAuthManager(auth.DefaultConfig()))
+	ctx := context.Background()
+
+	hashedPassword, err := utils.HashPassword("correct-password")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, &domain.User{
+		ID:       "user-1",
+		Email:    "user@example.com",
+		Username: "user",
+		Password: hashedPassword,
+		Role:     domain.UserRoleUser,
+	}))
+
+	// Unknown emails and wrong passwords fail the same way
+	_, _, err = authService.Login(ctx, "nobody@example.com", "password", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	_, _, err = authService.Login(ctx, "user@example.com", "wrong-password", "10.0.0.1")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	// A success resets the account failures
+	_, _, err = authService.Login(ctx, "user@example.com", "correct-password", "10.0.0.1")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, _, err = authService.Login(ctx, "user@example.com", "wrong-password", "10.0.0.2")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	}
+
+	// Locked out, even with the correct password
+	_, _, err = authService.Login(ctx, "user@example.com", "correct-password", "10.0.0.3")
+	assert.ErrorIs(t, err, domain.ErrTooManyLoginAttempts)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/mailer"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+const (
+	// MagicLinkTTL bounds how long a login link stays valid
+	MagicLinkTTL = 15 * time.Minute
+	// MagicLinkMaxRequests is how many links can be sent to an address within MagicLinkRequestWindow
+	MagicLinkMaxRequests = 3
+	// MagicLinkRequestWindow is the period MagicLinkMaxRequests applies to
+	MagicLinkRequestWindow = 15 * time.Minute
+)
+
+// magicLinkTier limits the links requested for an address
+var magicLinkTier = ratelimit.Tier{Name: "magic_link", Limit: MagicLinkMaxRequests, Period: MagicLinkRequestWindow}
+
+type MagicLinkService interface {
+	// Request emails a login link to the address if it belongs to a user. A non-empty device nonce
+	// binds the link to the device holding the nonce.
+	Request(ctx context.Context, email, deviceNonce string) error
+	// Consume exchanges a login link for a token pair
+	Consume(ctx context.Context, token, deviceNonce string) (accessToken, refreshToken string, err error)
+}
+
+type magicLinkService struct {
+	userRepo    repository.UserRepository
+	linkRepo    repository.MagicLinkRepository
+	limiter     *ratelimit.Limiter
+	mailer      mailer.Mailer
+	auditor     audit.Recorder
+	authManager *auth.AuthManager
+	appBaseURL  string
+	log         *logger.Logger
+	now         func() time.Time
+
+	// sending tracks the links being sent in the background
+	sending sync.WaitGroup
+}
+
+// NewMagicLinkService creates a new MagicLinkService instance. Requests per address are counted
+// in limits, a store shared between instances keeps the limit global.
+func NewMagicLinkService(userRepo repository.UserRepository, linkRepo repository.MagicLinkRepository, limits ratelimit.Store, mailer mailer.Mailer, auditor audit.Recorder, authManager *auth.AuthManager, appBaseURL string, log *logger.Logger) MagicLinkService {
+	s := &magicLinkService{
+		userRepo:    userRepo,
+		linkRepo:    linkRepo,
+		mailer:      mailer,
+		auditor:     auditor,
+		authManager: authManager,
+		appBaseURL:  appBaseURL,
+		log:         log,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+	s.limiter = ratelimit.NewLimiter(limits, []ratelimit.Tier{magicLinkTier}, ratelimit.WithClock(func() time.Time { return s.now() }))
+	return s
+}
+
+// Request sends a login link. The response must not reveal whether an account exists, so every
+// address is rate limited and the account is only looked up in the background, along with
+// creating and mailing the link: known and unknown addresses take the same time.
+func (s *magicLinkService) Request(ctx context.Context, email, deviceNonce string) error {
+	email = strings.TrimSpace(email)
+
+	// Taken atomically, concurrent requests can't get past the limit
+	result, err := s.limiter.Allow(ctx, magicLinkTier.Name, normalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return domain.ErrTooManyMagicLinks
+	}
+
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+
+		sendCtx := context.WithoutCancel(ctx)
+		if err := s.send(sendCtx, email, deviceNonce); err != nil {
+			s.log.WithContext(sendCtx).Errorf("Failed to send a login link, error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// send creates and mails a login link if the address belongs to an enabled user
+func (s *magicLinkService) send(ctx context.Context, email, deviceNonce string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	now := s.now()
+	s.deleteExpiredLinks(ctx, email, now)
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	link := &domain.MagicLink{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(MagicLinkTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if deviceNonce != "" {
+		link.DeviceHash = utils.HashToken(deviceNonce)
+	}
+	if err := s.linkRepo.Create(ctx, link); err != nil {
+		return err
+	}
+
+	loginURL := fmt.Sprintf("%s/magic-link?token=%s", s.appBaseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Log in to ReMinder by opening the link below. It expires in %s and can be used once.\n\n%s\n", MagicLinkTTL, loginURL)
+	if link.IsDeviceBound() {
+		body += "\nThe link only works in the browser it was requested from.\n"
+	}
+	if err := s.mailer.Send(ctx, mailer.Message{To: email, Subject: "Your ReMinder login link", Body: body}); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: user.ID, Action: audit.ActionMagicLinkRequest, TargetID: user.ID}, map[string]interface{}{
+		"deviceBound": link.IsDeviceBound(),
+	})
+	return nil
+}
+
+// deleteExpiredLinks removes the expired links sent to the address
+func (s *magicLinkService) deleteExpiredLinks(ctx context.Context, email string, now time.Time) {
+	links, err := s.linkRepo.GetAllByEmail(ctx, email)
+	if err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to list login links, error: %v", err)
+		return
+	}
+
+	for _, link := range links {
+		if link.IsExpired(now) {
+			if err := ignoreNotFound(s.linkRepo.Delete(ctx, link.ID)); err != nil {
+				s.log.WithContext(ctx).Warnf("Failed to delete expired login link: %s, error: %v", link.ID, err)
+			}
+		}
+	}
+}
+
+// Consume checks the link and its device binding, then deletes it before issuing tokens.
+// Deleting is the claim, of two concurrent uses only one finds the link to delete.
+func (s *magicLinkService) Consume(ctx context.Context, token, deviceNonce string) (string, string, error) {
+	link, err := s.linkRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return "", "", domain.ErrInvalidMagicLink
+		}
+		return "", "", err
+	}
+
+	// Checked before the link is used up, so opening it in another browser doesn't burn it
+	if link.IsDeviceBound() && (deviceNonce == "" || utils.HashToken(deviceNonce) != link.DeviceHash) {
+		recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: link.UserID, Action: audit.ActionLogin, TargetID: link.UserID, Outcome: audit.OutcomeFailure}, map[string]interface{}{
+			"method": "magic_link",
+			"reason": "device_mismatch",
+		})
+		return "", "", domain.ErrInvalidMagicLink
+	}
+
+	if err := s.linkRepo.Delete(ctx, link.ID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return "", "", domain.ErrInvalidMagicLink
+		}
+		return "", "", err
+	}
+
+	if link.IsExpired(s.now()) {
+		return "", "", domain.ErrInvalidMagicLink
+	}
+
+	user, err := s.userRepo.GetById(ctx, link.UserID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return "", "", domain.ErrInvalidMagicLink
+		}
+		return "", "", err
+	}
+	// The email may have changed since the link was sent
+	if user.Email != link.Email {
+		return "", "", domain.ErrInvalidMagicLink
+	}
+	if user.IsDisabled() {
+		return "", "", domain.ErrAccountDisabled
+	}
+
+	accessToken, refreshToken, err := s.authManager.GenerateTokenPair(user.ID, userClaims(user))
+	if err != nil {
+		return "", "", err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: user.ID, Action: audit.ActionLogin, TargetID: user.ID}, map[string]interface{}{
+		"method": "magic_link",
+	})
+	return accessToken, refreshToken, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/ratelimit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// magicLinkTestEnv holds a MagicLinkService sharing the user test database, with a manual clock
+type magicLinkTestEnv struct {
+	*userTestEnv
+	links       repository.MagicLinkRepository
+	authManager *auth.AuthManager
+	clock       *testClock
+	service     *magicLinkService
+}
+
+func setupMagicLinkService(t *testing.T) *magicLinkTestEnv {
+	env := &magicLinkTestEnv{
+		userTestEnv: setupUserService(t),
+		authManager: auth.NewAuthManager(auth.DefaultConfig()),
+		clock:       newTestClock(),
+	}
+	env.links = env.data.MagicLinks
+	limits := ratelimit.NewMemoryStore(time.Minute)
+	t.Cleanup(func() { limits.Close() })
+	env.service = NewMagicLinkService(env.userRepo, env.links, limits, env.mailer, env.auditor, env.authManager, "http://app.test", logger.New()).(*magicLinkService)
+	env.service.now = env.clock.Now
+
+	return env
+}
+
+// request asks for a login link and waits until it is sent in the background
+func (env *magicLinkTestEnv) request(t *testing.T, email, deviceNonce string) {
+	require.NoError(t, env.service.Request(context.Background(), email, deviceNonce))
+	env.service.sending.Wait()
+}
+
+func TestMagicLinkLogin(t *testing.T) {
+	env := setupMagicLinkService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+
+	env.request(t, "alice@example.com", "")
+	token := confirmationToken(t, env.mailer, "alice@example.com")
+
+	accessToken, refreshToken, err := env.service.Consume(ctx, token, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshToken)
+	claims, err := env.authManager.ParseToken(accessToken, auth.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Subject)
+
+	// Links are single use
+	_, _, err = env.service.Consume(ctx, token, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLink)
+
+	assert.Equal(t, []string{audit.ActionMagicLinkRequest, audit.ActionLogin}, env.auditor.actions())
+}
+
+func TestMagicLinkUnknownEmailIsIgnored(t *testing.T) {
+	env := setupMagicLinkService(t)
+
+	env.request(t, "nobody@example.com", "")
+
+	_, sent := env.mailer.Last("nobody@example.com")
+	assert.False(t, sent)
+}
+
+func TestMagicLinkExpires(t *testing.T) {
+	env := setupMagicLinkService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	env.request(t, "alice@example.com", "")
+	token := confirmationToken(t, env.mailer, "alice@example.com")
+
+	env.clock.Advance(MagicLinkTTL + time.Second)
+
+	_, _, err := env.service.Consume(ctx, token, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLink)
+}
+
+func TestMagicLinkRateLimit(t *testing.T) {
+	env := setupMagicLinkService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	for i := 0; i < MagicLinkMaxRequests; i++ {
+		env.request(t, "alice@example.com", "")
+	}
+	assert.ErrorIs(t, env.service.Request(ctx, "Alice@Example.com", ""), domain.ErrTooManyMagicLinks)
+
+	// Unknown addresses are limited alike, so the limit doesn't reveal which ones have an account
+	for i := 0; i < MagicLinkMaxRequests; i++ {
+		env.request(t, "nobody@example.com", "")
+	}
+	assert.ErrorIs(t, env.service.Request(ctx, "nobody@example.com", ""), domain.ErrTooManyMagicLinks)
+
+	// The limit is regained over the window, and expired links are cleaned up on the next request
+	env.clock.Advance(MagicLinkRequestWindow)
+	env.request(t, "alice@example.com", "")
+
+	links, err := env.links.GetAllByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+}
+
+func TestMagicLinkDeviceBinding(t *testing.T) {
+	env := setupMagicLinkService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	env.request(t, "alice@example.com", "device-nonce")
+	token := confirmationToken(t, env.mailer, "alice@example.com")
+
+	_, _, err := env.service.Consume(ctx, token, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLink)
+	_, _, err = env.service.Consume(ctx, token, "other-device")
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLink)
+	assert.Equal(t, audit.OutcomeFailure, env.auditor.last(t).Outcome)
+
+	// A mismatch doesn't use the link up
+	_, _, err = env.service.Consume(ctx, token, "device-nonce")
+	assert.NoError(t, err)
+}
+
+func TestMagicLinkRejectsChangedEmailAndDisabledUser(t *testing.T) {
+	env := setupMagicLinkService(t)
+	ctx := context.Background()
+
+	alice := createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	bob := createTestUser(t, env.userRepo, "bob", domain.UserRoleUser)
+
+	env.request(t, "alice@example.com", "")
+	aliceToken := confirmationToken(t, env.mailer, "alice@example.com")
+	env.request(t, "bob@example.com", "")
+	bobToken := confirmationToken(t, env.mailer, "bob@example.com")
+
+	alice.Email = "alice@new.example.com"
+	require.NoError(t, env.userRepo.Update(ctx, alice))
+	_, _, err := env.service.Consume(ctx, aliceToken, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidMagicLink)
+
+	bob.Status = domain.UserStatusDisabled
+	require.NoError(t, env.userRepo.Update(ctx, bob))
+	_, _, err = env.service.Consume(ctx, bobToken, "")
+	assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+}
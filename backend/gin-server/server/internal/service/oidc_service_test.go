@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth/oidc"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth/oidc/oidctest"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/memcache/redistest"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// oidcTestEnv bundles the service under test with a fake provider and a migrated SQLite database
+type oidcTestEnv struct {
+	fake         *oidctest.Provider
+	service      OIDCService
+	userRepo     repository.UserRepository
+	identityRepo repository.UserIdentityRepository
+	authManager  *auth.AuthManager
+}
+
+func setupOIDC(t *testing.T) *oidcTestEnv {
+	return setupOIDCWithFlows(t, memcache.NewInMemoryCache(time.Minute))
+}
+
+// setupOIDCWithFlows keeps pending logins in the given cache
+func setupOIDCWithFlows(t *testing.T, flows memcache.Cache) *oidcTestEnv {
+	log := logger.New()
+	dbManager := setupTestDB(t)
+
+	fake := oidctest.NewProvider("reminder", "secret")
+	t.Cleanup(fake.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "fake",
+		IssuerURL:    fake.Issuer(),
+		ClientID:     fake.ClientID,
+		ClientSecret: fake.ClientSecret,
+		RedirectURL:  "http://localhost/api/auth/oidc/fake/callback",
+	})
+
+	env := &oidcTestEnv{
+		fake:         fake,
+		userRepo:     repository.NewUserRepository(dbManager),
+		identityRepo: repository.NewUserIdentityRepository(dbManager),
+		authManager:  auth.NewAuthManager(auth.DefaultConfig()),
+	}
+	env.service = NewOIDCService([]*oidc.Provider{provider}, env.userRepo, env.identityRepo, newTestUsernameService(t, env.userRepo), flows, env.authManager, log)
+
+	return env
+}
+
+// login runs the whole flow and returns the claims of the issued access token
+func (env *oidcTestEnv) login(t *testing.T) (*auth.CustomClaims, error) {
+	ctx := context.Background()
+
+	authURL, state, err := env.service.StartLogin(ctx, "fake")
+	require.NoError(t, err)
+
+	redirect, err := env.fake.Authorize(authURL)
+	require.NoError(t, err)
+	require.Equal(t, state, redirect.Query().Get("state"))
+
+	accessToken, _, err := env.service.CompleteLogin(ctx, "fake", state, redirect.Query().Get("code"))
+	if err != nil {
+		return nil, err
+	}
+
+	return env.authManager.ParseToken(accessToken, auth.AccessToken)
+}
+
+func TestOIDCLoginCreatesUserOnFirstLogin(t *testing.T) {
+	env := setupOIDC(t)
+	ctx := context.Background()
+
+	claims, err := env.login(t)
+	require.NoError(t, err)
+	assert.Equal(t, "fake.user@example.com", claims.Custom["email"])
+
+	user, err := env.userRepo.GetByEmail(ctx, "fake.user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.EntityID)
+	assert.Equal(t, domain.UserRoleUser, user.Role)
+
+	identities, err := env.identityRepo.GetAllByUserId(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "fake-subject", identities[0].Subject)
+
+	// The second login reuses the linked identity
+	claims, err = env.login(t)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.EntityID)
+
+	identities, err = env.identityRepo.GetAllByUserId(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, identities, 1)
+}
+
+func TestOIDCLoginWithSharedFlowCache(t *testing.T) {
+	server, err := redistest.NewServer("")
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	flows := memcache.NewRedisCache(memcache.RedisConfig{Addr: server.Addr()})
+	t.Cleanup(func() { flows.Close() })
+
+	// The flow survives encoding, as it does when another instance completes the login
+	claims, err := setupOIDCWithFlows(t, flows).login(t)
+	require.NoError(t, err)
+	assert.Equal(t, "fake.user@example.com", claims.Custom["email"])
+}
+
+func TestOIDCLoginLinksExistingUserByVerifiedEmail(t *testing.T) {
+	env := setupOIDC(t)
+	ctx := context.Background()
+
+	existing := &domain.User{
+		ID:       "existing-user",
+		Email:    "fake.user@example.com",
+		Username: "existing",
+		Password: "hash",
+		Role:     domain.UserRoleUser,
+	}
+	require.NoError(t, env.userRepo.Create(ctx, existing))
+
+	claims, err := env.login(t)
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, claims.EntityID)
+
+	identity, err := env.identityRepo.GetByProviderSubject(ctx, "fake", "fake-subject")
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, identity.UserID)
+}
+
+func TestOIDCLoginRejectsUnverifiedEmail(t *testing.T) {
+	env := setupOIDC(t)
+
+	env.fake.SetUser(oidctest.User{Subject: "unverified", Email: "fake.user@example.com", EmailVerified: false})
+
+	_, err := env.login(t)
+	assert.ErrorIs(t, err, domain.ErrUnverifiedEmail)
+}
+
+func TestOIDCLoginRejectsReusedState(t *testing.T) {
+	env := setupOIDC(t)
+	ctx := context.Background()
+
+	authURL, state, err := env.service.StartLogin(ctx, "fake")
+	require.NoError(t, err)
+
+	redirect, err := env.fake.Authorize(authURL)
+	require.NoError(t, err)
+
+	_, _, err = env.service.CompleteLogin(ctx, "fake", state, redirect.Query().Get("code"))
+	require.NoError(t, err)
+
+	_, _, err = env.service.CompleteLogin(ctx, "fake", state, redirect.Query().Get("code"))
+	assert.ErrorIs(t, err, domain.ErrInvalidOIDCState)
+}
+
+func TestOIDCLoginRejectsUnknownProvider(t *testing.T) {
+	env := setupOIDC(t)
+
+	_, _, err := env.service.StartLogin(context.Background(), "unknown")
+	assert.ErrorIs(t, err, domain.ErrUnknownProvider)
+}
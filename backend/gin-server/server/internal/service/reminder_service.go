@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
 	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
 )
@@ -11,25 +16,36 @@ import (
 type ReminderService interface {
 	CreateReminder(ctx context.Context, userID string, data domain.Reminder) (*domain.Reminder, error)
 	ListRemindersByUserID(ctx context.Context, userID string) ([]domain.Reminder, error)
+	DeleteReminder(ctx context.Context, userID, reminderID string) error
 }
 
 type reminderService struct {
 	reminderRepo repository.ReminderRepository
+	auditor      audit.Recorder
 	log          *logger.Logger
 }
 
-func NewReminderService(reminderRepo repository.ReminderRepository, log *logger.Logger) ReminderService {
-	return &reminderService{reminderRepo: reminderRepo, log: log}
+func NewReminderService(reminderRepo repository.ReminderRepository, auditor audit.Recorder, log *logger.Logger) ReminderService {
+	return &reminderService{reminderRepo: reminderRepo, auditor: auditor, log: log}
 }
 
 func (s *reminderService) CreateReminder(ctx context.Context, userID string, data domain.Reminder) (*domain.Reminder, error) {
+	now := time.Now().UTC()
 	reminder := &domain.Reminder{
-		UserID: userID,
-		// data
+		ID:              uuid.New().String(),
+		Title:           data.Title,
+		Description:     data.Description,
+		IsPinned:        data.IsPinned,
+		UserID:          userID,
+		ReminderGroupID: data.ReminderGroupID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 	if err := s.reminderRepo.Create(ctx, reminder); err != nil {
 		return nil, err
 	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionReminderCreate, TargetID: reminder.ID}, nil)
 	return reminder, nil
 }
 
@@ -40,3 +56,23 @@ func (s *reminderService) ListRemindersByUserID(ctx context.Context, userID stri
 	}
 	return reminders, nil
 }
+
+// DeleteReminder deletes a reminder of the user, other users' reminders are reported as not found
+func (s *reminderService) DeleteReminder(ctx context.Context, userID, reminderID string) error {
+	reminder, err := s.reminderRepo.GetById(ctx, reminderID)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	if reminder == nil || reminder.UserID != userID {
+		return domain.ErrReminderNotFound
+	}
+
+	if err := s.reminderRepo.Delete(ctx, reminderID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionReminderDelete, TargetID: reminderID}, map[string]interface{}{
+		"title": reminder.Title,
+	})
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+func TestReminderCreateAndDeleteAreAudited(t *testing.T) {
+	dbManager := setupTestDB(t)
+	userRepo := repository.NewUserRepository(dbManager)
+	auditor := &memoryRecorder{}
+	reminderService := NewReminderService(repository.NewReminderRepository(dbManager), auditor, logger.New())
+	ctx := context.Background()
+
+	createTestUser(t, userRepo, "alice", domain.UserRoleUser)
+	createTestUser(t, userRepo, "bob", domain.UserRoleUser)
+
+	reminder, err := reminderService.CreateReminder(ctx, "alice", domain.Reminder{Title: "Buy milk", Description: "Two litres"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, reminder.ID)
+	assert.Equal(t, "Two litres", reminder.Description)
+	assert.False(t, reminder.CreatedAt.IsZero())
+
+	reminders, err := reminderService.ListRemindersByUserID(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+
+	// Other users' reminders can't be deleted
+	assert.ErrorIs(t, reminderService.DeleteReminder(ctx, "bob", reminder.ID), domain.ErrReminderNotFound)
+	require.NoError(t, reminderService.DeleteReminder(ctx, "alice", reminder.ID))
+	assert.ErrorIs(t, reminderService.DeleteReminder(ctx, "alice", reminder.ID), domain.ErrReminderNotFound)
+
+	assert.Equal(t, []string{audit.ActionReminderCreate, audit.ActionReminderDelete}, auditor.actions())
+	deleted := auditor.last(t)
+	assert.Equal(t, "alice", deleted.ActorID)
+	assert.Equal(t, reminder.ID, deleted.TargetID)
+	assert.JSONEq(t, `{"title":"Buy milk"}`, deleted.Metadata)
+}
@@ -0,0 +1,731 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/mailer"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+// EmailChangeTTL bounds how long an email change confirmation link stays valid
+const EmailChangeTTL = 24 * time.Hour
+
+const (
+	// DefaultUserPageSize is used when a user list query has no page size
+	DefaultUserPageSize = 20
+	// MaxUserPageSize caps the page size of a user list query
+	MaxUserPageSize = 100
+)
+
+// UserListQuery filters and paginates the admin user list
+type UserListQuery struct {
+	Search   string // Case insensitive match on the email or username
+	Page     int    // Starts at 1
+	PageSize int
+}
+
+// UserList is a page of users, newest first
+type UserList struct {
+	Users    []domain.User
+	Total    int
+	Page     int
+	PageSize int
+}
+
+// UserDetails is the admin view of a single user
+type UserDetails struct {
+	User           *domain.User
+	Reminders      int
+	ReminderGroups int
+	APITokens      int
+	Identities     []domain.UserIdentity
+}
+
+type UserService interface {
+	ChangeRole(ctx context.Context, actorID, userID, role string) (*domain.User, error)
+	UpdateProfile(ctx context.Context, userID, username string) (*domain.User, error)
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword, clientIP string) (accessToken, refreshToken string, err error)
+	RequestEmailChange(ctx context.Context, userID, newEmail, password, clientIP string) error
+	ConfirmEmailChange(ctx context.Context, token string) (user *domain.User, accessToken, refreshToken string, err error)
+	DeleteAccount(ctx context.Context, caller *auth.CustomClaims, password, clientIP string) error
+
+	// Admin user management
+	ListUsers(ctx context.Context, query UserListQuery) (*UserList, error)
+	GetUserDetails(ctx context.Context, userID string) (*UserDetails, error)
+	SetDisabled(ctx context.Context, actorID, userID string, disabled bool) (*domain.User, error)
+	RevokeSessions(ctx context.Context, userID string) error
+	ResetPassword(ctx context.Context, userID, newPassword string) error
+	DeleteUser(ctx context.Context, actorID, userID string) error
+}
+
+// UserDataRepositories groups the repositories holding data owned by a user,
+// so that deleting an account removes it on every backend, not only where the database cascades
+type UserDataRepositories struct {
+	Reminders      repository.ReminderRepository
+	ReminderGroups repository.ReminderGroupRepository
+	APITokens      repository.APITokenRepository
+	Identities     repository.UserIdentityRepository
+	EmailChanges   repository.EmailChangeRepository
+	MagicLinks     repository.MagicLinkRepository
+	LoginAttempts  repository.LoginAttemptRepository
+	ExportJobs     repository.ExportJobRepository
+}
+
+type userService struct {
+	userRepo       repository.UserRepository
+	data           UserDataRepositories
+	loginAttempts  LoginAttemptService
+	roles          *auth.RoleRegistry
+	passwordPolicy domain.PasswordPolicy
+	mailer         mailer.Mailer
+	auditor        audit.Recorder
+	appBaseURL     string
+	log            *logger.Logger
+	authManager    *auth.AuthManager
+}
+
+// NewUserService creates a new UserService instance
+func NewUserService(userRepo repository.UserRepository, data UserDataRepositories, loginAttempts LoginAttemptService, roles *auth.RoleRegistry, passwordPolicy domain.PasswordPolicy, mailer mailer.Mailer, auditor audit.Recorder, appBaseURL string, log *logger.Logger, authManager *auth.AuthManager) UserService {
+	return &userService{
+		userRepo:       userRepo,
+		data:           data,
+		loginAttempts:  loginAttempts,
+		roles:          roles,
+		passwordPolicy: passwordPolicy,
+		mailer:         mailer,
+		auditor:        auditor,
+		appBaseURL:     appBaseURL,
+		log:            log,
+		authManager:    authManager,
+	}
+}
+
+// ChangeRole assigns a registered role to a user. Admins cannot change their own role,
+// which prevents the last admin from locking everyone out by accident.
+func (s *userService) ChangeRole(ctx context.Context, actorID, userID, role string) (*domain.User, error) {
+	if !s.roles.HasRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+
+	if actorID == userID {
+		return nil, domain.ErrCannotChangeOwnRole
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == role {
+		return user, nil
+	}
+
+	previousRole := user.Role
+	user.Role = role
+	user.UpdatedAt = time.Now().UTC()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.log.WithContext(ctx).Infof("Role of userID: %s changed from %s to %s by userID: %s", userID, previousRole, role, actorID)
+	return user, nil
+}
+
+// UpdateProfile changes the username, which must be unique
+func (s *userService) UpdateProfile(ctx context.Context, userID, username string) (*domain.User, error) {
+	if err := domain.ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Username == username {
+		return user, nil
+	}
+
+	existing, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrUsernameTaken
+	}
+
+	previousUsername := user.Username
+	user.Username = username
+	user.UpdatedAt = time.Now().UTC()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionProfileUpdate, TargetID: userID}, map[string]interface{}{
+		"previousUsername": previousUsername,
+		"username":         username,
+	})
+	return user, nil
+}
+
+// ChangePassword replaces the password after checking the current one. Every session is revoked,
+// in case the old password leaked, and a token pair for the caller's new session is returned.
+func (s *userService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword, clientIP string) (string, string, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.checkPassword(ctx, user, currentPassword, clientIP); err != nil {
+		if reason := passwordFailureReason(err); reason != "" {
+			recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionPasswordChange, TargetID: userID, Outcome: audit.OutcomeFailure}, map[string]interface{}{
+				"reason": reason,
+			})
+		}
+		return "", "", err
+	}
+
+	if err := s.passwordPolicy.Validate(newPassword, user.Email); err != nil {
+		return "", "", err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return "", "", err
+	}
+	user.Password = hashedPassword
+
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return "", "", err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionPasswordChange, TargetID: userID}, nil)
+	s.log.WithContext(ctx).Infof("Password changed for userID: %s", userID)
+	return s.authManager.GenerateTokenPair(user.ID, userClaims(user))
+}
+
+// RequestEmailChange sends a confirmation link to the new address. The email only changes
+// once the link is used, and the current address is told about the request.
+func (s *userService) RequestEmailChange(ctx context.Context, userID, newEmail, password, clientIP string) error {
+	newEmail = strings.TrimSpace(newEmail)
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPassword(ctx, user, password, clientIP); err != nil {
+		return err
+	}
+
+	if err := s.checkEmailAvailable(ctx, newEmail); err != nil {
+		return err
+	}
+
+	// Only the latest request can be confirmed
+	if err := s.deleteEmailChanges(ctx, userID); err != nil {
+		return err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	err = s.data.EmailChanges.Create(ctx, &domain.EmailChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		NewEmail:  newEmail,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(EmailChangeTTL),
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/confirm-email?token=%s", s.appBaseURL, url.QueryEscape(token))
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body:    fmt.Sprintf("Confirm your new ReMinder email address by opening the link below. It expires in %s.\n\n%s\n", EmailChangeTTL, link),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body:    fmt.Sprintf("A change of your ReMinder email address to %s was requested. If this was not you, change your password.\n", newEmail),
+	}); err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to notify userID: %s about email change, error: %v", userID, err)
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionEmailChangeRequest, TargetID: userID}, map[string]interface{}{
+		"newEmail": newEmail,
+	})
+	return nil
+}
+
+// ConfirmEmailChange applies the pending email change of the token. Every session is revoked, as
+// with a password change, and a token pair for a new session is returned.
+func (s *userService) ConfirmEmailChange(ctx context.Context, token string) (*domain.User, string, string, error) {
+	change, err := s.data.EmailChanges.GetByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, "", "", domain.ErrInvalidEmailChangeToken
+		}
+		return nil, "", "", err
+	}
+
+	// Tokens are single use
+	if err := s.data.EmailChanges.Delete(ctx, change.ID); err != nil {
+		return nil, "", "", err
+	}
+
+	if change.IsExpired(time.Now().UTC()) {
+		return nil, "", "", domain.ErrInvalidEmailChangeToken
+	}
+
+	user, err := s.getUser(ctx, change.UserID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// The address may have been taken since the request
+	if err := s.checkEmailAvailable(ctx, change.NewEmail); err != nil {
+		return nil, "", "", err
+	}
+
+	previousEmail := user.Email
+	user.Email = change.NewEmail
+
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return nil, "", "", err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: user.ID, Action: audit.ActionEmailChange, TargetID: user.ID}, map[string]interface{}{
+		"previousEmail": previousEmail,
+		"email":         user.Email,
+	})
+	s.log.WithContext(ctx).Infof("Email changed for userID: %s from %s to %s", user.ID, previousEmail, user.Email)
+
+	accessToken, refreshToken, err := s.authManager.GenerateTokenPair(user.ID, userClaims(user))
+	if err != nil {
+		return nil, "", "", err
+	}
+	return user, accessToken, refreshToken, nil
+}
+
+// DeleteAccount deletes the caller's own account and data. It can't be undone, so it takes a
+// session, not a personal access token, and the current password.
+func (s *userService) DeleteAccount(ctx context.Context, caller *auth.CustomClaims, password, clientIP string) error {
+	if caller.TokenType == auth.PersonalAccessToken {
+		return domain.ErrSessionRequired
+	}
+
+	userID := caller.EntityID
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkPassword(ctx, user, password, clientIP); err != nil {
+		if reason := passwordFailureReason(err); reason != "" {
+			recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionAccountDelete, TargetID: userID, Outcome: audit.OutcomeFailure}, map[string]interface{}{
+				"reason": reason,
+			})
+		}
+		return err
+	}
+
+	if err := s.deleteAccount(ctx, userID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditor, s.log, &audit.Event{ActorID: userID, Action: audit.ActionAccountDelete, TargetID: userID}, nil)
+	return nil
+}
+
+// deleteAccount removes the user and every record owned by them. Owned records go first,
+// so a failure leaves the account in place and the deletion can simply be retried.
+func (s *userService) deleteAccount(ctx context.Context, userID string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	reminders, err := s.data.Reminders.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing reminders: %w", err)
+	}
+	for _, reminder := range reminders {
+		if err := ignoreNotFound(s.data.Reminders.Delete(ctx, reminder.ID)); err != nil {
+			return fmt.Errorf("deleting reminder %s: %w", reminder.ID, err)
+		}
+	}
+
+	groups, err := s.data.ReminderGroups.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing reminder groups: %w", err)
+	}
+	for _, group := range groups {
+		if err := ignoreNotFound(s.data.ReminderGroups.Delete(ctx, group.ID)); err != nil {
+			return fmt.Errorf("deleting reminder group %s: %w", group.ID, err)
+		}
+	}
+
+	tokens, err := s.data.APITokens.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing api tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := ignoreNotFound(s.data.APITokens.Delete(ctx, token.ID)); err != nil {
+			return fmt.Errorf("deleting api token %s: %w", token.ID, err)
+		}
+	}
+
+	identities, err := s.data.Identities.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing identities: %w", err)
+	}
+	for _, identity := range identities {
+		if err := ignoreNotFound(s.data.Identities.Delete(ctx, identity.ID)); err != nil {
+			return fmt.Errorf("deleting identity %s: %w", identity.ID, err)
+		}
+	}
+
+	if err := s.deleteEmailChanges(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.deleteExportJobs(ctx, userID); err != nil {
+		return err
+	}
+
+	links, err := s.data.MagicLinks.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing login links: %w", err)
+	}
+	for _, link := range links {
+		if err := ignoreNotFound(s.data.MagicLinks.Delete(ctx, link.ID)); err != nil {
+			return fmt.Errorf("deleting login link %s: %w", link.ID, err)
+		}
+	}
+
+	// Failed login tracking stores the email address
+	loginAttemptID := domain.LoginAttemptID(domain.LoginAttemptKindAccount, normalizeEmail(user.Email))
+	if err := ignoreNotFound(s.data.LoginAttempts.Delete(ctx, loginAttemptID)); err != nil {
+		return fmt.Errorf("deleting login attempts: %w", err)
+	}
+
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	s.log.WithContext(ctx).Infof("Account deleted userID: %s", userID)
+	return nil
+}
+
+// ListUsers returns a page of users matching the search. Collections only filter on equality,
+// so the search runs in memory over all users.
+func (s *userService) ListUsers(ctx context.Context, query UserListQuery) (*UserList, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = DefaultUserPageSize
+	}
+	if query.PageSize > MaxUserPageSize {
+		query.PageSize = MaxUserPageSize
+	}
+
+	users, err := s.userRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	search := strings.ToLower(strings.TrimSpace(query.Search))
+	matches := make([]domain.User, 0, len(users))
+	for _, user := range users {
+		if search == "" ||
+			strings.Contains(strings.ToLower(user.Email), search) ||
+			strings.Contains(strings.ToLower(user.Username), search) {
+			matches = append(matches, user)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	list := &UserList{
+		Users:    []domain.User{},
+		Total:    len(matches),
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+
+	start := (query.Page - 1) * query.PageSize
+	if start < len(matches) {
+		end := start + query.PageSize
+		if end > len(matches) {
+			end = len(matches)
+		}
+		list.Users = matches[start:end]
+	}
+
+	return list, nil
+}
+
+// GetUserDetails returns a user with a summary of the data they own
+func (s *userService) GetUserDetails(ctx context.Context, userID string) (*UserDetails, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reminders, err := s.data.Reminders.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing reminders: %w", err)
+	}
+
+	groups, err := s.data.ReminderGroups.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing reminder groups: %w", err)
+	}
+
+	tokens, err := s.data.APITokens.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing api tokens: %w", err)
+	}
+
+	identities, err := s.data.Identities.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing identities: %w", err)
+	}
+
+	return &UserDetails{
+		User:           user,
+		Reminders:      len(reminders),
+		ReminderGroups: len(groups),
+		APITokens:      len(tokens),
+		Identities:     identities,
+	}, nil
+}
+
+// SetDisabled disables or re-enables a user. Disabled users can't log in and their
+// existing tokens are rejected. Admins cannot disable themselves.
+func (s *userService) SetDisabled(ctx context.Context, actorID, userID string, disabled bool) (*domain.User, error) {
+	if actorID == userID {
+		return nil, domain.ErrCannotManageSelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := domain.UserStatusActive
+	if disabled {
+		status = domain.UserStatusDisabled
+	}
+	if user.Status == status {
+		return user, nil
+	}
+
+	user.Status = status
+	user.UpdatedAt = time.Now().UTC()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.log.WithContext(ctx).Infof("Status of userID: %s changed to %s by userID: %s", userID, status, actorID)
+	return user, nil
+}
+
+// RevokeSessions logs the user out everywhere by invalidating every issued access and refresh token.
+// Personal access tokens are managed separately and keep working.
+func (s *userService) RevokeSessions(ctx context.Context, userID string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return err
+	}
+
+	s.log.WithContext(ctx).Infof("Sessions revoked for userID: %s", userID)
+	return nil
+}
+
+// ResetPassword sets a new password chosen by an admin and logs the user out everywhere
+func (s *userService) ResetPassword(ctx context.Context, userID, newPassword string) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordPolicy.Validate(newPassword, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+
+	if err := s.revokeSessions(ctx, user); err != nil {
+		return err
+	}
+
+	// A reset also lifts an account lockout
+	loginAttemptID := domain.LoginAttemptID(domain.LoginAttemptKindAccount, normalizeEmail(user.Email))
+	if err := ignoreNotFound(s.data.LoginAttempts.Delete(ctx, loginAttemptID)); err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to clear login lockout for userID: %s, error: %v", userID, err)
+	}
+
+	s.log.WithContext(ctx).Infof("Password reset for userID: %s", userID)
+	return nil
+}
+
+// DeleteUser deletes another user's account and data, admins cannot delete themselves
+func (s *userService) DeleteUser(ctx context.Context, actorID, userID string) error {
+	if actorID == userID {
+		return domain.ErrCannotManageSelf
+	}
+
+	// Audited as an admin action by the route
+	return s.deleteAccount(ctx, userID)
+}
+
+// revokeSessions bumps the token version and stores the user
+// checkPassword verifies the password of a signed in user. It is counted like a login, so a stolen
+// session can't be used to guess the password past the lockout.
+func (s *userService) checkPassword(ctx context.Context, user *domain.User, password, clientIP string) error {
+	attempt, err := s.loginAttempts.Attempt(ctx, user.Email, clientIP)
+	if err != nil {
+		return err
+	}
+
+	if !utils.VerifyPassword(user.Password, password) {
+		if err := s.loginAttempts.RecordFailure(ctx, attempt); err != nil {
+			s.log.WithContext(ctx).Warnf("Failed to record failed password check for userID: %s, error: %v", user.ID, err)
+		}
+		return domain.ErrInvalidCredentials
+	}
+
+	if err := s.loginAttempts.RecordSuccess(ctx, attempt); err != nil {
+		s.log.WithContext(ctx).Warnf("Failed to reset failed password checks for userID: %s, error: %v", user.ID, err)
+	}
+	return nil
+}
+
+// passwordFailureReason returns the audited reason of a failed checkPassword, empty for other errors
+func passwordFailureReason(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrTooManyLoginAttempts):
+		return "locked_out"
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		return "invalid_credentials"
+	}
+	return ""
+}
+
+func (s *userService) revokeSessions(ctx context.Context, user *domain.User) error {
+	user.TokenVersion++
+	user.UpdatedAt = time.Now().UTC()
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *userService) getUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := s.userRepo.GetById(ctx, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userService) checkEmailAvailable(ctx context.Context, email string) error {
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+	if existing != nil {
+		return domain.ErrEmailAlreadyInUse
+	}
+	return nil
+}
+
+func (s *userService) deleteEmailChanges(ctx context.Context, userID string) error {
+	changes, err := s.data.EmailChanges.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing email changes: %w", err)
+	}
+	for _, change := range changes {
+		if err := ignoreNotFound(s.data.EmailChanges.Delete(ctx, change.ID)); err != nil {
+			return fmt.Errorf("deleting email change %s: %w", change.ID, err)
+		}
+	}
+	return nil
+}
+
+// deleteExportJobs removes the user's export jobs along with their archives, which hold a copy of
+// all the personal data being deleted
+func (s *userService) deleteExportJobs(ctx context.Context, userID string) error {
+	jobs, err := s.data.ExportJobs.GetAllByUserId(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listing export jobs: %w", err)
+	}
+	for _, job := range jobs {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("removing export archive %s: %w", job.ID, err)
+			}
+		}
+		if err := ignoreNotFound(s.data.ExportJobs.Delete(ctx, job.ID)); err != nil {
+			return fmt.Errorf("deleting export job %s: %w", job.ID, err)
+		}
+	}
+	if err := ignoreNotFound(s.data.ExportJobs.DeleteClaim(ctx, userID)); err != nil {
+		return fmt.Errorf("deleting export claim: %w", err)
+	}
+	return nil
+}
+
+// ignoreNotFound treats deleting an already deleted record as success
+func ignoreNotFound(err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return nil
+	}
+	return err
+}
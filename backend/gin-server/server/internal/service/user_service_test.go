@@ -0,0 +1,503 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/audit"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/mailer"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/utils"
+)
+
+func createTestUser(t *testing.T, userRepo repository.UserRepository, id, role string) *domain.User {
+	user := &domain.User{
+		ID:       id,
+		Email:    id + "@example.com",
+		Username: id,
+		Password: "hash",
+		Role:     role,
+	}
+	require.NoError(t, userRepo.Create(context.Background(), user))
+	return user
+}
+
+// userTestEnv holds a UserService backed by a migrated SQLite database
+type userTestEnv struct {
+	service     UserService
+	userRepo    repository.UserRepository
+	authManager *auth.AuthManager
+	data        UserDataRepositories
+	mailer      *mailer.MemoryMailer
+	auditor     *memoryRecorder
+}
+
+func setupUserService(t *testing.T) *userTestEnv {
+	dbManager := setupTestDB(t)
+	loginAttempts, err := repository.NewLoginAttemptRepository(dbManager)
+	require.NoError(t, err)
+
+	env := &userTestEnv{
+		userRepo: repository.NewUserRepository(dbManager),
+		data: UserDataRepositories{
+			Reminders:      repository.NewReminderRepository(dbManager),
+			ReminderGroups: repository.NewReminderGroupRepository(dbManager),
+			APITokens:      repository.NewAPITokenRepository(dbManager),
+			Identities:     repository.NewUserIdentityRepository(dbManager),
+			EmailChanges:   repository.NewEmailChangeRepository(dbManager),
+			MagicLinks:     repository.NewMagicLinkRepository(dbManager),
+			LoginAttempts:  loginAttempts,
+			ExportJobs:     repository.NewExportJobRepository(dbManager),
+		},
+		mailer:      mailer.NewMemoryMailer(),
+		auditor:     &memoryRecorder{},
+		authManager: auth.NewAuthManager(auth.DefaultConfig()),
+	}
+	loginAttemptService := NewLoginAttemptService(loginAttempts, testPolicy(), logger.New())
+	env.service = NewUserService(env.userRepo, env.data, loginAttemptService, domain.NewRoleRegistry(), domain.DefaultPasswordPolicy(), env.mailer, env.auditor, "http://app.test", logger.New(), env.authManager)
+
+	return env
+}
+
+func TestChangeRole(t *testing.T) {
+	env := setupUserService(t)
+	userRepo, userService := env.userRepo, env.service
+	ctx := context.Background()
+
+	createTestUser(t, userRepo, "admin", domain.UserRoleAdmin)
+	createTestUser(t, userRepo, "member", domain.UserRoleUser)
+
+	user, err := userService.ChangeRole(ctx, "admin", "member", domain.UserRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserRoleAdmin, user.Role)
+
+	stored, err := userRepo.GetById(ctx, "member")
+	require.NoError(t, err)
+	assert.Equal(t, domain.UserRoleAdmin, stored.Role)
+
+	_, err = userService.ChangeRole(ctx, "admin", "member", "superuser")
+	assert.ErrorIs(t, err, domain.ErrInvalidRole)
+
+	_, err = userService.ChangeRole(ctx, "admin", "admin", domain.UserRoleUser)
+	assert.ErrorIs(t, err, domain.ErrCannotChangeOwnRole)
+
+	_, err = userService.ChangeRole(ctx, "admin", "missing", domain.UserRoleUser)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func TestRefreshPicksUpRoleChange(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	authManager := auth.NewAuthManager(auth.DefaultConfig())
+	authService := NewAuthService(userRepo, newTestUsernameService(t, userRepo), NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New()), domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), authManager)
+	userService := NewUserService(userRepo, UserDataRepositories{}, NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New()), domain.NewRoleRegistry(), domain.DefaultPasswordPolicy(), mailer.NewMemoryMailer(), &memoryRecorder{}, "", logger.New(), authManager)
+	ctx := context.Background()
+
+	createTestUser(t, userRepo, "admin", domain.UserRoleAdmin)
+	member := createTestUser(t, userRepo, "member", domain.UserRoleUser)
+
+	_, refreshToken, err := authManager.GenerateTokenPair(member.ID, userClaims(member))
+	require.NoError(t, err)
+
+	_, err = userService.ChangeRole(ctx, "admin", member.ID, domain.UserRoleAdmin)
+	require.NoError(t, err)
+
+	accessToken, _, err := authService.Refresh(ctx, refreshToken)
+	require.NoError(t, err)
+
+	claims, err := authManager.ParseToken(accessToken, auth.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.UserRoleAdmin}, claims.Roles("role"))
+}
+
+func TestUpdateProfile(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	createTestUser(t, env.userRepo, "bob", domain.UserRoleUser)
+
+	user, err := env.service.UpdateProfile(ctx, "alice", "alice.w")
+	require.NoError(t, err)
+	assert.Equal(t, "alice.w", user.Username)
+
+	_, err = env.service.UpdateProfile(ctx, "alice", "bob")
+	assert.ErrorIs(t, err, domain.ErrUsernameTaken)
+
+	_, err = env.service.UpdateProfile(ctx, "alice", "a")
+	assert.ErrorIs(t, err, domain.ErrInvalidUsername)
+}
+
+func TestChangePassword(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	user := createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	hashedPassword, err := utils.HashPassword("Current-Pass-42")
+	require.NoError(t, err)
+	user.Password = hashedPassword
+	require.NoError(t, env.userRepo.Update(ctx, user))
+
+	_, oldRefreshToken, err := env.authManager.GenerateTokenPair(user.ID, userClaims(user))
+	require.NoError(t, err)
+
+	_, _, err = env.service.ChangePassword(ctx, "alice", "wrong", "New-Password-42", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	var policyErr *domain.PasswordPolicyError
+	_, _, err = env.service.ChangePassword(ctx, "alice", "Current-Pass-42", "short", "")
+	assert.ErrorAs(t, err, &policyErr)
+
+	_, refreshToken, err := env.service.ChangePassword(ctx, "alice", "Current-Pass-42", "New-Password-42", "")
+	require.NoError(t, err)
+
+	stored, err := env.userRepo.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, utils.VerifyPassword(stored.Password, "New-Password-42"))
+
+	// Sessions from before the change are revoked, the returned one is current
+	assertSessionRevoked(t, env, stored, oldRefreshToken, true)
+	assertSessionRevoked(t, env, stored, refreshToken, false)
+
+	// The wrong current password and the change are audited, policy violations are not
+	assert.Equal(t, []string{audit.ActionPasswordChange, audit.ActionPasswordChange}, env.auditor.actions())
+	assert.Equal(t, audit.OutcomeFailure, env.auditor.events[0].Outcome)
+	assert.NotEqual(t, audit.OutcomeFailure, env.auditor.last(t).Outcome)
+}
+
+// assertSessionRevoked checks whether the session of a refresh token was revoked for the user
+func assertSessionRevoked(t *testing.T, env *userTestEnv, user *domain.User, refreshToken string, revoked bool) {
+	t.Helper()
+	claims, err := env.authManager.ParseToken(refreshToken, auth.RefreshToken)
+	require.NoError(t, err)
+
+	err = checkSession(user, claims)
+	if revoked {
+		assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+	} else {
+		assert.NoError(t, err)
+	}
+}
+
+// confirmationToken extracts the token from the last confirmation link sent to the address
+func confirmationToken(t *testing.T, m *mailer.MemoryMailer, to string) string {
+	msg, ok := m.Last(to)
+	require.True(t, ok, "no email sent to %s", to)
+
+	start := strings.Index(msg.Body, "http://app.test/")
+	require.NotEqual(t, -1, start)
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	require.NoError(t, err)
+
+	return link.Query().Get("token")
+}
+
+func TestEmailChangeFlow(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	user := createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	createTestUser(t, env.userRepo, "bob", domain.UserRoleUser)
+	hashedPassword, err := utils.HashPassword("Current-Pass-42")
+	require.NoError(t, err)
+	user.Password = hashedPassword
+	require.NoError(t, env.userRepo.Update(ctx, user))
+
+	err = env.service.RequestEmailChange(ctx, "alice", "new@example.com", "wrong", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	err = env.service.RequestEmailChange(ctx, "alice", "bob@example.com", "Current-Pass-42", "")
+	assert.ErrorIs(t, err, domain.ErrEmailAlreadyInUse)
+
+	require.NoError(t, env.service.RequestEmailChange(ctx, "alice", "new@example.com", "Current-Pass-42", ""))
+	token := confirmationToken(t, env.mailer, "new@example.com")
+
+	// The current address is notified, and nothing changes before the confirmation
+	_, notified := env.mailer.Last("alice@example.com")
+	assert.True(t, notified)
+	stored, err := env.userRepo.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", stored.Email)
+
+	_, oldRefreshToken, err := env.authManager.GenerateTokenPair(stored.ID, userClaims(stored))
+	require.NoError(t, err)
+
+	confirmed, _, refreshToken, err := env.service.ConfirmEmailChange(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", confirmed.Email)
+
+	stored, err = env.userRepo.GetById(ctx, "alice")
+	require.NoError(t, err)
+	assertSessionRevoked(t, env, stored, oldRefreshToken, true)
+	assertSessionRevoked(t, env, stored, refreshToken, false)
+
+	// Tokens are single use
+	_, _, _, err = env.service.ConfirmEmailChange(ctx, token)
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailChangeToken)
+}
+
+func TestEmailChangeRejectsExpiredToken(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "alice", domain.UserRoleUser)
+	now := time.Now().UTC()
+	require.NoError(t, env.data.EmailChanges.Create(ctx, &domain.EmailChange{
+		ID:        "change-1",
+		UserID:    "alice",
+		NewEmail:  "new@example.com",
+		TokenHash: utils.HashToken("expired-token"),
+		ExpiresAt: now.Add(-time.Minute),
+		CreatedAt: now.Add(-EmailChangeTTL),
+	}))
+
+	_, _, _, err := env.service.ConfirmEmailChange(ctx, "expired-token")
+	assert.ErrorIs(t, err, domain.ErrInvalidEmailChangeToken)
+}
+
+func TestDeleteAccountRemovesOwnedData(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	setPassword(t, env, createTestUser(t, env.userRepo, "alice", domain.UserRoleUser), "Current-Pass-42")
+	createTestUser(t, env.userRepo, "bob", domain.UserRoleUser)
+
+	for _, userID := range []string{"alice", "bob"} {
+		require.NoError(t, env.data.ReminderGroups.Create(ctx, &domain.ReminderGroup{ID: userID + "-group", Name: "Group", UserID: userID, CreatedAt: now}))
+		require.NoError(t, env.data.Reminders.Create(ctx, &domain.Reminder{ID: userID + "-reminder", Title: "Reminder", UserID: userID, ReminderGroupID: userID + "-group", CreatedAt: now}))
+		require.NoError(t, env.data.APITokens.Create(ctx, &domain.APIToken{ID: userID + "-token", UserID: userID, Name: "Token", TokenHash: userID, Prefix: "rmd_pat_", Scopes: "*", CreatedAt: now}))
+		require.NoError(t, env.data.Identities.Create(ctx, &domain.UserIdentity{ID: userID + "-identity", UserID: userID, Provider: "fake", Subject: userID, CreatedAt: now}))
+	}
+
+	archive := filepath.Join(t.TempDir(), "alice-export.zip")
+	require.NoError(t, os.WriteFile(archive, []byte("zip"), 0o600))
+	require.NoError(t, env.data.ExportJobs.Create(ctx, &domain.ExportJob{ID: "alice-export", UserID: "alice", Status: domain.ExportJobStatusDone, FilePath: archive, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, env.data.ExportJobs.CreateClaim(ctx, &domain.ExportClaim{ID: "alice", JobID: "alice-export", CreatedAt: now}))
+
+	require.NoError(t, env.service.DeleteAccount(ctx, sessionClaims("alice"), "Current-Pass-42", ""))
+
+	_, err := env.userRepo.GetById(ctx, "alice")
+	assert.Error(t, err)
+
+	reminders, err := env.data.Reminders.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+	groups, err := env.data.ReminderGroups.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+	tokens, err := env.data.APITokens.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+	identities, err := env.data.Identities.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, identities)
+	jobs, err := env.data.ExportJobs.GetAllByUserId(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+	_, err = env.data.ExportJobs.GetClaim(ctx, "alice")
+	assert.ErrorIs(t, err, db.ErrNotFound)
+	_, err = os.Stat(archive)
+	assert.ErrorIs(t, err, os.ErrNotExist, "export archive outlived the account")
+
+	// Other users keep their data
+	reminders, err = env.data.Reminders.GetAllByUserId(ctx, "bob")
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1)
+
+	assert.ErrorIs(t, env.service.DeleteAccount(ctx, sessionClaims("alice"), "Current-Pass-42", ""), domain.ErrUserNotFound)
+	assert.Equal(t, []string{audit.ActionAccountDelete}, env.auditor.actions())
+}
+
+func TestDeleteAccountRequiresPasswordAndSession(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	setPassword(t, env, createTestUser(t, env.userRepo, "alice", domain.UserRoleUser), "Current-Pass-42")
+
+	apiToken := &auth.CustomClaims{TokenType: auth.PersonalAccessToken, EntityID: "alice"}
+	assert.ErrorIs(t, env.service.DeleteAccount(ctx, apiToken, "Current-Pass-42", ""), domain.ErrSessionRequired)
+	assert.ErrorIs(t, env.service.DeleteAccount(ctx, sessionClaims("alice"), "wrong", ""), domain.ErrInvalidCredentials)
+
+	_, err := env.userRepo.GetById(ctx, "alice")
+	require.NoError(t, err, "the account was deleted")
+}
+
+func TestPasswordChecksAreLockedOut(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	setPassword(t, env, createTestUser(t, env.userRepo, "alice", domain.UserRoleUser), "Current-Pass-42")
+
+	// Every check of the current password counts towards the lockout of the account
+	_, _, err := env.service.ChangePassword(ctx, "alice", "wrong", "New-Password-42", "203.0.113.7")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	err = env.service.RequestEmailChange(ctx, "alice", "new@example.com", "wrong", "203.0.113.7")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	err = env.service.DeleteAccount(ctx, sessionClaims("alice"), "wrong", "203.0.113.7")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	_, _, err = env.service.ChangePassword(ctx, "alice", "Current-Pass-42", "New-Password-42", "203.0.113.7")
+	assert.ErrorIs(t, err, domain.ErrTooManyLoginAttempts, "the right password is rejected while locked out")
+	err = env.service.DeleteAccount(ctx, sessionClaims("alice"), "Current-Pass-42", "203.0.113.7")
+	assert.ErrorIs(t, err, domain.ErrTooManyLoginAttempts)
+}
+
+// setPassword stores the hash of the password as the user's password
+func setPassword(t *testing.T, env *userTestEnv, user *domain.User, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	require.NoError(t, err)
+	user.Password = hashedPassword
+	require.NoError(t, env.userRepo.Update(context.Background(), user))
+}
+
+// sessionClaims returns the claims of an access token of the user
+func sessionClaims(userID string) *auth.CustomClaims {
+	return &auth.CustomClaims{TokenType: auth.AccessToken, EntityID: userID}
+}
+
+func TestListUsers(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"alice", "bob", "carol", "alina"} {
+		require.NoError(t, env.userRepo.Create(ctx, &domain.User{
+			ID:        id,
+			Email:     id + "@example.com",
+			Username:  id,
+			Password:  "hash",
+			Role:      domain.UserRoleUser,
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	list, err := env.service.ListUsers(ctx, UserListQuery{Search: "ALI"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Total)
+	require.Len(t, list.Users, 2)
+	assert.Equal(t, "alina", list.Users[0].ID, "newest users come first")
+	assert.Equal(t, "alice", list.Users[1].ID)
+
+	list, err = env.service.ListUsers(ctx, UserListQuery{Page: 2, PageSize: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 4, list.Total)
+	require.Len(t, list.Users, 1)
+	assert.Equal(t, "alice", list.Users[0].ID)
+
+	list, err = env.service.ListUsers(ctx, UserListQuery{Page: 3, PageSize: 3})
+	require.NoError(t, err)
+	assert.Empty(t, list.Users)
+
+	list, err = env.service.ListUsers(ctx, UserListQuery{PageSize: MaxUserPageSize + 1})
+	require.NoError(t, err)
+	assert.Equal(t, MaxUserPageSize, list.PageSize)
+	assert.Equal(t, 1, list.Page)
+}
+
+func TestDisabledUserIsLockedOut(t *testing.T) {
+	env := setupUserService(t)
+	authManager := auth.NewAuthManager(auth.DefaultConfig())
+	authService := NewAuthService(env.userRepo, newTestUsernameService(t, env.userRepo), NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New()), domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), authManager)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "admin", domain.UserRoleAdmin)
+	member := createTestUser(t, env.userRepo, "member", domain.UserRoleUser)
+	member.Password, _ = utils.HashPassword("Member-Pass-42")
+	require.NoError(t, env.userRepo.Update(ctx, member))
+
+	accessToken, refreshToken, err := authService.Login(ctx, member.Email, "Member-Pass-42", "")
+	require.NoError(t, err)
+	claims, err := authManager.ParseToken(accessToken, auth.AccessToken)
+	require.NoError(t, err)
+	_, err = authService.ValidateSession(ctx, claims)
+	require.NoError(t, err)
+
+	_, err = env.service.SetDisabled(ctx, "admin", "admin", true)
+	assert.ErrorIs(t, err, domain.ErrCannotManageSelf)
+
+	user, err := env.service.SetDisabled(ctx, "admin", member.ID, true)
+	require.NoError(t, err)
+	assert.True(t, user.IsDisabled())
+
+	_, _, err = authService.Login(ctx, member.Email, "Member-Pass-42", "")
+	assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+	_, err = authService.ValidateSession(ctx, claims)
+	assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+	_, _, err = authService.Refresh(ctx, refreshToken)
+	assert.ErrorIs(t, err, domain.ErrAccountDisabled)
+
+	// Enabling restores access, including for the existing session
+	_, err = env.service.SetDisabled(ctx, "admin", member.ID, false)
+	require.NoError(t, err)
+	_, err = authService.ValidateSession(ctx, claims)
+	assert.NoError(t, err)
+	_, _, err = authService.Login(ctx, member.Email, "Member-Pass-42", "")
+	assert.NoError(t, err)
+}
+
+func TestRevokeSessionsAndResetPassword(t *testing.T) {
+	env := setupUserService(t)
+	authManager := auth.NewAuthManager(auth.DefaultConfig())
+	authService := NewAuthService(env.userRepo, newTestUsernameService(t, env.userRepo), NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New()), domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), authManager)
+	ctx := context.Background()
+
+	member := createTestUser(t, env.userRepo, "member", domain.UserRoleUser)
+	accessToken, refreshToken, err := authManager.GenerateTokenPair(member.ID, userClaims(member))
+	require.NoError(t, err)
+	claims, err := authManager.ParseToken(accessToken, auth.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, env.service.RevokeSessions(ctx, member.ID))
+
+	_, err = authService.ValidateSession(ctx, claims)
+	assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+	_, _, err = authService.Refresh(ctx, refreshToken)
+	assert.ErrorIs(t, err, domain.ErrSessionRevoked)
+
+	// Reset passwords must follow the policy, and log the user out again
+	var policyErr *domain.PasswordPolicyError
+	assert.ErrorAs(t, env.service.ResetPassword(ctx, member.ID, "short"), &policyErr)
+
+	_, _, err = authService.Login(ctx, member.Email, "Admin-Chosen-42", "")
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+	require.NoError(t, env.service.ResetPassword(ctx, member.ID, "Admin-Chosen-42"))
+	accessToken, _, err = authService.Login(ctx, member.Email, "Admin-Chosen-42", "")
+	require.NoError(t, err)
+
+	// Tokens issued after the revocation carry the new version
+	claims, err = authManager.ParseToken(accessToken, auth.AccessToken)
+	require.NoError(t, err)
+	_, err = authService.ValidateSession(ctx, claims)
+	assert.NoError(t, err)
+}
+
+func TestDeleteUser(t *testing.T) {
+	env := setupUserService(t)
+	ctx := context.Background()
+
+	createTestUser(t, env.userRepo, "admin", domain.UserRoleAdmin)
+	createTestUser(t, env.userRepo, "member", domain.UserRoleUser)
+
+	assert.ErrorIs(t, env.service.DeleteUser(ctx, "admin", "admin"), domain.ErrCannotManageSelf)
+	require.NoError(t, env.service.DeleteUser(ctx, "admin", "member"))
+
+	_, err := env.service.GetUserDetails(ctx, "member")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// Admin deletions are audited by the route, not as a self deletion
+	assert.Empty(t, env.auditor.actions())
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/usernamegen"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/db"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+const (
+	// DefaultUsernameSuggestions is the number of suggestions returned when none is asked for
+	DefaultUsernameSuggestions = 5
+	// MaxUsernameSuggestions caps the number of suggestions of a request
+	MaxUsernameSuggestions = 20
+	// createUserAttempts bounds the retries when a generated username is taken between the check and the insert
+	createUserAttempts = 3
+)
+
+type UsernameService interface {
+	// Generate returns a username that is not taken
+	Generate(ctx context.Context) (string, error)
+	// Suggest returns up to count distinct usernames that are not taken
+	Suggest(ctx context.Context, count int) ([]string, error)
+}
+
+type usernameService struct {
+	userRepo  repository.UserRepository
+	generator *usernamegen.Generator
+}
+
+// NewUsernameService creates a new UsernameService checking availability against the users
+func NewUsernameService(userRepo repository.UserRepository, generator *usernamegen.Generator) UsernameService {
+	return &usernameService{
+		userRepo:  userRepo,
+		generator: generator,
+	}
+}
+
+func (s *usernameService) Generate(ctx context.Context) (string, error) {
+	return s.generator.GenerateAvailable(ctx, s.isAvailable)
+}
+
+func (s *usernameService) Suggest(ctx context.Context, count int) ([]string, error) {
+	if count < 1 {
+		count = DefaultUsernameSuggestions
+	}
+	if count > MaxUsernameSuggestions {
+		count = MaxUsernameSuggestions
+	}
+
+	return s.generator.Suggest(ctx, count, s.isAvailable)
+}
+
+// isAvailable reports whether no user has the username
+func (s *usernameService) isAvailable(ctx context.Context, username string) (bool, error) {
+	_, err := s.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, db.ErrNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// createUserWithUsername creates the user under a generated username. A username can be taken
+// by a concurrent registration after it was checked, the unique constraint then rejects the
+// insert and another one is generated.
+func createUserWithUsername(ctx context.Context, userRepo repository.UserRepository, usernames UsernameService, user *domain.User) error {
+	var err error
+	for attempt := 0; attempt < createUserAttempts; attempt++ {
+		user.Username, err = usernames.Generate(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = userRepo.Create(ctx, user)
+		if !errors.Is(err, db.ErrDuplicate) {
+			return err
+		}
+
+		// The duplicate may be the email rather than the username
+		if _, lookupErr := userRepo.GetByUsername(ctx, user.Username); lookupErr != nil {
+			return err
+		}
+	}
+	return err
+}
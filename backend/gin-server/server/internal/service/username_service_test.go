@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/auth"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/logger"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/pkg/usernamegen"
+
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/domain"
+	"github.com/singhAmandeep007/ReMinder/backend/gin-server/server/internal/repository"
+)
+
+// seededUsernameService returns a UsernameService whose generator yields the same names for the same seed
+func seededUsernameService(t *testing.T, userRepo repository.UserRepository, seed int64) UsernameService {
+	generator, err := usernamegen.New(usernamegen.WithSeed(seed))
+	require.NoError(t, err)
+	return NewUsernameService(userRepo, generator)
+}
+
+func TestUsernameServiceSkipsTakenNames(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	// The first name of a seed is known, so it can be taken up front
+	taken, err := seededUsernameService(t, userRepo, 9).Generate(ctx)
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Create(ctx, &domain.User{ID: "alice", Email: "alice@example.com", Username: taken, Password: "hash", Role: domain.UserRoleUser}))
+
+	username, err := seededUsernameService(t, userRepo, 9).Generate(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, taken, username)
+	assert.Contains(t, username, taken+"-", "a taken name is retried with a suffix")
+	assert.NoError(t, domain.ValidateUsername(username))
+}
+
+func TestUsernameServiceSuggest(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	usernames := seededUsernameService(t, userRepo, 3)
+	ctx := context.Background()
+
+	suggestions, err := usernames.Suggest(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, suggestions, DefaultUsernameSuggestions)
+
+	suggestions, err = usernames.Suggest(ctx, 1000)
+	require.NoError(t, err)
+	assert.Len(t, suggestions, MaxUsernameSuggestions)
+}
+
+func TestRegisterWithCollidingGenerators(t *testing.T) {
+	userRepo := repository.NewUserRepository(setupTestDB(t))
+	ctx := context.Background()
+
+	// Two instances with the same seed propose the same names
+	register := func(email string) *domain.User {
+		authService := NewAuthService(userRepo, seededUsernameService(t, userRepo, 5), NewLoginAttemptService(repository.NewInMemoryLoginAttemptRepository(), DefaultLoginAttemptPolicy(), logger.New()), domain.DefaultPasswordPolicy(), &memoryRecorder{}, logger.New(), auth.NewAuthManager(auth.DefaultConfig()))
+		user, err := authService.Register(ctx, email, "Reminder-Test-2025")
+		require.NoError(t, err)
+		return user
+	}
+
+	first := register("first@example.com")
+	second := register("second@example.com")
+	assert.NotEqual(t, first.Username, second.Username)
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPassword(t *testing.T) {
+	hashed, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=19456,t=2,p=1$"))
+	assert.True(t, VerifyPassword(hashed, "correct horse battery staple"))
+	assert.False(t, VerifyPassword(hashed, "wrong password"))
+	assert.False(t, PasswordNeedsRehash(hashed))
+
+	// Salts are random
+	other, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, other)
+}
+
+func TestVerifyLegacyBcryptPassword(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	assert.True(t, VerifyPassword(string(legacy), "secret"))
+	assert.False(t, VerifyPassword(string(legacy), "other"))
+	assert.True(t, PasswordNeedsRehash(string(legacy)))
+}
+
+func TestPasswordNeedsRehashOnOutdatedParams(t *testing.T) {
+	params := DefaultArgon2Params
+	params.Iterations = 1
+
+	hashed, err := hashArgon2id("secret", params)
+	require.NoError(t, err)
+
+	assert.True(t, VerifyPassword(hashed, "secret"))
+	assert.True(t, PasswordNeedsRehash(hashed))
+}
+
+func TestVerifyPasswordRejectsMalformedHashes(t *testing.T) {
+	for _, hashed := range []string{
+		"",
+		"$argon2id$",
+		"$argon2id$v=18$m=19456,t=2,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=0,t=2,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=19456,t=2,p=1$!!!$a2V5",
+	} {
+		assert.False(t, VerifyPassword(hashed, "secret"), hashed)
+		assert.True(t, PasswordNeedsRehash(hashed), hashed)
+	}
+}
@@ -7,6 +7,7 @@ import (
 type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 	Message string      `json:"message,omitempty"`
+	Errors  interface{} `json:"errors,omitempty"`
 }
 
 func SuccessResponse(c *gin.Context, statusCode int, data interface{}) {
@@ -20,3 +21,8 @@ func ErrorResponse(c *gin.Context, statusCode int, message string) {
 func ErrorResponseWithAbort(c *gin.Context, statusCode int, message string) {
 	c.AbortWithStatusJSON(statusCode, Response{Message: message})
 }
+
+// ValidationErrorResponse responds with a message and the structured details of what failed validation
+func ValidationErrorResponse(c *gin.Context, statusCode int, message string, errors interface{}) {
+	c.JSON(statusCode, Response{Message: message, Errors: errors})
+}